@@ -2,24 +2,13 @@ package hitron
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"net/url"
 )
 
 func (c *CableModem) CMReboot(ctx context.Context) (*Error, error) {
-	csrf, err := c.UsersCSRF(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve CSRF token: %w", err)
-	}
-
 	o := Error{}
 
-	err = c.sendRequest(ctx, http.MethodPost, "/CM/Reboot",
-		url.Values{
-			"model": []string{`{"reboot":1}`},
-			"csrf":  []string{csrf.CSRF},
-		}, &o)
+	err := c.sendModelInto(ctx, http.MethodPost, "/CM/Reboot", map[string]int{"reboot": 1}, &o)
 	if err != nil {
 		return nil, err
 	}
@@ -28,19 +17,9 @@ func (c *CableModem) CMReboot(ctx context.Context) (*Error, error) {
 }
 
 func (c *CableModem) CMClearLog(ctx context.Context) (*Error, error) {
-	csrf, err := c.UsersCSRF(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve CSRF token: %w", err)
-	}
-
 	o := Error{}
 
-	err = c.sendRequest(ctx, http.MethodPost, "/CM/Log",
-		url.Values{
-			"model":   []string{"[]"},
-			"csrf":    []string{csrf.CSRF},
-			"_method": []string{"PUT"},
-		}, &o)
+	err := c.sendModelInto(ctx, http.MethodPut, "/CM/Log", []struct{}{}, &o)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,3 +34,70 @@ func staticResponseServer(t *testing.T, body string) *httptest.Server {
 
 	return srv
 }
+
+// recordedWrite - a write request as received by writeServer
+type recordedWrite struct {
+	Path   string
+	Method string // the method from the "_method" field, or POST
+	Model  string
+	CSRF   string
+}
+
+// writeServer - a server that responds to GETs with the given bodies (by path),
+// and records all POSTed writes. CSRF tokens are always "csrf-token".
+func writeServer(t *testing.T, responses map[string]string) (*httptest.Server, func() []recordedWrite) {
+	t.Helper()
+
+	mu := sync.Mutex{}
+	writes := []recordedWrite{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if strings.HasSuffix(r.URL.Path, "/Users/CSRF") {
+				_, _ = w.Write([]byte(`{"errCode":"000","errMsg":"","CSRF":"csrf-token"}`))
+
+				return
+			}
+
+			body, ok := responses[r.URL.Path]
+			if !ok {
+				t.Errorf("unexpected GET %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(body))
+
+			return
+		}
+
+		if err := r.ParseForm(); err != nil { //nolint:gosec
+			t.Errorf("failed to parse form: %v", err)
+		}
+
+		method := r.Form.Get("_method")
+		if method == "" {
+			method = r.Method
+		}
+
+		mu.Lock()
+		writes = append(writes, recordedWrite{
+			Path:   r.URL.Path,
+			Method: method,
+			Model:  r.Form.Get("model"),
+			CSRF:   r.Form.Get("csrf"),
+		})
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"errCode":"000","errMsg":""}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []recordedWrite {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]recordedWrite{}, writes...)
+	}
+}
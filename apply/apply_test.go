@@ -0,0 +1,681 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModem - an in-memory Modem, recording writes
+type fakeModem struct {
+	ssids    hitron.WiFiSSIDs
+	guest    hitron.WiFiGuestSSID
+	dns      hitron.DNS
+	dmz      hitron.RouterDMZ
+	forwards hitron.RouterPortForwardall
+	triggers hitron.RouterPortTriggerall
+	time     hitron.Time
+	hosts    hitron.Hosts
+	keywords hitron.FirewallKeywordFilterRules
+	services hitron.FirewallServiceFilterRules
+	trusted  []hitron.TrustRule
+	writes   []string
+}
+
+func (f *fakeModem) WiFiSSIDs(context.Context) (hitron.WiFiSSIDs, error) { return f.ssids, nil }
+func (f *fakeModem) WiFiGuestSSID(context.Context) (hitron.WiFiGuestSSID, error) {
+	return f.guest, nil
+}
+func (f *fakeModem) DNS(context.Context) (hitron.DNS, error)             { return f.dns, nil }
+func (f *fakeModem) RouterDMZ(context.Context) (hitron.RouterDMZ, error) { return f.dmz, nil }
+func (f *fakeModem) RouterPortForwardall(context.Context) (hitron.RouterPortForwardall, error) {
+	return f.forwards, nil
+}
+
+func (f *fakeModem) RouterPortTriggerall(context.Context) (hitron.RouterPortTriggerall, error) {
+	return f.triggers, nil
+}
+func (f *fakeModem) Time(context.Context) (hitron.Time, error) { return f.time, nil }
+
+func (f *fakeModem) Hosts(context.Context) (hitron.Hosts, error) { return f.hosts, nil }
+
+func (f *fakeModem) FirewallKeywordFilterRules(context.Context) (hitron.FirewallKeywordFilterRules, error) {
+	return f.keywords, nil
+}
+
+func (f *fakeModem) FirewallKeywordFilterStatus(context.Context) (hitron.FirewallKeywordFilterStatus, error) {
+	return hitron.FirewallKeywordFilterStatus{Enable: true}, nil
+}
+
+func (f *fakeModem) FirewallKeywordFilterTrustRules(context.Context) (hitron.FirewallKeywordFilterTrustRules, error) {
+	return hitron.FirewallKeywordFilterTrustRules{Rules: f.trusted}, nil
+}
+
+func (f *fakeModem) FirewallServiceFilterRules(context.Context) (hitron.FirewallServiceFilterRules, error) {
+	return f.services, nil
+}
+
+func (f *fakeModem) FirewallServiceFilterStatus(context.Context) (hitron.FirewallServiceFilterStatus, error) {
+	return hitron.FirewallServiceFilterStatus{}, nil
+}
+
+func (f *fakeModem) FirewallServiceFilterTrustRules(context.Context) (hitron.FirewallServiceFilterTrustRules, error) {
+	return hitron.FirewallServiceFilterTrustRules{Rules: f.trusted}, nil
+}
+
+func (f *fakeModem) UpdateTime(_ context.Context, c hitron.TimeChanges) (hitron.Time, error) {
+	f.writes = append(f.writes, "update time")
+
+	if c.TZ != nil {
+		f.time.TZ = c.TZ
+	}
+
+	if c.SNTPServer != nil {
+		f.time.SNTPServer = *c.SNTPServer
+	}
+
+	return f.time, nil
+}
+
+func (f *fakeModem) UpdateDNS(_ context.Context, c hitron.DNSChanges) (hitron.DNS, error) {
+	f.writes = append(f.writes, "update dns")
+
+	if c.LanDNS1 != nil {
+		f.dns.LanDNS1 = *c.LanDNS1
+	}
+
+	if c.AutoEnable != nil {
+		f.dns.AutoEnable = *c.AutoEnable
+	}
+
+	return f.dns, nil
+}
+
+func (f *fakeModem) SetDMZ(_ context.Context, host net.IP) (hitron.RouterDMZ, []string, error) {
+	f.writes = append(f.writes, "set dmz "+host.String())
+	f.dmz.Enable, f.dmz.Host = true, host
+
+	return f.dmz, []string{"DMZ host " + host.String() + " has no DHCP reservation, so its address may change"}, nil
+}
+
+func (f *fakeModem) DisableDMZ(context.Context) (hitron.RouterDMZ, error) {
+	f.writes = append(f.writes, "disable dmz")
+	f.dmz.Enable, f.dmz.Host = false, net.IPv4zero
+
+	return f.dmz, nil
+}
+
+func (f *fakeModem) AddPortForwardRule(_ context.Context, r hitron.PortForwardRule) (hitron.RouterPortForwardall, error) {
+	f.writes = append(f.writes, "add "+r.AppName)
+
+	return f.forwards, nil
+}
+
+func (f *fakeModem) UpdatePortForwardRule(_ context.Context, r hitron.PortForwardRule) (hitron.RouterPortForwardall, error) {
+	f.writes = append(f.writes, "update "+r.AppName)
+
+	return f.forwards, nil
+}
+
+func (f *fakeModem) DeletePortForwardRule(_ context.Context, id int) (hitron.RouterPortForwardall, error) {
+	for _, r := range f.forwards.Rules {
+		if r.ID == id {
+			f.writes = append(f.writes, "delete "+r.AppName)
+		}
+	}
+
+	return f.forwards, nil
+}
+
+func (f *fakeModem) AddPortTriggerRule(_ context.Context, r hitron.PortTriggerRule) (hitron.RouterPortTriggerall, error) {
+	f.writes = append(f.writes, "add trigger "+r.AppName)
+
+	return f.triggers, nil
+}
+
+func (f *fakeModem) UpdatePortTriggerRule(_ context.Context, r hitron.PortTriggerRule) (hitron.RouterPortTriggerall, error) {
+	f.writes = append(f.writes, "update trigger "+r.AppName)
+
+	return f.triggers, nil
+}
+
+func (f *fakeModem) DeletePortTriggerRule(_ context.Context, id int) (hitron.RouterPortTriggerall, error) {
+	for _, r := range f.triggers.Rules {
+		if r.ID == id {
+			f.writes = append(f.writes, "delete trigger "+r.AppName)
+		}
+	}
+
+	return f.triggers, nil
+}
+
+func (f *fakeModem) UpdateSSID(_ context.Context, id int, c hitron.SSIDChanges) (hitron.SSID, error) {
+	f.writes = append(f.writes, "update ssid "+strconv.Itoa(id))
+
+	for _, s := range f.ssids.SSIDs {
+		if s.ID == id {
+			if c.Passphrase != nil {
+				s.Passphrase = *c.Passphrase
+			}
+
+			return s, nil
+		}
+	}
+
+	return hitron.SSID{}, errors.New("no such SSID")
+}
+
+func (f *fakeModem) UpdateGuestSSID(_ context.Context, c hitron.GuestSSIDChanges) (hitron.WiFiGuestSSID, error) {
+	f.writes = append(f.writes, "update guest")
+
+	if c.Password != nil {
+		f.guest.Password = *c.Password
+	}
+
+	return f.guest, nil
+}
+
+func (f *fakeModem) AddKeywordFilterRule(_ context.Context, r hitron.KeywordFilterRule) (hitron.FirewallKeywordFilterRules, error) {
+	f.writes = append(f.writes, "add keyword "+r.Keyword)
+
+	return f.keywords, nil
+}
+
+func (f *fakeModem) UpdateKeywordFilterRule(_ context.Context, r hitron.KeywordFilterRule) (hitron.FirewallKeywordFilterRules, error) {
+	f.writes = append(f.writes, "update keyword "+r.Keyword)
+
+	return f.keywords, nil
+}
+
+func (f *fakeModem) DeleteKeywordFilterRule(_ context.Context, id int) (hitron.FirewallKeywordFilterRules, error) {
+	for _, r := range f.keywords.Rules {
+		if r.ID == id {
+			f.writes = append(f.writes, "delete keyword "+r.Keyword)
+		}
+	}
+
+	return f.keywords, nil
+}
+
+func (f *fakeModem) SetKeywordFilterEnabled(_ context.Context, enable bool) (hitron.FirewallKeywordFilterStatus, error) {
+	f.writes = append(f.writes, "set keyword filter "+strconv.FormatBool(enable))
+
+	return hitron.FirewallKeywordFilterStatus{Enable: enable}, nil
+}
+
+func (f *fakeModem) AddKeywordFilterTrust(_ context.Context, r hitron.TrustRule) (hitron.FirewallKeywordFilterTrustRules, error) {
+	f.writes = append(f.writes, "trust keyword "+r.MacAddr.String())
+
+	return hitron.FirewallKeywordFilterTrustRules{Rules: f.trusted}, nil
+}
+
+func (f *fakeModem) DeleteKeywordFilterTrust(_ context.Context, mac net.HardwareAddr) (hitron.FirewallKeywordFilterTrustRules, error) {
+	f.writes = append(f.writes, "untrust keyword "+mac.String())
+
+	return hitron.FirewallKeywordFilterTrustRules{Rules: f.trusted}, nil
+}
+
+func (f *fakeModem) AddServiceFilterRule(_ context.Context, r hitron.ServiceFilterRule) (hitron.FirewallServiceFilterRules, error) {
+	f.writes = append(f.writes, "add service "+r.Name)
+
+	return f.services, nil
+}
+
+func (f *fakeModem) UpdateServiceFilterRule(_ context.Context, r hitron.ServiceFilterRule) (hitron.FirewallServiceFilterRules, error) {
+	f.writes = append(f.writes, "update service "+r.Name)
+
+	return f.services, nil
+}
+
+func (f *fakeModem) DeleteServiceFilterRule(_ context.Context, id int) (hitron.FirewallServiceFilterRules, error) {
+	for _, r := range f.services.Rules {
+		if r.ID == id {
+			f.writes = append(f.writes, "delete service "+r.Name)
+		}
+	}
+
+	return f.services, nil
+}
+
+func (f *fakeModem) SetServiceFilterEnabled(_ context.Context, enable bool) (hitron.FirewallServiceFilterStatus, error) {
+	f.writes = append(f.writes, "set service filter "+strconv.FormatBool(enable))
+
+	return hitron.FirewallServiceFilterStatus{Enable: enable}, nil
+}
+
+func (f *fakeModem) AddServiceFilterTrust(_ context.Context, r hitron.TrustRule) (hitron.FirewallServiceFilterTrustRules, error) {
+	f.writes = append(f.writes, "trust service "+r.MacAddr.String())
+
+	return hitron.FirewallServiceFilterTrustRules{Rules: f.trusted}, nil
+}
+
+func (f *fakeModem) DeleteServiceFilterTrust(_ context.Context, mac net.HardwareAddr) (hitron.FirewallServiceFilterTrustRules, error) {
+	f.writes = append(f.writes, "untrust service "+mac.String())
+
+	return hitron.FirewallServiceFilterTrustRules{Rules: f.trusted}, nil
+}
+
+func newFakeModem() *fakeModem {
+	return &fakeModem{
+		ssids: hitron.WiFiSSIDs{SSIDs: []hitron.SSID{
+			{ID: 1, Name: "CODA", Passphrase: "supersecret", Enable: true, Visible: true},
+		}},
+		dns: hitron.DNS{AutoEnable: true, LanDNS1: net.ParseIP("192.168.0.1")},
+		dmz: hitron.RouterDMZ{Host: net.IPv4zero},
+		forwards: hitron.RouterPortForwardall{Rules: []hitron.PortForwardRule{
+			{
+				ID: 1, Enable: true, AppName: "ssh", Protocol: "TCP",
+				PublicPorts: hitron.PortRange{Start: 22, End: 22}, PrivatePorts: hitron.PortRange{Start: 22, End: 22},
+				LocalIP:   net.ParseIP("192.168.0.16"),
+				RemoteIPs: hitron.IPRange{Start: net.IPv4zero, End: net.IPv4bcast},
+			},
+			{
+				ID: 2, Enable: true, AppName: "old", Protocol: "UDP",
+				PublicPorts: hitron.PortRange{Start: 5000, End: 5000}, PrivatePorts: hitron.PortRange{Start: 5000, End: 5000},
+				LocalIP:   net.ParseIP("192.168.0.17"),
+				RemoteIPs: hitron.IPRange{Start: net.IPv4zero, End: net.IPv4bcast},
+			},
+		}},
+		triggers: hitron.RouterPortTriggerall{Rules: []hitron.PortTriggerRule{
+			{
+				ID: 1, Enable: true, AppName: "game", Protocol: "BOTH",
+				TriggerPorts: hitron.PortRange{Start: 6000, End: 6010}, TargetPorts: hitron.PortRange{Start: 7000, End: 7010},
+				Timeout: 5 * time.Minute,
+			},
+			{
+				ID: 2, Enable: true, AppName: "chat", Protocol: "TCP",
+				TriggerPorts: hitron.PortRange{Start: 6660, End: 6669}, TargetPorts: hitron.PortRange{Start: 113, End: 113},
+				Timeout: time.Minute,
+			},
+		}},
+		time: hitron.Time{TZ: time.UTC, SNTPServer: "pool.ntp.org", Enable: true},
+		hosts: hitron.Hosts{Hosts: []hitron.Host{
+			{Name: "laptop", MacAddr: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe}},
+			{Name: "tablet", MacAddr: net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01}},
+		}},
+		keywords: hitron.FirewallKeywordFilterRules{Rules: []hitron.KeywordFilterRule{
+			{ID: 1, Keyword: "casino", Enable: true},
+			{ID: 2, Keyword: "poker"},
+			{ID: 3, Keyword: "lottery", Enable: true},
+		}},
+		services: hitron.FirewallServiceFilterRules{Rules: []hitron.ServiceFilterRule{
+			{
+				ID: 1, Enable: true, Name: "IRC", Protocol: "TCP",
+				Ports: hitron.PortRange{Start: 6660, End: 6669}, Schedule: hitron.Always,
+			},
+			{
+				ID: 2, Enable: true, Name: "telnet", Protocol: "TCP",
+				Ports: hitron.PortRange{Start: 23, End: 23}, Schedule: hitron.Always,
+			},
+		}},
+		trusted: []hitron.TrustRule{
+			{ID: 1, Name: "old laptop", MacAddr: net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}},
+			{ID: 2, Name: "laptop", MacAddr: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe}},
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+dns:
+  lanDNS1: 192.168.0.53
+portForwards: []
+`))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.DNS)
+	assert.Equal(t, "192.168.0.53", *cfg.DNS.LanDNS1)
+	assert.NotNil(t, cfg.PortForwards)
+	assert.Empty(t, cfg.PortForwards)
+	assert.Nil(t, cfg.PortTriggers)
+
+	_, err = Load(strings.NewReader("dns:\n  landns: 1.1.1.1\n"))
+	assert.Error(t, err)
+
+	cfg, err = Load(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, cfg)
+}
+
+func TestNewPlan_NoChanges(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+ssids:
+  - id: 1
+    name: CODA
+dns:
+  auto: true
+  lanDNS1: 192.168.0.1
+time:
+  zone: UTC
+portForwards:
+  - name: ssh
+    protocol: TCP
+    localIP: 192.168.0.16
+    publicPorts: "22"
+  - name: old
+    protocol: UDP
+    localIP: 192.168.0.17
+    publicPorts: "5000"
+`))
+	require.NoError(t, err)
+
+	plan, err := NewPlan(context.Background(), newFakeModem(), cfg)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+	assert.Equal(t, "No changes.\n", plan.String())
+}
+
+func TestNewPlan_PortForwards(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+portForwards:
+  - name: ssh
+    protocol: TCP
+    localIP: 192.168.0.16
+    publicPorts: "22"
+    privatePorts: "2222"
+  - name: web
+    protocol: TCP
+    localIP: 192.168.0.10
+    publicPorts: "80"
+    enable: false
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, `portForwards:
+  ~ ssh: TCP 22 -> 192.168.0.16:22 from 0.0.0.0-255.255.255.255, enabled=true -> TCP 22 -> 192.168.0.16:2222 from 0.0.0.0-255.255.255.255, enabled=true
+  + web: TCP 80 -> 192.168.0.10:80 from 0.0.0.0-255.255.255.255, enabled=false
+  - old: UDP 5000 -> 192.168.0.17:5000 from 0.0.0.0-255.255.255.255, enabled=true
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"delete old", "update ssh", "add web"}, m.writes)
+}
+
+func TestNewPlan_PortTriggers(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+portTriggers:
+  - name: game
+    protocol: BOTH
+    triggerPorts: 6000-6010
+    targetPorts: 7000-7010
+    timeout: 10m
+  - name: voip
+    protocol: UDP
+    triggerPorts: "5060"
+    targetPorts: 10000-10010
+    timeout: 1m
+    twoWay: true
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, `portTriggers:
+  ~ game: BOTH 6000-6010 opens 7000-7010 for 5m0s, twoWay=false, enabled=true -> BOTH 6000-6010 opens 7000-7010 for 10m0s, twoWay=false, enabled=true
+  + voip: UDP 5060 opens 10000-10010 for 1m0s, twoWay=true, enabled=true
+  - chat: TCP 6660-6669 opens 113 for 1m0s, twoWay=false, enabled=true
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"delete trigger chat", "update trigger game", "add trigger voip"}, m.writes)
+}
+
+func TestNewPlan_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	for _, in := range []string{
+		"portForwards:\n  - name: a\n    protocol: TCP\n    localIP: 192.168.0.2\n    publicPorts: '0'\n",
+		"portForwards:\n  - {name: a, protocol: TCP, localIP: 192.168.0.2, publicPorts: '1'}\n" +
+			"  - {name: a, protocol: TCP, localIP: 192.168.0.2, publicPorts: '2'}\n",
+		"dns:\n  lanDNS1: pi.hole\n",
+		"time:\n  zone: Mars/Olympus_Mons\n",
+		"ssids:\n  - id: 9\n    name: foo\n",
+		"dmz:\n  enable: true\n",
+		"portTriggers:\n  - {name: a, protocol: TCP, triggerPorts: '1', targetPorts: '2', timeout: 0s}\n",
+		"keywordFilter:\n  keywords: [casino, CASINO]\n",
+		"keywordFilter:\n  keywords: ['online casino']\n",
+		"keywordFilter:\n  trusted: [phone]\n",
+		"serviceFilter:\n  trusted: [laptop, 'de:ad:be:ef:ca:fe']\n",
+		"serviceFilter:\n  services:\n  - {name: a, protocol: TCP, ports: '1', days: Someday}\n",
+	} {
+		cfg, err := Load(strings.NewReader(in))
+		require.NoError(t, err)
+
+		_, err = NewPlan(ctx, newFakeModem(), cfg)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestNewPlan_SSIDs(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+ssids:
+  - id: 1
+    name: CODA
+    passphrase: newpassword
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "ssids:\n  ~ 1/passphrase: (hidden) -> (hidden)\n", plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"update ssid 1"}, m.writes)
+}
+
+func TestNewPlan_Guest(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+guest:
+  password: newpassword
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "guest:\n  ~ password: (hidden) -> (hidden)\n", plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"update guest"}, m.writes)
+	assert.Equal(t, "newpassword", m.guest.Password)
+}
+
+func TestNewPlan_DMZ(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+dmz:
+  enable: true
+  host: 192.168.0.50
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "dmz:\n  ~ enable: false -> true\n  ~ host: \"\" -> \"192.168.0.50\"\n", plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"set dmz 192.168.0.50"}, m.writes)
+	assert.Equal(t, []string{"dmz: DMZ host 192.168.0.50 has no DHCP reservation, so its address may change"}, plan.Warnings())
+
+	cfg.DMZ = &DMZ{Enable: false}
+
+	plan, err = NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"set dmz 192.168.0.50", "disable dmz"}, m.writes)
+}
+
+func TestNewPlan_DNS(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+dns:
+  auto: false
+  lanDNS1: 192.168.0.53
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "dns:\n  ~ auto: true -> false\n  ~ lanDNS1: \"192.168.0.1\" -> \"192.168.0.53\"\n", plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"update dns"}, m.writes)
+	assert.Equal(t, "192.168.0.53", m.dns.LanDNS1.String())
+	assert.False(t, m.dns.AutoEnable)
+}
+
+func TestNewPlan_Time(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+time:
+  zone: America/Toronto
+  sntpServer: time.example.com
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+
+	// the modem doesn't list Toronto, so the equivalent zone is used
+	assert.Equal(t, `time:
+  ~ sntpServer: "pool.ntp.org" -> "time.example.com"
+  ~ zone: "Etc/UTC" -> "America/New_York"
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{"update time"}, m.writes)
+	assert.Equal(t, "America/New_York", m.time.TZ.String())
+
+	plan, err = NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+
+	kathmandu := "Asia/Kathmandu"
+	cfg.Time.Zone = &kathmandu
+
+	_, err = NewPlan(ctx, m, cfg)
+	assert.Error(t, err)
+}
+
+func TestNewPlan_KeywordFilter(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+keywordFilter:
+  enable: false
+  keywords: [Casino, poker, gambling]
+  trusted: [laptop, tablet, old laptop]
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, `keywordFilter:
+  ~ poker: enabled=false -> enabled=true
+  + gambling: enabled=true
+  - lottery: enabled=true
+  + trusted/ba:dd:ca:fe:00:01: tablet
+  ~ enable: true -> false
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{
+		"delete keyword lottery", "update keyword poker", "add keyword gambling",
+		"trust keyword ba:dd:ca:fe:00:01", "set keyword filter false",
+	}, m.writes)
+}
+
+func TestNewPlan_ServiceFilter(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+serviceFilter:
+  services:
+    - name: IRC
+      protocol: TCP
+      ports: 6660-6669
+      days: Sat,Sun
+    - name: games
+      protocol: UDP
+      ports: 27000-27050
+      days: Mon-Fri
+      time: 09:00-17:00
+  trusted: ["de:ad:be:ef:ca:fe"]
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, `serviceFilter:
+  ~ IRC: TCP 6660-6669, Sun,Mon,Tue,Wed,Thu,Fri,Sat all day, enabled=true -> TCP 6660-6669, Sun,Sat all day, enabled=true
+  + games: UDP 27000-27050, Mon,Tue,Wed,Thu,Fri 09:00-17:00, enabled=true
+  - telnet: TCP 23, Sun,Mon,Tue,Wed,Thu,Fri,Sat all day, enabled=true
+  - trusted/02:00:00:00:00:01: old laptop
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+	assert.Equal(t, []string{
+		"delete service telnet", "update service IRC", "add service games",
+		"untrust service 02:00:00:00:00:01",
+	}, m.writes)
+}
+
+func TestPlanApply_Unsupported(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`
+portForwards: []
+`))
+	require.NoError(t, err)
+
+	m := newFakeModem()
+	ctx := context.Background()
+
+	plan, err := NewPlan(ctx, m, cfg)
+	require.NoError(t, err)
+	assert.Len(t, plan.Changes(), 2)
+
+	// all sections can be written at the moment, so add one which can't
+	plan.sections = append(plan.sections, section{
+		name:    "example",
+		changes: []Change{{Section: "example", Name: "setting", Action: Update, Old: "a", New: "b"}},
+	})
+
+	err = plan.Apply(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errors.ErrUnsupported))
+
+	// nothing should be written if any section can't be applied
+	assert.Empty(t, m.writes)
+}
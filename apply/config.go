@@ -0,0 +1,151 @@
+// Package apply reconciles a declarative, desired-state configuration against
+// the live configuration of a Hitron CODA-4x8x modem.
+//
+// A Config is loaded from YAML, compared with the values read from the modem
+// to produce a Plan, and the Plan can then be printed and applied. Only the
+// changed settings are written to the modem.
+package apply
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the desired state of the modem. Sections and fields which are
+// omitted are left unmanaged, and are never changed. Rule lists (port
+// forwards, port triggers, and the filters' keywords, services, and trusted
+// devices) are managed as a whole when present: rules on the modem which
+// aren't listed are deleted.
+type Config struct {
+	Guest         *Guest         `yaml:"guest,omitempty"`
+	DNS           *DNS           `yaml:"dns,omitempty"`
+	DMZ           *DMZ           `yaml:"dmz,omitempty"`
+	Time          *Time          `yaml:"time,omitempty"`
+	KeywordFilter *KeywordFilter `yaml:"keywordFilter,omitempty"`
+	ServiceFilter *ServiceFilter `yaml:"serviceFilter,omitempty"`
+	SSIDs         []SSID         `yaml:"ssids,omitempty"`
+	PortForwards  []PortForward  `yaml:"portForwards,omitempty"`
+	PortTriggers  []PortTrigger  `yaml:"portTriggers,omitempty"`
+}
+
+// SSID - desired state of a WiFi network, identified by its ID (as listed by
+// `hitron wifi ssids`)
+type SSID struct {
+	Name       *string `yaml:"name,omitempty"`
+	Passphrase *string `yaml:"passphrase,omitempty"`
+	Enable     *bool   `yaml:"enable,omitempty"`
+	Visible    *bool   `yaml:"visible,omitempty"`
+	ID         int     `yaml:"id"`
+}
+
+// Guest - desired state of the guest WiFi network
+type Guest struct {
+	SSID     *string `yaml:"ssid,omitempty"`
+	SSID5G   *string `yaml:"ssid5G,omitempty"`
+	Password *string `yaml:"password,omitempty"`
+	MaxUsers *int    `yaml:"maxUsers,omitempty"`
+	Enable   *bool   `yaml:"enable,omitempty"`
+}
+
+// DNS - desired LAN DNS settings
+type DNS struct {
+	LanDNS1      *string `yaml:"lanDNS1,omitempty"`
+	LanDNS2      *string `yaml:"lanDNS2,omitempty"`
+	DomainSuffix *string `yaml:"domainSuffix,omitempty"`
+	Auto         *bool   `yaml:"auto,omitempty"`
+	Proxy        *bool   `yaml:"proxy,omitempty"`
+}
+
+// DMZ - desired DMZ host settings. Host is ignored when Enable is false.
+type DMZ struct {
+	Host   string `yaml:"host,omitempty"`
+	Enable bool   `yaml:"enable"`
+}
+
+// PortForward - a desired port forwarding rule, identified by its name
+type PortForward struct {
+	Enable       *bool  `yaml:"enable,omitempty"`       // default true
+	Name         string `yaml:"name"`                   //
+	Protocol     string `yaml:"protocol"`               // TCP, UDP, or BOTH
+	LocalIP      string `yaml:"localIP"`                //
+	PublicPorts  string `yaml:"publicPorts"`            // e.g. "22" or "8080-8088"
+	PrivatePorts string `yaml:"privatePorts,omitempty"` // default same as PublicPorts
+	RemoteIPs    string `yaml:"remoteIPs,omitempty"`    // e.g. "10.0.0.1-10.0.0.255", default any
+}
+
+// PortTrigger - a desired port triggering rule, identified by its name
+type PortTrigger struct {
+	Enable       *bool  `yaml:"enable,omitempty"` // default true
+	Name         string `yaml:"name"`             //
+	Protocol     string `yaml:"protocol"`         // TCP, UDP, or BOTH
+	TriggerPorts string `yaml:"triggerPorts"`     // outgoing ports which open the target ports
+	TargetPorts  string `yaml:"targetPorts"`      // incoming ports to open
+	Timeout      string `yaml:"timeout"`          // e.g. "5m"
+	TwoWay       bool   `yaml:"twoWay,omitempty"` //
+}
+
+// Time - desired time settings
+type Time struct {
+	Zone       *string `yaml:"zone,omitempty"` // IANA time zone name, e.g. "America/Toronto"
+	SNTPServer *string `yaml:"sntpServer,omitempty"`
+	Enable     *bool   `yaml:"enable,omitempty"` // enable SNTP
+	Daylight   *bool   `yaml:"daylight,omitempty"`
+}
+
+// KeywordFilter - desired state of the firewall's URL keyword filter.
+// Trusted devices are exempt from the filter, and are given by MAC address or
+// by name in the hosts list.
+type KeywordFilter struct {
+	Enable   *bool    `yaml:"enable,omitempty"`
+	Keywords []string `yaml:"keywords,omitempty"`
+	Trusted  []string `yaml:"trusted,omitempty"`
+}
+
+// ServiceFilter - desired state of the firewall's service filter. Trusted
+// devices are exempt from the filter, and are given by MAC address or by name
+// in the hosts list.
+type ServiceFilter struct {
+	Enable   *bool     `yaml:"enable,omitempty"`
+	Services []Service `yaml:"services,omitempty"`
+	Trusted  []string  `yaml:"trusted,omitempty"`
+}
+
+// Service - a desired service filter rule, identified by its name
+type Service struct {
+	Enable   *bool  `yaml:"enable,omitempty"` // default true
+	Name     string `yaml:"name"`             //
+	Protocol string `yaml:"protocol"`         // TCP, UDP, or BOTH
+	Ports    string `yaml:"ports"`            // e.g. "6667" or "6660-6669"
+	Days     string `yaml:"days,omitempty"`   // e.g. "Mon-Fri", default every day
+	Time     string `yaml:"time,omitempty"`   // e.g. "09:00-17:00", default all day
+}
+
+// Load reads a Config in YAML format. Unknown fields are rejected, to catch
+// typos which would otherwise silently leave settings unmanaged.
+func Load(r io.Reader) (*Config, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	cfg := &Config{}
+
+	err := dec.Decode(cfg)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadFile reads a Config from the named YAML file
+func LoadFile(name string) (*Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
@@ -0,0 +1,421 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func planKeywordFilter(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "keywordFilter"}
+
+	want := cfg.KeywordFilter
+	if want == nil {
+		return s, nil
+	}
+
+	var deletes []int
+
+	var updates, adds []hitron.KeywordFilterRule
+
+	if want.Keywords != nil {
+		live, err := m.FirewallKeywordFilterRules(ctx)
+		if err != nil {
+			return s, fmt.Errorf("failed to read keyword filter rules: %w", err)
+		}
+
+		// keywords are matched without regard to case
+		liveByKeyword := make(map[string]hitron.KeywordFilterRule, len(live.Rules))
+		for _, r := range live.Rules {
+			liveByKeyword[strings.ToLower(r.Keyword)] = r
+		}
+
+		keywords := map[string]bool{}
+
+		for _, kw := range want.Keywords {
+			r := hitron.KeywordFilterRule{Keyword: kw, Enable: true}
+			if err := r.Validate(); err != nil {
+				return s, err
+			}
+
+			key := strings.ToLower(kw)
+			if keywords[key] {
+				return s, fmt.Errorf("duplicate keyword %q", kw)
+			}
+
+			keywords[key] = true
+
+			have, ok := liveByKeyword[key]
+
+			switch {
+			case !ok:
+				adds = append(adds, r)
+				s.changes = append(s.changes, Change{
+					Section: s.name, Name: kw, Action: Create,
+					New: describeKeyword(r),
+				})
+			case !have.Enable:
+				r.ID = have.ID
+				r.Keyword = have.Keyword
+				updates = append(updates, r)
+				s.changes = append(s.changes, Change{
+					Section: s.name, Name: kw, Action: Update,
+					Old: describeKeyword(have), New: describeKeyword(r),
+				})
+			}
+		}
+
+		for _, have := range live.Rules {
+			if !keywords[strings.ToLower(have.Keyword)] {
+				deletes = append(deletes, have.ID)
+				s.changes = append(s.changes, Change{
+					Section: s.name, Name: have.Keyword, Action: Delete,
+					Old: describeKeyword(have),
+				})
+			}
+		}
+	}
+
+	var trustAdds []hitron.TrustRule
+
+	var trustDeletes []net.HardwareAddr
+
+	if want.Trusted != nil {
+		live, err := m.FirewallKeywordFilterTrustRules(ctx)
+		if err != nil {
+			return s, fmt.Errorf("failed to read keyword filter trusted devices: %w", err)
+		}
+
+		var changes []Change
+
+		changes, trustAdds, trustDeletes, err = planTrust(ctx, m, s.name, live.Rules, want.Trusted)
+		if err != nil {
+			return s, err
+		}
+
+		s.changes = append(s.changes, changes...)
+	}
+
+	var enable *bool
+
+	if want.Enable != nil {
+		status, err := m.FirewallKeywordFilterStatus(ctx)
+		if err != nil {
+			return s, fmt.Errorf("failed to read keyword filter status: %w", err)
+		}
+
+		d := differ{section: s.name}
+		d.boolean("enable", want.Enable, status.Enable)
+
+		if len(d.changes) > 0 {
+			enable = want.Enable
+			s.changes = append(s.changes, d.changes...)
+		}
+	}
+
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		for _, id := range deletes {
+			if _, err := m.DeleteKeywordFilterRule(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range updates {
+			if _, err := m.UpdateKeywordFilterRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range adds {
+			if _, err := m.AddKeywordFilterRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		for _, mac := range trustDeletes {
+			if _, err := m.DeleteKeywordFilterTrust(ctx, mac); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range trustAdds {
+			if _, err := m.AddKeywordFilterTrust(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		if enable != nil {
+			if _, err := m.SetKeywordFilterEnabled(ctx, *enable); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return s, nil
+}
+
+func describeKeyword(r hitron.KeywordFilterRule) string {
+	return fmt.Sprintf("enabled=%t", r.Enable)
+}
+
+func (s Service) rule() (hitron.ServiceFilterRule, error) {
+	r := hitron.ServiceFilterRule{
+		Name:     s.Name,
+		Protocol: s.Protocol,
+		Enable:   s.Enable == nil || *s.Enable,
+	}
+
+	if s.Name == "" {
+		return r, fmt.Errorf("service name must be set")
+	}
+
+	var err error
+
+	r.Ports, err = hitron.ParsePortRange(s.Ports)
+	if err != nil {
+		return r, fmt.Errorf("service %q: %w", s.Name, err)
+	}
+
+	r.Schedule, err = hitron.ParseSchedule(s.Days, s.Time)
+	if err != nil {
+		return r, fmt.Errorf("service %q: %w", s.Name, err)
+	}
+
+	return r, r.Validate()
+}
+
+func describeService(r hitron.ServiceFilterRule) string {
+	// the modem may list the days in a different order
+	sched := r.Schedule
+	sched.Days = slices.Sorted(slices.Values(sched.Days))
+
+	return fmt.Sprintf("%s %s, %s, enabled=%t", r.Protocol, r.Ports, sched, r.Enable)
+}
+
+func planServiceFilter(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "serviceFilter"}
+
+	want := cfg.ServiceFilter
+	if want == nil {
+		return s, nil
+	}
+
+	var deletes []int
+
+	var updates, adds []hitron.ServiceFilterRule
+
+	if want.Services != nil {
+		desired := make([]hitron.ServiceFilterRule, 0, len(want.Services))
+		for _, svc := range want.Services {
+			r, err := svc.rule()
+			if err != nil {
+				return s, err
+			}
+
+			desired = append(desired, r)
+		}
+
+		live, err := m.FirewallServiceFilterRules(ctx)
+		if err != nil {
+			return s, fmt.Errorf("failed to read service filter rules: %w", err)
+		}
+
+		liveByName := make(map[string]hitron.ServiceFilterRule, len(live.Rules))
+		for _, r := range live.Rules {
+			liveByName[r.Name] = r
+		}
+
+		names := map[string]bool{}
+
+		for _, want := range desired {
+			if names[want.Name] {
+				return s, fmt.Errorf("duplicate service name %q", want.Name)
+			}
+
+			names[want.Name] = true
+
+			have, ok := liveByName[want.Name]
+
+			switch {
+			case !ok:
+				adds = append(adds, want)
+				s.changes = append(s.changes, Change{
+					Section: s.name, Name: want.Name, Action: Create,
+					New: describeService(want),
+				})
+			case describeService(want) != describeService(have):
+				want.ID = have.ID
+				updates = append(updates, want)
+				s.changes = append(s.changes, Change{
+					Section: s.name, Name: want.Name, Action: Update,
+					Old: describeService(have), New: describeService(want),
+				})
+			}
+		}
+
+		for _, have := range live.Rules {
+			if !names[have.Name] {
+				deletes = append(deletes, have.ID)
+				s.changes = append(s.changes, Change{
+					Section: s.name, Name: have.Name, Action: Delete,
+					Old: describeService(have),
+				})
+			}
+		}
+	}
+
+	var trustAdds []hitron.TrustRule
+
+	var trustDeletes []net.HardwareAddr
+
+	if want.Trusted != nil {
+		live, err := m.FirewallServiceFilterTrustRules(ctx)
+		if err != nil {
+			return s, fmt.Errorf("failed to read service filter trusted devices: %w", err)
+		}
+
+		var changes []Change
+
+		changes, trustAdds, trustDeletes, err = planTrust(ctx, m, s.name, live.Rules, want.Trusted)
+		if err != nil {
+			return s, err
+		}
+
+		s.changes = append(s.changes, changes...)
+	}
+
+	var enable *bool
+
+	if want.Enable != nil {
+		status, err := m.FirewallServiceFilterStatus(ctx)
+		if err != nil {
+			return s, fmt.Errorf("failed to read service filter status: %w", err)
+		}
+
+		d := differ{section: s.name}
+		d.boolean("enable", want.Enable, status.Enable)
+
+		if len(d.changes) > 0 {
+			enable = want.Enable
+			s.changes = append(s.changes, d.changes...)
+		}
+	}
+
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		for _, id := range deletes {
+			if _, err := m.DeleteServiceFilterRule(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range updates {
+			if _, err := m.UpdateServiceFilterRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range adds {
+			if _, err := m.AddServiceFilterRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		for _, mac := range trustDeletes {
+			if _, err := m.DeleteServiceFilterTrust(ctx, mac); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range trustAdds {
+			if _, err := m.AddServiceFilterTrust(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		if enable != nil {
+			if _, err := m.SetServiceFilterEnabled(ctx, *enable); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return s, nil
+}
+
+// planTrust compares a filter's trusted devices with the desired list of MAC
+// addresses or host names. Devices are matched by MAC address, so names are
+// resolved with the hosts list - or with the names of already-trusted devices,
+// for devices which aren't connected.
+func planTrust(ctx context.Context, m Modem, name string, live []hitron.TrustRule, trusted []string) (
+	changes []Change, adds []hitron.TrustRule, deletes []net.HardwareAddr, err error,
+) {
+	hosts, err := m.Hosts(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read hosts: %w", err)
+	}
+
+	macs := map[string]bool{}
+
+	for _, t := range trusted {
+		want, terr := trustRule(hosts, live, t)
+		if terr != nil {
+			return nil, nil, nil, fmt.Errorf("%s trusted device %q: %w", name, t, terr)
+		}
+
+		mac := want.MacAddr.String()
+		if macs[mac] {
+			return nil, nil, nil, fmt.Errorf("duplicate %s trusted device %q (%s)", name, t, mac)
+		}
+
+		macs[mac] = true
+
+		if !slices.ContainsFunc(live, func(r hitron.TrustRule) bool { return r.MacAddr.String() == mac }) {
+			adds = append(adds, want)
+			changes = append(changes, Change{
+				Section: name, Name: "trusted/" + mac, Action: Create,
+				New: want.Name,
+			})
+		}
+	}
+
+	for _, have := range live {
+		if !macs[have.MacAddr.String()] {
+			deletes = append(deletes, have.MacAddr)
+			changes = append(changes, Change{
+				Section: name, Name: "trusted/" + have.MacAddr.String(), Action: Delete,
+				Old: have.Name,
+			})
+		}
+	}
+
+	return changes, adds, deletes, nil
+}
+
+// trustRule resolves a MAC address or host name to a trust rule
+func trustRule(hosts hitron.Hosts, live []hitron.TrustRule, nameOrMAC string) (hitron.TrustRule, error) {
+	host, err := hosts.Lookup(nameOrMAC)
+	if err != nil {
+		for _, r := range live {
+			if strings.EqualFold(r.Name, nameOrMAC) {
+				return r, nil
+			}
+		}
+
+		return hitron.TrustRule{}, err
+	}
+
+	r := hitron.TrustRule{Name: host.Name, MacAddr: host.MacAddr}
+	if r.Name == "" {
+		r.Name = nameOrMAC
+	}
+
+	return r, r.Validate()
+}
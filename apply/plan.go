@@ -0,0 +1,237 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+// Modem is the subset of *hitron.CableModem used to read and write the
+// configuration
+type Modem interface {
+	WiFiSSIDs(ctx context.Context) (hitron.WiFiSSIDs, error)
+	WiFiGuestSSID(ctx context.Context) (hitron.WiFiGuestSSID, error)
+	DNS(ctx context.Context) (hitron.DNS, error)
+	RouterDMZ(ctx context.Context) (hitron.RouterDMZ, error)
+	RouterPortForwardall(ctx context.Context) (hitron.RouterPortForwardall, error)
+	RouterPortTriggerall(ctx context.Context) (hitron.RouterPortTriggerall, error)
+	Time(ctx context.Context) (hitron.Time, error)
+	Hosts(ctx context.Context) (hitron.Hosts, error)
+	FirewallKeywordFilterRules(ctx context.Context) (hitron.FirewallKeywordFilterRules, error)
+	FirewallKeywordFilterStatus(ctx context.Context) (hitron.FirewallKeywordFilterStatus, error)
+	FirewallKeywordFilterTrustRules(ctx context.Context) (hitron.FirewallKeywordFilterTrustRules, error)
+	FirewallServiceFilterRules(ctx context.Context) (hitron.FirewallServiceFilterRules, error)
+	FirewallServiceFilterStatus(ctx context.Context) (hitron.FirewallServiceFilterStatus, error)
+	FirewallServiceFilterTrustRules(ctx context.Context) (hitron.FirewallServiceFilterTrustRules, error)
+
+	UpdateTime(ctx context.Context, changes hitron.TimeChanges) (hitron.Time, error)
+	UpdateDNS(ctx context.Context, changes hitron.DNSChanges) (hitron.DNS, error)
+	SetDMZ(ctx context.Context, host net.IP) (hitron.RouterDMZ, []string, error)
+	DisableDMZ(ctx context.Context) (hitron.RouterDMZ, error)
+	AddPortForwardRule(ctx context.Context, rule hitron.PortForwardRule) (hitron.RouterPortForwardall, error)
+	UpdatePortForwardRule(ctx context.Context, rule hitron.PortForwardRule) (hitron.RouterPortForwardall, error)
+	DeletePortForwardRule(ctx context.Context, id int) (hitron.RouterPortForwardall, error)
+	AddPortTriggerRule(ctx context.Context, rule hitron.PortTriggerRule) (hitron.RouterPortTriggerall, error)
+	UpdatePortTriggerRule(ctx context.Context, rule hitron.PortTriggerRule) (hitron.RouterPortTriggerall, error)
+	DeletePortTriggerRule(ctx context.Context, id int) (hitron.RouterPortTriggerall, error)
+	UpdateSSID(ctx context.Context, id int, changes hitron.SSIDChanges) (hitron.SSID, error)
+	UpdateGuestSSID(ctx context.Context, changes hitron.GuestSSIDChanges) (hitron.WiFiGuestSSID, error)
+	AddKeywordFilterRule(ctx context.Context, rule hitron.KeywordFilterRule) (hitron.FirewallKeywordFilterRules, error)
+	UpdateKeywordFilterRule(ctx context.Context, rule hitron.KeywordFilterRule) (hitron.FirewallKeywordFilterRules, error)
+	DeleteKeywordFilterRule(ctx context.Context, id int) (hitron.FirewallKeywordFilterRules, error)
+	SetKeywordFilterEnabled(ctx context.Context, enable bool) (hitron.FirewallKeywordFilterStatus, error)
+	AddKeywordFilterTrust(ctx context.Context, rule hitron.TrustRule) (hitron.FirewallKeywordFilterTrustRules, error)
+	DeleteKeywordFilterTrust(ctx context.Context, mac net.HardwareAddr) (hitron.FirewallKeywordFilterTrustRules, error)
+	AddServiceFilterRule(ctx context.Context, rule hitron.ServiceFilterRule) (hitron.FirewallServiceFilterRules, error)
+	UpdateServiceFilterRule(ctx context.Context, rule hitron.ServiceFilterRule) (hitron.FirewallServiceFilterRules, error)
+	DeleteServiceFilterRule(ctx context.Context, id int) (hitron.FirewallServiceFilterRules, error)
+	SetServiceFilterEnabled(ctx context.Context, enable bool) (hitron.FirewallServiceFilterStatus, error)
+	AddServiceFilterTrust(ctx context.Context, rule hitron.TrustRule) (hitron.FirewallServiceFilterTrustRules, error)
+	DeleteServiceFilterTrust(ctx context.Context, mac net.HardwareAddr) (hitron.FirewallServiceFilterTrustRules, error)
+}
+
+// Action - the kind of change to be made to a setting or rule
+type Action string
+
+// Actions
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Change describes a single difference between the desired and the live
+// configuration
+type Change struct {
+	Section string // e.g. "dns"
+	Name    string // the setting or rule name
+	Action  Action
+	Old     string // the live value (empty for Create)
+	New     string // the desired value (empty for Delete)
+}
+
+func (c Change) String() string {
+	switch c.Action {
+	case Create:
+		return "+ " + c.Name + ": " + c.New
+	case Delete:
+		return "- " + c.Name + ": " + c.Old
+	default:
+		return "~ " + c.Name + ": " + c.Old + " -> " + c.New
+	}
+}
+
+// section - the changes for one section of the configuration, and the function
+// which writes them to the modem (nil if the section can't be written). The
+// function reports non-fatal problems with warn.
+type section struct {
+	apply   func(ctx context.Context, warn func(string)) error
+	name    string
+	changes []Change
+}
+
+// Plan is the set of changes needed to bring the modem to the desired state
+type Plan struct {
+	sections []section
+	warnings []string
+}
+
+// NewPlan reads the live configuration of all sections managed by cfg, and
+// computes the changes required.
+func NewPlan(ctx context.Context, m Modem, cfg *Config) (*Plan, error) {
+	planners := []func(context.Context, Modem, *Config) (section, error){
+		planSSIDs,
+		planGuest,
+		planDNS,
+		planDMZ,
+		planPortForwards,
+		planPortTriggers,
+		planTime,
+		planKeywordFilter,
+		planServiceFilter,
+	}
+
+	p := &Plan{}
+
+	for _, planner := range planners {
+		s, err := planner(ctx, m, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(s.changes) > 0 {
+			p.sections = append(p.sections, s)
+		}
+	}
+
+	return p, nil
+}
+
+// Changes returns all changes in the plan
+func (p *Plan) Changes() []Change {
+	changes := []Change{}
+	for _, s := range p.sections {
+		changes = append(changes, s.changes...)
+	}
+
+	return changes
+}
+
+// Empty returns true when the modem is already in the desired state
+func (p *Plan) Empty() bool {
+	return len(p.sections) == 0
+}
+
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+
+	sb := strings.Builder{}
+
+	for _, s := range p.sections {
+		sb.WriteString(s.name)
+		sb.WriteString(":\n")
+
+		for _, c := range s.changes {
+			sb.WriteString("  ")
+			sb.WriteString(c.String())
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// Apply writes the planned changes to the modem, section by section. Nothing
+// is written if the plan contains changes to a section which can't be written.
+func (p *Plan) Apply(ctx context.Context) error {
+	for _, s := range p.sections {
+		if s.apply == nil {
+			return fmt.Errorf("changing %s settings: %w", s.name, errors.ErrUnsupported)
+		}
+	}
+
+	for _, s := range p.sections {
+		warn := func(w string) {
+			p.warnings = append(p.warnings, s.name+": "+w)
+		}
+
+		if err := s.apply(ctx, warn); err != nil {
+			return fmt.Errorf("failed to apply %s changes: %w", s.name, err)
+		}
+	}
+
+	return nil
+}
+
+// Warnings returns the non-fatal problems reported by the modem while the plan
+// was applied, such as a DMZ host with no DHCP reservation
+func (p *Plan) Warnings() []string {
+	return p.warnings
+}
+
+// differ - accumulates changes to scalar settings in a section
+type differ struct {
+	section string
+	changes []Change
+}
+
+func (d *differ) change(name, have, want string) {
+	d.changes = append(d.changes, Change{
+		Section: d.section,
+		Name:    name,
+		Action:  Update,
+		Old:     have,
+		New:     want,
+	})
+}
+
+func (d *differ) str(name string, want *string, have string) {
+	if want != nil && *want != have {
+		d.change(name, fmt.Sprintf("%q", have), fmt.Sprintf("%q", *want))
+	}
+}
+
+// secret - like str, but doesn't reveal the values
+func (d *differ) secret(name string, want *string, have string) {
+	if want != nil && *want != have {
+		d.change(name, "(hidden)", "(hidden)")
+	}
+}
+
+func (d *differ) boolean(name string, want *bool, have bool) {
+	if want != nil && *want != have {
+		d.change(name, fmt.Sprint(have), fmt.Sprint(*want))
+	}
+}
+
+func (d *differ) integer(name string, want *int, have int) {
+	if want != nil && *want != have {
+		d.change(name, fmt.Sprint(have), fmt.Sprint(*want))
+	}
+}
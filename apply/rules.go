@@ -0,0 +1,286 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func (p PortForward) rule() (hitron.PortForwardRule, error) {
+	r := hitron.PortForwardRule{
+		AppName:  p.Name,
+		Protocol: p.Protocol,
+		LocalIP:  net.ParseIP(p.LocalIP),
+		Enable:   p.Enable == nil || *p.Enable,
+	}
+
+	var err error
+
+	r.PublicPorts, err = hitron.ParsePortRange(p.PublicPorts)
+	if err != nil {
+		return r, fmt.Errorf("port forward %q: %w", p.Name, err)
+	}
+
+	r.PrivatePorts = r.PublicPorts
+	if p.PrivatePorts != "" {
+		r.PrivatePorts, err = hitron.ParsePortRange(p.PrivatePorts)
+		if err != nil {
+			return r, fmt.Errorf("port forward %q: %w", p.Name, err)
+		}
+	}
+
+	r.RemoteIPs = anyRemote()
+	if p.RemoteIPs != "" {
+		r.RemoteIPs, err = hitron.ParseIPRange(p.RemoteIPs)
+		if err != nil {
+			return r, fmt.Errorf("port forward %q: %w", p.Name, err)
+		}
+	}
+
+	return r, r.Validate()
+}
+
+func anyRemote() hitron.IPRange {
+	return hitron.IPRange{Start: net.IPv4zero, End: net.IPv4bcast}
+}
+
+func describePortForward(r hitron.PortForwardRule) string {
+	remote := r.RemoteIPs
+	if remote.Start == nil && remote.End == nil {
+		remote = anyRemote()
+	}
+
+	return fmt.Sprintf("%s %s -> %s:%s from %s, enabled=%t",
+		r.Protocol, r.PublicPorts, r.LocalIP, r.PrivatePorts, remote, r.Enable)
+}
+
+func planPortForwards(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "portForwards"}
+	if cfg.PortForwards == nil {
+		return s, nil
+	}
+
+	desired := make([]hitron.PortForwardRule, 0, len(cfg.PortForwards))
+	for _, pf := range cfg.PortForwards {
+		r, err := pf.rule()
+		if err != nil {
+			return s, err
+		}
+
+		desired = append(desired, r)
+	}
+
+	live, err := m.RouterPortForwardall(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read port forwarding rules: %w", err)
+	}
+
+	liveByName := make(map[string]hitron.PortForwardRule, len(live.Rules))
+	for _, r := range live.Rules {
+		liveByName[r.AppName] = r
+	}
+
+	var deletes []int
+
+	var updates, adds []hitron.PortForwardRule
+
+	names := map[string]bool{}
+
+	for _, want := range desired {
+		if names[want.AppName] {
+			return s, fmt.Errorf("duplicate port forward name %q", want.AppName)
+		}
+
+		names[want.AppName] = true
+
+		have, ok := liveByName[want.AppName]
+		if !ok {
+			adds = append(adds, want)
+			s.changes = append(s.changes, Change{
+				Section: s.name, Name: want.AppName, Action: Create,
+				New: describePortForward(want),
+			})
+
+			continue
+		}
+
+		if describePortForward(want) != describePortForward(have) {
+			want.ID = have.ID
+			want.Origin = have.Origin
+			updates = append(updates, want)
+			s.changes = append(s.changes, Change{
+				Section: s.name, Name: want.AppName, Action: Update,
+				Old: describePortForward(have), New: describePortForward(want),
+			})
+		}
+	}
+
+	for _, have := range live.Rules {
+		if !names[have.AppName] {
+			deletes = append(deletes, have.ID)
+			s.changes = append(s.changes, Change{
+				Section: s.name, Name: have.AppName, Action: Delete,
+				Old: describePortForward(have),
+			})
+		}
+	}
+
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		// delete first, to free up ports for the updated and added rules
+		for _, id := range deletes {
+			if _, err := m.DeletePortForwardRule(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range updates {
+			if _, err := m.UpdatePortForwardRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range adds {
+			if _, err := m.AddPortForwardRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return s, nil
+}
+
+func (p PortTrigger) rule() (hitron.PortTriggerRule, error) {
+	r := hitron.PortTriggerRule{
+		AppName:  p.Name,
+		Protocol: p.Protocol,
+		TwoWay:   p.TwoWay,
+		Enable:   p.Enable == nil || *p.Enable,
+	}
+
+	if p.Name == "" {
+		return r, fmt.Errorf("port trigger name must be set")
+	}
+
+	var err error
+
+	r.TriggerPorts, err = hitron.ParsePortRange(p.TriggerPorts)
+	if err != nil {
+		return r, fmt.Errorf("port trigger %q: trigger ports: %w", p.Name, err)
+	}
+
+	r.TargetPorts, err = hitron.ParsePortRange(p.TargetPorts)
+	if err != nil {
+		return r, fmt.Errorf("port trigger %q: target ports: %w", p.Name, err)
+	}
+
+	r.Timeout, err = time.ParseDuration(p.Timeout)
+	if err != nil {
+		return r, fmt.Errorf("port trigger %q: invalid timeout: %w", p.Name, err)
+	}
+
+	return r, r.Validate()
+}
+
+func describePortTrigger(r hitron.PortTriggerRule) string {
+	return fmt.Sprintf("%s %s opens %s for %s, twoWay=%t, enabled=%t",
+		r.Protocol, r.TriggerPorts, r.TargetPorts, r.Timeout, r.TwoWay, r.Enable)
+}
+
+func planPortTriggers(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "portTriggers"}
+	if cfg.PortTriggers == nil {
+		return s, nil
+	}
+
+	desired := make([]hitron.PortTriggerRule, 0, len(cfg.PortTriggers))
+	for _, pt := range cfg.PortTriggers {
+		r, err := pt.rule()
+		if err != nil {
+			return s, err
+		}
+
+		desired = append(desired, r)
+	}
+
+	live, err := m.RouterPortTriggerall(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read port triggering rules: %w", err)
+	}
+
+	liveByName := make(map[string]hitron.PortTriggerRule, len(live.Rules))
+	for _, r := range live.Rules {
+		liveByName[r.AppName] = r
+	}
+
+	var deletes []int
+
+	var updates, adds []hitron.PortTriggerRule
+
+	names := map[string]bool{}
+
+	for _, want := range desired {
+		if names[want.AppName] {
+			return s, fmt.Errorf("duplicate port trigger name %q", want.AppName)
+		}
+
+		names[want.AppName] = true
+
+		have, ok := liveByName[want.AppName]
+
+		switch {
+		case !ok:
+			adds = append(adds, want)
+			s.changes = append(s.changes, Change{
+				Section: s.name, Name: want.AppName, Action: Create,
+				New: describePortTrigger(want),
+			})
+		case describePortTrigger(want) != describePortTrigger(have):
+			want.ID = have.ID
+			updates = append(updates, want)
+			s.changes = append(s.changes, Change{
+				Section: s.name, Name: want.AppName, Action: Update,
+				Old: describePortTrigger(have), New: describePortTrigger(want),
+			})
+		}
+	}
+
+	for _, have := range live.Rules {
+		if !names[have.AppName] {
+			deletes = append(deletes, have.ID)
+			s.changes = append(s.changes, Change{
+				Section: s.name, Name: have.AppName, Action: Delete,
+				Old: describePortTrigger(have),
+			})
+		}
+	}
+
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		// delete first, to free up ports for the updated and added rules
+		for _, id := range deletes {
+			if _, err := m.DeletePortTriggerRule(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range updates {
+			if _, err := m.UpdatePortTriggerRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range adds {
+			if _, err := m.AddPortTriggerRule(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return s, nil
+}
@@ -0,0 +1,314 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func planSSIDs(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "ssids"}
+	if len(cfg.SSIDs) == 0 {
+		return s, nil
+	}
+
+	live, err := m.WiFiSSIDs(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read SSIDs: %w", err)
+	}
+
+	d := differ{section: s.name}
+	updates := map[int]hitron.SSIDChanges{}
+
+	for _, want := range cfg.SSIDs {
+		found := false
+
+		for _, have := range live.SSIDs {
+			if have.ID != want.ID {
+				continue
+			}
+
+			found = true
+			prefix := strconv.Itoa(want.ID) + "/"
+			n := len(d.changes)
+
+			d.str(prefix+"name", want.Name, have.Name)
+			d.secret(prefix+"passphrase", want.Passphrase, have.Passphrase)
+			d.boolean(prefix+"enable", want.Enable, have.Enable)
+			d.boolean(prefix+"visible", want.Visible, have.Visible)
+
+			if len(d.changes) > n {
+				updates[want.ID] = hitron.SSIDChanges{
+					Name:       want.Name,
+					Passphrase: want.Passphrase,
+					Enable:     want.Enable,
+					Visible:    want.Visible,
+				}
+			}
+		}
+
+		if !found {
+			return s, fmt.Errorf("no SSID with ID %d on the modem", want.ID)
+		}
+	}
+
+	s.changes = d.changes
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		for _, want := range cfg.SSIDs {
+			changes, ok := updates[want.ID]
+			if !ok {
+				continue
+			}
+
+			if _, err := m.UpdateSSID(ctx, want.ID, changes); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return s, nil
+}
+
+func planGuest(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "guest"}
+
+	want := cfg.Guest
+	if want == nil {
+		return s, nil
+	}
+
+	have, err := m.WiFiGuestSSID(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read guest network: %w", err)
+	}
+
+	d := differ{section: s.name}
+	d.boolean("enable", want.Enable, have.Enable)
+	d.str("ssid", want.SSID, have.SSID)
+	d.str("ssid5G", want.SSID5G, have.SSID5G)
+	d.secret("password", want.Password, have.Password)
+	d.integer("maxUsers", want.MaxUsers, have.MaxUsers)
+
+	s.changes = d.changes
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		_, err := m.UpdateGuestSSID(ctx, hitron.GuestSSIDChanges{
+			SSID:     want.SSID,
+			SSID5G:   want.SSID5G,
+			Password: want.Password,
+			MaxUsers: want.MaxUsers,
+			Enable:   want.Enable,
+		})
+
+		return err
+	}
+
+	return s, nil
+}
+
+// ipString - like net.IP.String, but empty for unset/unspecified addresses
+func ipString(ip net.IP) string {
+	if len(ip) == 0 || ip.IsUnspecified() {
+		return ""
+	}
+
+	return ip.String()
+}
+
+// parseOptionalIP - parses the IP, returning the canonical string form
+func parseOptionalIP(name string, s *string) (*string, error) {
+	if s == nil || *s == "" {
+		return s, nil
+	}
+
+	ip := net.ParseIP(*s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid %s %q: not an IP address", name, *s)
+	}
+
+	out := ip.String()
+
+	return &out, nil
+}
+
+// optionalIP - the parsed form of an optional IP from parseOptionalIP, where
+// an empty string means no address
+func optionalIP(s *string) *net.IP {
+	if s == nil {
+		return nil
+	}
+
+	ip := net.ParseIP(*s)
+
+	return &ip
+}
+
+func planDNS(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "dns"}
+
+	want := cfg.DNS
+	if want == nil {
+		return s, nil
+	}
+
+	dns1, err := parseOptionalIP("lanDNS1", want.LanDNS1)
+	if err != nil {
+		return s, err
+	}
+
+	dns2, err := parseOptionalIP("lanDNS2", want.LanDNS2)
+	if err != nil {
+		return s, err
+	}
+
+	have, err := m.DNS(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read DNS settings: %w", err)
+	}
+
+	d := differ{section: s.name}
+	d.boolean("auto", want.Auto, have.AutoEnable)
+	d.boolean("proxy", want.Proxy, have.ProxyEnable)
+	d.str("lanDNS1", dns1, ipString(have.LanDNS1))
+	d.str("lanDNS2", dns2, ipString(have.LanDNS2))
+	d.str("domainSuffix", want.DomainSuffix, have.DomainSuffix)
+
+	changes := hitron.DNSChanges{
+		LanDNS1:      optionalIP(dns1),
+		LanDNS2:      optionalIP(dns2),
+		DomainSuffix: want.DomainSuffix,
+		AutoEnable:   want.Auto,
+		ProxyEnable:  want.Proxy,
+	}
+
+	s.changes = d.changes
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		_, err := m.UpdateDNS(ctx, changes)
+
+		return err
+	}
+
+	return s, nil
+}
+
+func planDMZ(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "dmz"}
+
+	want := cfg.DMZ
+	if want == nil {
+		return s, nil
+	}
+
+	var host *string
+
+	if want.Enable {
+		h, err := parseOptionalIP("DMZ host", &want.Host)
+		if err != nil {
+			return s, err
+		}
+
+		if *h == "" {
+			return s, fmt.Errorf("a DMZ host must be given when the DMZ is enabled")
+		}
+
+		host = h
+	}
+
+	have, err := m.RouterDMZ(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read DMZ settings: %w", err)
+	}
+
+	d := differ{section: s.name}
+	d.boolean("enable", &want.Enable, have.Enable)
+	d.str("host", host, ipString(have.Host))
+
+	s.changes = d.changes
+	s.apply = func(ctx context.Context, warn func(string)) error {
+		if !want.Enable {
+			_, err := m.DisableDMZ(ctx)
+
+			return err
+		}
+
+		_, warnings, err := m.SetDMZ(ctx, net.ParseIP(*host))
+		for _, w := range warnings {
+			warn(w)
+		}
+
+		return err
+	}
+
+	return s, nil
+}
+
+func planTime(ctx context.Context, m Modem, cfg *Config) (section, error) {
+	s := section{name: "time"}
+
+	want := cfg.Time
+	if want == nil {
+		return s, nil
+	}
+
+	// compare zones as the modem will report them, since it only supports a
+	// subset of locations
+	var (
+		loc  *time.Location
+		zone *string
+	)
+
+	if want.Zone != nil {
+		l, err := time.LoadLocation(*want.Zone)
+		if err != nil {
+			return s, fmt.Errorf("invalid time zone %q: %w", *want.Zone, err)
+		}
+
+		loc, err = hitron.SupportedLocation(l)
+		if err != nil {
+			return s, err
+		}
+
+		name := loc.String()
+		zone = &name
+	}
+
+	have, err := m.Time(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to read time settings: %w", err)
+	}
+
+	haveZone := ""
+	if have.TZ != nil {
+		haveZone = have.TZ.String()
+		if l, err := hitron.SupportedLocation(have.TZ); err == nil {
+			haveZone = l.String()
+		}
+	}
+
+	d := differ{section: s.name}
+	d.boolean("enable", want.Enable, have.Enable)
+	d.str("sntpServer", want.SNTPServer, have.SNTPServer)
+	d.str("zone", zone, haveZone)
+	d.boolean("daylight", want.Daylight, have.Daylight)
+
+	changes := hitron.TimeChanges{
+		TZ:         loc,
+		SNTPServer: want.SNTPServer,
+		Enable:     want.Enable,
+		Daylight:   want.Daylight,
+	}
+
+	s.changes = d.changes
+	s.apply = func(ctx context.Context, _ func(string)) error {
+		_, err := m.UpdateTime(ctx, changes)
+
+		return err
+	}
+
+	return s, nil
+}
@@ -127,7 +127,7 @@ type PortInfo struct {
 	SignalStrength float64 `json:"signalStrength,string"` // signal strength of the downstream channel, in dBmV (dB above/below 1 mV)
 	Frequency      int64   `json:"frequency,string"`      // in Hz
 	// upstream-only
-	Bandwidth int64 `json:"bandwidth,string,omitempty"` // maximum available upstream bandwidth (in bits/sec, maybe?)
+	Bandwidth int64 `json:"bandwidth,string,omitempty"` // maximum available upstream bandwidth (unit undocumented - the channel width in Hz, maybe?)
 	// downstream-only
 	SNR        float64 `json:"snr,string"`        // signal-to-noise ratio of the downstream data channel, in dB
 	DsOctets   int64   `json:"dsoctets,string"`   // number of octets/bytes received
@@ -250,7 +250,10 @@ func (s *CMSysInfo) UnmarshalJSON(b []byte) error {
 
 	s.Error = raw.Error
 	s.NetworkAccess = raw.NetworkAccess
-	s.IP = raw.IPs[0]
+	if len(raw.IPs) > 0 {
+		s.IP = raw.IPs[0]
+	}
+
 	s.GW = raw.GW
 	s.Configname = raw.Configname
 
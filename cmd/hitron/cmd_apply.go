@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"github.com/hairyhenderson/hitron_coda/apply"
+)
+
+func cmdApply(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
+	file := f.String("f", "", "path to the desired-state configuration `file` (YAML)")
+	dryRun := f.Bool("dry-run", false, "only print the changes which would be made")
+
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), `usage: apply -f <file> [-dry-run]
+
+Reconcile the modem's configuration with the desired state in the given file.
+Only settings which differ are changed.
+
+flags:
+`)
+		f.PrintDefaults()
+	}
+
+	_ = f.Parse(argv)
+
+	if *file == "" {
+		f.Usage()
+
+		return fmt.Errorf("no configuration file given")
+	}
+
+	cfg, err := apply.LoadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	if err := cm.Login(ctx); err != nil {
+		return err
+	}
+
+	defer logout(ctx, cm)
+
+	plan, err := apply.NewPlan(ctx, cm, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(plan)
+
+	if *dryRun || plan.Empty() {
+		return nil
+	}
+
+	err = plan.Apply(ctx)
+
+	for _, w := range plan.Warnings() {
+		slog.WarnContext(ctx, w)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Applied %d change(s).\n", len(plan.Changes()))
+
+	return nil
+}
@@ -3,63 +3,38 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
+	"os"
 
 	hitron "github.com/hairyhenderson/hitron_coda"
 )
 
-func cmdCM(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
-	f.Usage = func() {
-		f.PrintDefaults()
-		fmt.Fprintf(f.Output(), `
-subcommands:
-	version
-		Print cable modem version information
-	reboot
-		Reboot the cable modem
-	log
-		Print cable modem logs
-	clearLog
-		Clear cable modem logs
-	sysInfo
-		Print cable modem system information
-`)
+func cmdCM(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"reboot": {
+			usage: "reboot",
+			help:  "Reboot the cable modem",
+			fn: func(ctx context.Context, _ []string) error {
+				out, err := cm.CMReboot(ctx)
+				if err != nil {
+					return err
+				}
+
+				return render(os.Stdout, format, out)
+			},
+		},
+		"clearLog": {
+			usage: "clearLog",
+			help:  "Clear cable modem logs",
+			fn: func(ctx context.Context, _ []string) error {
+				out, err := cm.CMClearLog(ctx)
+				if err != nil {
+					return err
+				}
+
+				return render(os.Stdout, format, out)
+			},
+		},
 	}
 
-	_ = f.Parse(argv)
-
-	args := f.Args()
-	if len(args) == 0 {
-		f.Usage()
-
-		return nil
-	}
-
-	cmds := map[string]func(ctx context.Context) (fmt.Stringer, error){
-		"version":  func(ctx context.Context) (fmt.Stringer, error) { return cm.CMVersion(ctx) },
-		"reboot":   func(ctx context.Context) (fmt.Stringer, error) { return cm.CMReboot(ctx) },
-		"log":      func(ctx context.Context) (fmt.Stringer, error) { return cm.CMLog(ctx) },
-		"clearLog": func(ctx context.Context) (fmt.Stringer, error) { return cm.CMClearLog(ctx) },
-		"sysInfo":  func(ctx context.Context) (fmt.Stringer, error) { return cm.CMSysInfo(ctx) },
-	}
-
-	c, ok := cmds[args[0]]
-	if !ok {
-		return fmt.Errorf("unknown subcommand: %s", args[0])
-	}
-
-	if err := cm.Login(ctx); err != nil {
-		return err
-	}
-
-	defer func() { _ = cm.Logout(ctx) }()
-
-	out, err := c(ctx)
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("%s", out)
-
-	return nil
+	return cmdGroup(ctx, cm, format, f, actions, argv)
 }
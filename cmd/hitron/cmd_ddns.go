@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func cmdDDNS(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"set": {
+			usage: "set [-provider p] [-username u] [-password p] [-hostname h] [-interval d] [-enable on|off]",
+			help:  "Change the dynamic DNS client settings",
+			fn: func(ctx context.Context, argv []string) error {
+				return ddnsSet(ctx, cm, format, flag.NewFlagSet("set", flag.ExitOnError), argv)
+			},
+		},
+		"enable": {
+			usage: "enable",
+			help:  "Enable the dynamic DNS client",
+			fn: func(ctx context.Context, _ []string) error {
+				return ddnsEnable(ctx, cm, format, true)
+			},
+		},
+		"disable": {
+			usage: "disable",
+			help:  "Disable the dynamic DNS client",
+			fn: func(ctx context.Context, _ []string) error {
+				return ddnsEnable(ctx, cm, format, false)
+			},
+		},
+		"providers": {
+			usage: "providers",
+			help:  "List the dynamic DNS providers the firmware supports",
+			fn: func(_ context.Context, _ []string) error {
+				fmt.Println(strings.Join(hitron.DDNSProviders(), "\n"))
+
+				return nil
+			},
+		},
+	}
+
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func ddnsSet(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	changes := hitron.DDNSChanges{}
+
+	stringFlag(f, &changes.Provider, "provider", "DDNS `provider` (see the providers command)")
+	stringFlag(f, &changes.Username, "username", "`username` for the provider")
+	stringFlag(f, &changes.Password, "password", "`password` for the provider")
+	stringFlag(f, &changes.Hostname, "hostname", "`hostname` to update (comma-separated for several)")
+	f.Func("interval", "how often to update the hostname (`duration`, e.g. 24h)", func(s string) error {
+		d, err := time.ParseDuration(s)
+		changes.UpdateInterval = &d
+
+		return err
+	})
+	boolFlag(f, &changes.Enable, "enable", "enable the DDNS client")
+
+	_ = f.Parse(argv)
+
+	if f.NFlag() == 0 {
+		f.Usage()
+
+		return fmt.Errorf("no changes given")
+	}
+
+	out, err := cm.UpdateDDNS(ctx, changes)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+func ddnsEnable(ctx context.Context, cm *hitron.CableModem, format OutputFormat, enable bool) error {
+	out, err := cm.SetDDNSEnabled(ctx, enable)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func cmdDNS(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"set": {
+			usage: "set [-auto on|off] [-dns1 ip] [-dns2 ip] [-proxy on|off] [-suffix domain]",
+			help:  "Change the DNS settings given to LAN clients, and confirm they took effect",
+			fn: func(ctx context.Context, argv []string) error {
+				return dnsSet(ctx, cm, format, flag.NewFlagSet("set", flag.ExitOnError), argv)
+			},
+		},
+	}
+
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func dnsSet(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	changes := hitron.DNSChanges{}
+
+	boolFlag(f, &changes.AutoEnable, "auto", "set DNS servers automatically (off to use -dns1 and -dns2)")
+	ipFlag(f, &changes.LanDNS1, "dns1", "primary DNS server `address` (IPv4 or IPv6)")
+	ipFlag(f, &changes.LanDNS2, "dns2", "secondary DNS server `address` (IPv4 or IPv6, or \"\" to clear)")
+	boolFlag(f, &changes.ProxyEnable, "proxy", "proxy DNS requests from LAN clients through the router")
+	stringFlag(f, &changes.DomainSuffix, "suffix", "LAN domain `suffix`")
+
+	_ = f.Parse(argv)
+
+	if f.NFlag() == 0 {
+		f.Usage()
+
+		return fmt.Errorf("no changes given")
+	}
+
+	out, err := cm.UpdateDNS(ctx, changes)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// ipFlag defines a flag which sets *p only when it's given. An empty value
+// sets a nil address.
+func ipFlag(f *flag.FlagSet, p **net.IP, name, usage string) {
+	f.Func(name, usage, func(s string) error {
+		var ip net.IP
+
+		if s != "" {
+			ip = net.ParseIP(s)
+			if ip == nil {
+				return fmt.Errorf("invalid IP address %q", s)
+			}
+		}
+
+		*p = &ip
+
+		return nil
+	})
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func cmdExporter(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
+	listen := f.String("listen", ":9779", "address to listen on for scrapes")
+	timeout := f.Duration("timeout", 10*time.Second, "maximum time to spend collecting metrics for a single scrape")
+
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), `usage: exporter <flags>
+
+Serve cable modem and router metrics at /metrics, in Prometheus text format.
+
+flags:
+`)
+		f.PrintDefaults()
+	}
+
+	_ = f.Parse(argv)
+
+	if err := cm.Login(ctx); err != nil {
+		return err
+	}
+
+	defer logout(ctx, cm)
+
+	e := &exporter{cm: cm, timeout: *timeout}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.InfoContext(ctx, "serving metrics", slog.String("addr", *listen))
+
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// exporter collects metrics from the modem on each scrape. Scrapes are
+// serialized so that a single session is shared.
+type exporter struct {
+	cm      *hitron.CableModem
+	mu      sync.Mutex
+	timeout time.Duration
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), e.timeout)
+	defer cancel()
+
+	m := e.collect(ctx)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, err := m.WriteTo(w)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to write metrics", slog.Any("err", err))
+	}
+}
+
+func (e *exporter) collect(ctx context.Context) *metricSet {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := time.Now()
+	m := newMetricSet()
+
+	collectors := []struct {
+		fn   func(context.Context, *metricSet) error
+		name string
+	}{
+		{e.collectDownstream, "downstream"},
+		{e.collectUpstream, "upstream"},
+		{e.collectDownstreamOFDM, "downstream_ofdm"},
+		{e.collectUpstreamOFDMA, "upstream_ofdma"},
+		{e.collectRouter, "router"},
+		{e.collectWiFiClients, "wifi_clients"},
+	}
+
+	for _, c := range collectors {
+		success := 1.0
+
+		err := c.fn(ctx, m)
+		if err != nil {
+			slog.WarnContext(ctx, "collector failed", slog.String("collector", c.name), slog.Any("err", err))
+
+			success = 0
+		}
+
+		m.gauge("hitron_scrape_collector_success", "Whether a collector succeeded.", success, "collector", c.name)
+	}
+
+	m.gauge("hitron_scrape_duration_seconds", "Time taken to collect metrics from the modem.",
+		time.Since(start).Seconds())
+
+	return m
+}
+
+func channelLabels(p hitron.PortInfo) []string {
+	return []string{
+		"channel_id", p.ChannelID,
+		"port_id", p.PortID,
+		"frequency", strconv.FormatInt(p.Frequency, 10),
+		"modulation", p.Modulation,
+	}
+}
+
+func (e *exporter) collectDownstream(ctx context.Context, m *metricSet) error {
+	info, err := e.cm.CMDsInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range info.Ports {
+		l := channelLabels(p)
+
+		m.gauge("hitron_downstream_signal_strength_dbmv", "Downstream channel signal strength, in dBmV.", p.SignalStrength, l...)
+		m.gauge("hitron_downstream_snr_db", "Downstream channel signal-to-noise ratio, in dB.", p.SNR, l...)
+		m.counter("hitron_downstream_corrected_total", "Corrupt blocks which were corrected.", float64(p.Correcteds), l...)
+		m.counter("hitron_downstream_uncorrectable_total", "Corrupt blocks which could not be corrected.", float64(p.Uncorrect), l...)
+		m.counter("hitron_downstream_received_bytes_total", "Bytes received on the downstream channel.", float64(p.DsOctets), l...)
+	}
+
+	return nil
+}
+
+func (e *exporter) collectUpstream(ctx context.Context, m *metricSet) error {
+	info, err := e.cm.CMUsInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range info.Ports {
+		l := channelLabels(p)
+
+		m.gauge("hitron_upstream_signal_strength_dbmv", "Upstream channel signal strength, in dBmV.", p.SignalStrength, l...)
+		m.gauge("hitron_upstream_bandwidth", "Upstream channel bandwidth, as reported by the modem (the unit is not documented).", float64(p.Bandwidth), l...)
+	}
+
+	return nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func (e *exporter) collectDownstreamOFDM(ctx context.Context, m *metricSet) error {
+	info, err := e.cm.CMDsOfdm(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range info.Receivers {
+		l := []string{
+			"receiver", strconv.Itoa(r.ID),
+			"frequency", strconv.FormatInt(r.SubcarrierFreq, 10),
+			"fft_type", r.FFTType,
+		}
+
+		m.gauge("hitron_downstream_ofdm_plc_power_dbmv", "OFDM receiver PLC power level, in dBmV.", r.PLCPower, l...)
+		m.gauge("hitron_downstream_ofdm_plc_locked", "Whether the OFDM receiver's PLC is locked.", boolToFloat(r.PLCLocked), l...)
+		m.gauge("hitron_downstream_ofdm_ncp_locked", "Whether the OFDM receiver's NCP is locked.", boolToFloat(r.NCPLocked), l...)
+		m.gauge("hitron_downstream_ofdm_mdc1_locked", "Whether the OFDM receiver's MDC1 is locked.", boolToFloat(r.MDC1Locked), l...)
+	}
+
+	return nil
+}
+
+func (e *exporter) collectUpstreamOFDMA(ctx context.Context, m *metricSet) error {
+	info, err := e.cm.CMUsOfdm(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range info.Channels {
+		l := []string{
+			"channel_id", strconv.Itoa(c.ID),
+			"fft_size", c.FFTSize,
+		}
+
+		m.gauge("hitron_upstream_ofdma_enabled", "Whether the OFDMA channel is enabled.", boolToFloat(c.Enable), l...)
+		m.gauge("hitron_upstream_ofdma_digital_attenuation_db", "OFDMA channel digital attenuation, in dB.", c.DigAtten, l...)
+		m.gauge("hitron_upstream_ofdma_bandwidth_mhz", "OFDMA channel bandwidth, in MHz.", c.ChannelBw, l...)
+		m.gauge("hitron_upstream_ofdma_reported_power_qdbmv", "OFDMA channel reported power, in quarter-dBmV.", c.RepPower, l...)
+	}
+
+	return nil
+}
+
+func (e *exporter) collectRouter(ctx context.Context, m *metricSet) error {
+	info, err := e.cm.RouterSysInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.counter("hitron_router_wan_received_bytes_total", "Bytes received on the WAN interface.", float64(info.WanRx))
+	m.counter("hitron_router_wan_transmitted_bytes_total", "Bytes transmitted on the WAN interface.", float64(info.WanTx))
+	m.counter("hitron_router_wan_received_packets_total", "Packets received on the WAN interface.", float64(info.WanRxPkts))
+	m.counter("hitron_router_wan_transmitted_packets_total", "Packets transmitted on the WAN interface.", float64(info.WanTxPkts))
+	m.counter("hitron_router_lan_received_bytes_total", "Bytes received on the LAN interface.", float64(info.LanRx))
+	m.counter("hitron_router_lan_transmitted_bytes_total", "Bytes transmitted on the LAN interface.", float64(info.LanTx))
+	m.gauge("hitron_router_lan_uptime_seconds", "Time since the LAN interface came up.", info.SystemLanUptime.Seconds())
+	m.gauge("hitron_router_wan_uptime_seconds", "Time since the WAN interface came up.", info.SystemWanUptime.Seconds())
+	m.gauge("hitron_router_info", "Router model and software version.", 1,
+		"model", info.ModelName,
+		"software_version", info.SoftwareVersion,
+		"router_mode", info.RouterMode)
+
+	return nil
+}
+
+func (e *exporter) collectWiFiClients(ctx context.Context, m *metricSet) error {
+	info, err := e.cm.WiFiClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range info.Clients {
+		l := []string{
+			"mac", c.MACAddr.String(),
+			"hostname", c.Hostname,
+			"band", c.Band,
+			"ssid", c.SSID,
+			"channel", strconv.Itoa(c.Channel),
+		}
+
+		m.gauge("hitron_wifi_client_rssi_dbm", "Received signal strength of the WiFi client, in dBm.", float64(c.RSSI), l...)
+		m.gauge("hitron_wifi_client_data_rate_bits_per_second", "Negotiated data rate of the WiFi client, in bits per second.", float64(c.DataRate), l...)
+	}
+
+	return nil
+}
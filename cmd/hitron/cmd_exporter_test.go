@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"github.com/hairyhenderson/hitron_coda/hitrontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter(t *testing.T) {
+	modem := hitrontest.NewServer("cusadmin", "password")
+	t.Cleanup(modem.Close)
+
+	cm, err := hitron.New(modem.Host(), "cusadmin", "password", hitron.WithScheme("http"))
+	require.NoError(t, err)
+	require.NoError(t, cm.Login(context.Background()))
+
+	srv := httptest.NewServer(&exporter{cm: cm, timeout: 5 * time.Second})
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+
+	// every sample follows the HELP and TYPE lines of its own family, and each
+	// family is only described once
+	described := map[string]bool{}
+	family := ""
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			family = strings.Fields(line)[2]
+			assert.False(t, described[family], "%s described twice", family)
+			described[family] = true
+
+			require.Less(t, i+1, len(lines))
+			assert.Regexp(t, `^# TYPE `+family+` (gauge|counter)$`, lines[i+1])
+		case strings.HasPrefix(line, "# TYPE "):
+		default:
+			name, _, _ := strings.Cut(line, " ")
+			name, _, _ = strings.Cut(name, "{")
+			assert.Equal(t, family, name, line)
+		}
+	}
+
+	for _, sample := range []string{
+		`hitron_downstream_snr_db{channel_id="11",port_id="1",frequency="615000000",modulation="QAM256"} 38.605`,
+		`hitron_downstream_corrected_total{channel_id="9",port_id="2",frequency="603000000",modulation="QAM256"} 3`,
+		`hitron_upstream_bandwidth{channel_id="3",port_id="1",frequency="32300000",modulation="64QAM"} 6.4e+06`,
+		`hitron_router_info{model="CODA-4680-TPIA",software_version="7.1.1.2.2b9",router_mode="Dualstack"} 1`,
+		`hitron_wifi_client_data_rate_bits_per_second{mac="ca:fe:de:ad:be:ef",hostname="foo",band="2.4G",ssid="CODA",channel="3"} 1.048576e+07`,
+		`hitron_scrape_collector_success{collector="downstream"} 1`,
+		`hitron_scrape_collector_success{collector="wifi_clients"} 1`,
+	} {
+		assert.Contains(t, lines, sample)
+	}
+
+	assert.True(t, described["hitron_scrape_duration_seconds"])
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func cmdFirewall(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"inbound": {
+			usage: "inbound [add|update|delete|enable|disable|all] ...",
+			help:  "Print or change the inbound firewall rules, or turn them on or off",
+			fn: func(ctx context.Context, argv []string) error {
+				return firewallInbound(ctx, cm, format, argv)
+			},
+		},
+		"keyword": {
+			usage: "keyword [add|delete|enable|disable|all|trust] ...",
+			help:  "Print or change the blocked URL keywords and the devices exempt from them",
+			fn: func(ctx context.Context, argv []string) error {
+				return firewallKeyword(ctx, cm, format, argv)
+			},
+		},
+		"service": {
+			usage: "service [add|update|delete|enable|disable|all|trust] ...",
+			help:  "Print or change the blocked services and the devices exempt from them",
+			fn: func(ctx context.Context, argv []string) error {
+				return firewallService(ctx, cm, format, argv)
+			},
+		},
+	}
+
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func firewallInbound(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	name := ""
+	if len(argv) > 0 {
+		name, argv = argv[0], argv[1:]
+	}
+
+	var (
+		out interface{}
+		err error
+	)
+
+	switch name {
+	case "":
+		out, err = cm.FirewallInboundRules(ctx)
+	case "add":
+		rule := hitron.InboundRule{Protocol: "BOTH", Action: hitron.FirewallAllow, Enable: true}
+
+		err = inboundFlags(flag.NewFlagSet("add", flag.ExitOnError), &rule, argv)
+		if err != nil {
+			return err
+		}
+
+		out, err = cm.AddInboundRule(ctx, rule)
+	case "update":
+		rule, rest, ierr := inboundArg(ctx, cm, argv)
+		if ierr != nil {
+			return ierr
+		}
+
+		err = inboundFlags(flag.NewFlagSet("update", flag.ExitOnError), &rule, rest)
+		if err != nil {
+			return err
+		}
+
+		out, err = cm.UpdateInboundRule(ctx, rule)
+	case "delete", "enable", "disable":
+		if len(argv) != 1 {
+			return fmt.Errorf("usage: inbound %s <id>", name)
+		}
+
+		id, perr := strconv.Atoi(argv[0])
+		if perr != nil {
+			return fmt.Errorf("invalid rule ID %q", argv[0])
+		}
+
+		if name == "delete" {
+			out, err = cm.DeleteInboundRule(ctx, id)
+		} else {
+			out, err = cm.SetInboundRuleEnabled(ctx, id, name == "enable")
+		}
+	case "all":
+		if len(argv) != 1 || (argv[0] != "on" && argv[0] != "off") {
+			return fmt.Errorf("usage: inbound all on|off")
+		}
+
+		out, err = cm.SetInboundEnabled(ctx, argv[0] == "on")
+	default:
+		return fmt.Errorf("unknown inbound subcommand: %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// inboundArg finds the inbound rule with the ID given as the first argument,
+// and returns it with the remaining arguments
+func inboundArg(ctx context.Context, cm *hitron.CableModem, argv []string) (hitron.InboundRule, []string, error) {
+	if len(argv) < 1 {
+		return hitron.InboundRule{}, nil, fmt.Errorf("usage: inbound update <id> [flags]")
+	}
+
+	id, err := strconv.Atoi(argv[0])
+	if err != nil {
+		return hitron.InboundRule{}, nil, fmt.Errorf("invalid rule ID %q", argv[0])
+	}
+
+	all, err := cm.FirewallInboundRules(ctx)
+	if err != nil {
+		return hitron.InboundRule{}, nil, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID == id {
+			return rule, argv[1:], nil
+		}
+	}
+
+	return hitron.InboundRule{}, nil, fmt.Errorf("no inbound rule with ID %d", id)
+}
+
+// inboundFlags parses flags into the given rule, leaving fields alone when
+// their flags aren't given
+func inboundFlags(f *flag.FlagSet, rule *hitron.InboundRule, argv []string) error {
+	var enable *bool
+
+	f.StringVar(&rule.Name, "name", rule.Name, "rule `name`")
+	f.StringVar(&rule.Protocol, "protocol", rule.Protocol, "`protocol` (TCP, UDP, or BOTH)")
+	f.StringVar(&rule.Action, "action", rule.Action, "`action` for matching traffic (Allow or Deny)")
+	f.Func("ports", "destination `ports` (e.g. 22 or 8080-8088)", func(s string) (err error) {
+		rule.Ports, err = hitron.ParsePortRange(s)
+
+		return err
+	})
+	f.Func("sources", "source `addresses` (e.g. 203.0.113.1-203.0.113.254, or \"\" for any)", func(s string) (err error) {
+		if s == "" {
+			rule.Sources = hitron.IPRange{}
+
+			return nil
+		}
+
+		rule.Sources, err = hitron.ParseIPRange(s)
+
+		return err
+	})
+	boolFlag(f, &enable, "enable", "enable the rule")
+
+	if err := f.Parse(argv); err != nil {
+		return err
+	}
+
+	if enable != nil {
+		rule.Enable = *enable
+	}
+
+	return nil
+}
+
+func firewallKeyword(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	name := ""
+	if len(argv) > 0 {
+		name, argv = argv[0], argv[1:]
+	}
+
+	var (
+		out interface{}
+		err error
+	)
+
+	switch name {
+	case "":
+		out, err = cm.FirewallKeywordFilterRules(ctx)
+	case "add":
+		if len(argv) != 1 {
+			return fmt.Errorf("usage: keyword add <keyword>")
+		}
+
+		out, err = cm.AddKeywordFilterRule(ctx, hitron.KeywordFilterRule{Keyword: argv[0], Enable: true})
+	case "delete", "enable", "disable":
+		if len(argv) != 1 {
+			return fmt.Errorf("usage: keyword %s <keyword|id>", name)
+		}
+
+		rule, kerr := keywordArg(ctx, cm, argv[0])
+		if kerr != nil {
+			return kerr
+		}
+
+		if name == "delete" {
+			out, err = cm.DeleteKeywordFilterRule(ctx, rule.ID)
+		} else {
+			rule.Enable = name == "enable"
+			out, err = cm.UpdateKeywordFilterRule(ctx, rule)
+		}
+	case "all":
+		if len(argv) != 1 || (argv[0] != "on" && argv[0] != "off") {
+			return fmt.Errorf("usage: keyword all on|off")
+		}
+
+		out, err = cm.SetKeywordFilterEnabled(ctx, argv[0] == "on")
+	case "trust":
+		out, err = firewallTrust(ctx, cm, "keyword", argv,
+			func(ctx context.Context) ([]hitron.TrustRule, error) {
+				all, err := cm.FirewallKeywordFilterTrustRules(ctx)
+
+				return all.Rules, err
+			},
+			func(ctx context.Context, rule hitron.TrustRule) error {
+				_, err := cm.AddKeywordFilterTrust(ctx, rule)
+
+				return err
+			},
+			func(ctx context.Context, mac net.HardwareAddr) error {
+				_, err := cm.DeleteKeywordFilterTrust(ctx, mac)
+
+				return err
+			})
+	default:
+		return fmt.Errorf("unknown keyword subcommand: %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// keywordArg finds the keyword filter rule with the given keyword or ID
+func keywordArg(ctx context.Context, cm *hitron.CableModem, arg string) (hitron.KeywordFilterRule, error) {
+	all, err := cm.FirewallKeywordFilterRules(ctx)
+	if err != nil {
+		return hitron.KeywordFilterRule{}, err
+	}
+
+	id, _ := strconv.Atoi(arg)
+
+	for _, rule := range all.Rules {
+		if strings.EqualFold(rule.Keyword, arg) || rule.ID == id {
+			return rule, nil
+		}
+	}
+
+	return hitron.KeywordFilterRule{}, fmt.Errorf("no keyword filter rule for %q", arg)
+}
+
+func firewallService(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	name := ""
+	if len(argv) > 0 {
+		name, argv = argv[0], argv[1:]
+	}
+
+	var (
+		out interface{}
+		err error
+	)
+
+	switch name {
+	case "":
+		out, err = cm.FirewallServiceFilterRules(ctx)
+	case "add":
+		rule := hitron.ServiceFilterRule{Protocol: "BOTH", Schedule: hitron.Always, Enable: true}
+
+		err = serviceFlags(flag.NewFlagSet("add", flag.ExitOnError), &rule, argv)
+		if err != nil {
+			return err
+		}
+
+		out, err = cm.AddServiceFilterRule(ctx, rule)
+	case "update":
+		if len(argv) < 1 {
+			return fmt.Errorf("usage: service update <name|id> [flags]")
+		}
+
+		rule, serr := serviceArg(ctx, cm, argv[0])
+		if serr != nil {
+			return serr
+		}
+
+		err = serviceFlags(flag.NewFlagSet("update", flag.ExitOnError), &rule, argv[1:])
+		if err != nil {
+			return err
+		}
+
+		out, err = cm.UpdateServiceFilterRule(ctx, rule)
+	case "delete", "enable", "disable":
+		if len(argv) != 1 {
+			return fmt.Errorf("usage: service %s <name|id>", name)
+		}
+
+		rule, serr := serviceArg(ctx, cm, argv[0])
+		if serr != nil {
+			return serr
+		}
+
+		if name == "delete" {
+			out, err = cm.DeleteServiceFilterRule(ctx, rule.ID)
+		} else {
+			rule.Enable = name == "enable"
+			out, err = cm.UpdateServiceFilterRule(ctx, rule)
+		}
+	case "all":
+		if len(argv) != 1 || (argv[0] != "on" && argv[0] != "off") {
+			return fmt.Errorf("usage: service all on|off")
+		}
+
+		out, err = cm.SetServiceFilterEnabled(ctx, argv[0] == "on")
+	case "trust":
+		out, err = firewallTrust(ctx, cm, "service", argv,
+			func(ctx context.Context) ([]hitron.TrustRule, error) {
+				all, err := cm.FirewallServiceFilterTrustRules(ctx)
+
+				return all.Rules, err
+			},
+			func(ctx context.Context, rule hitron.TrustRule) error {
+				_, err := cm.AddServiceFilterTrust(ctx, rule)
+
+				return err
+			},
+			func(ctx context.Context, mac net.HardwareAddr) error {
+				_, err := cm.DeleteServiceFilterTrust(ctx, mac)
+
+				return err
+			})
+	default:
+		return fmt.Errorf("unknown service subcommand: %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// serviceArg finds the service filter rule with the given name or ID
+func serviceArg(ctx context.Context, cm *hitron.CableModem, arg string) (hitron.ServiceFilterRule, error) {
+	all, err := cm.FirewallServiceFilterRules(ctx)
+	if err != nil {
+		return hitron.ServiceFilterRule{}, err
+	}
+
+	id, _ := strconv.Atoi(arg)
+
+	for _, rule := range all.Rules {
+		if strings.EqualFold(rule.Name, arg) || rule.ID == id {
+			return rule, nil
+		}
+	}
+
+	return hitron.ServiceFilterRule{}, fmt.Errorf("no service filter rule for %q", arg)
+}
+
+// serviceFlags parses flags into the given rule, leaving fields alone when
+// their flags aren't given
+func serviceFlags(f *flag.FlagSet, rule *hitron.ServiceFilterRule, argv []string) error {
+	var enable *bool
+
+	f.StringVar(&rule.Name, "name", rule.Name, "rule `name`")
+	f.StringVar(&rule.Protocol, "protocol", rule.Protocol, "`protocol` (TCP, UDP, or BOTH)")
+	f.Func("ports", "destination `ports` (e.g. 6667 or 6660-6669)", func(s string) (err error) {
+		rule.Ports, err = hitron.ParsePortRange(s)
+
+		return err
+	})
+
+	days := f.String("days", "", "`days` to block the service on (e.g. Mon-Fri or Sat,Sun - every day if not given)")
+	window := f.String("time", "", "time `window` to block the service during (e.g. 09:00-17:00, not spanning midnight - all day if not given)")
+
+	boolFlag(f, &enable, "enable", "enable the rule")
+
+	if err := f.Parse(argv); err != nil {
+		return err
+	}
+
+	if *days != "" || *window != "" {
+		sched, err := hitron.ParseSchedule(*days, *window)
+		if err != nil {
+			return err
+		}
+
+		rule.Schedule = sched
+	}
+
+	if enable != nil {
+		rule.Enable = *enable
+	}
+
+	return nil
+}
+
+// trustedDevice is a device exempt from a filter, along with its current
+// state in the hosts list
+type trustedDevice struct {
+	ID      int
+	Name    string
+	MacAddr net.HardwareAddr
+	IP      net.IP
+	Online  bool
+}
+
+// firewallTrust lists, adds, or deletes the devices exempt from a filter.
+// Devices can be given by MAC address or by name in the hosts list.
+func firewallTrust(ctx context.Context, cm *hitron.CableModem, filter string, argv []string,
+	list func(context.Context) ([]hitron.TrustRule, error),
+	add func(context.Context, hitron.TrustRule) error,
+	del func(context.Context, net.HardwareAddr) error,
+) ([]trustedDevice, error) {
+	hosts, err := cm.Hosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(argv) > 0 {
+		if len(argv) != 2 || (argv[0] != "add" && argv[0] != "delete") {
+			return nil, fmt.Errorf("usage: %s trust [add|delete <mac|name>]", filter)
+		}
+
+		mac, err := trustArg(ctx, hosts, list, argv[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if argv[0] == "add" {
+			name := argv[1]
+			if h, ok := hosts.Find(mac); ok && h.Name != "" {
+				name = h.Name
+			}
+
+			err = add(ctx, hitron.TrustRule{Name: name, MacAddr: mac})
+		} else {
+			err = del(ctx, mac)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rules, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]trustedDevice, len(rules))
+	for i, r := range rules {
+		out[i] = trustedDevice{ID: r.ID, Name: r.Name, MacAddr: r.MacAddr}
+
+		if h, ok := hosts.Find(r.MacAddr); ok {
+			out[i].IP = h.IP
+			out[i].Online = true
+		}
+	}
+
+	return out, nil
+}
+
+// trustArg resolves a MAC address or host name to a MAC address. Trusted
+// devices which are offline can still be found by the name in their rule.
+func trustArg(ctx context.Context, hosts hitron.Hosts, list func(context.Context) ([]hitron.TrustRule, error), arg string) (net.HardwareAddr, error) {
+	host, err := hosts.Lookup(arg)
+	if err == nil {
+		return host.MacAddr, nil
+	}
+
+	rules, rerr := list(ctx)
+	if rerr != nil {
+		return nil, rerr
+	}
+
+	for _, r := range rules {
+		if strings.EqualFold(r.Name, arg) {
+			return r.MacAddr, nil
+		}
+	}
+
+	return nil, err
+}
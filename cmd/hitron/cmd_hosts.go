@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func cmdHosts(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"block": {
+			usage: "block <mac|name> [-days Mon-Fri] [-time 21:00-23:59]",
+			help:  "Block a device from reaching the Internet, at all times or on a schedule",
+			fn: func(ctx context.Context, argv []string) error {
+				return hostsBlock(ctx, cm, format, flag.NewFlagSet("block", flag.ExitOnError), argv)
+			},
+		},
+		"unblock": {
+			usage: "unblock <mac|name>",
+			help:  "Remove a device's block",
+			fn: func(ctx context.Context, argv []string) error {
+				return hostsUnblock(ctx, cm, format, argv)
+			},
+		},
+	}
+
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func hostsBlock(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	if len(argv) < 1 {
+		return fmt.Errorf("usage: block <mac|name> [flags]")
+	}
+
+	days := f.String("days", "", "`days` to block the device on (e.g. Mon-Fri or Sat,Sun - every day if not given)")
+	window := f.String("time", "", "time `window` to block the device during (e.g. 21:00-23:59, not spanning midnight - all day if not given)")
+
+	_ = f.Parse(argv[1:])
+
+	sched, err := hitron.ParseSchedule(*days, *window)
+	if err != nil {
+		return err
+	}
+
+	host, err := hostArg(ctx, cm, argv[0])
+	if err != nil {
+		return err
+	}
+
+	out, err := cm.BlockDevice(ctx, host.MacAddr, host.Name, sched)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+func hostsUnblock(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	if len(argv) != 1 {
+		return fmt.Errorf("usage: unblock <mac|name>")
+	}
+
+	host, err := hostArg(ctx, cm, argv[0])
+	if err != nil {
+		// blocked devices which are offline can still be found by the name in
+		// their rule
+		rules, rerr := cm.FirewallDeviceFilterRules(ctx)
+		if rerr != nil {
+			return rerr
+		}
+
+		rule, ok := findFilterRule(rules, argv[0])
+		if !ok {
+			return err
+		}
+
+		host.MacAddr = rule.MacAddr
+	}
+
+	out, err := cm.UnblockDevice(ctx, host.MacAddr)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// hostArg resolves a MAC address or host name to a host
+func hostArg(ctx context.Context, cm *hitron.CableModem, arg string) (hitron.Host, error) {
+	hosts, err := cm.Hosts(ctx)
+	if err != nil {
+		return hitron.Host{}, err
+	}
+
+	return hosts.Lookup(arg)
+}
+
+func findFilterRule(rules hitron.FirewallDeviceFilterRules, name string) (hitron.DeviceFilterRule, bool) {
+	for _, r := range rules.Rules {
+		if strings.EqualFold(r.Name, name) {
+			return r, true
+		}
+	}
+
+	return hitron.DeviceFilterRule{}, false
+}
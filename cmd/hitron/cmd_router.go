@@ -4,56 +4,279 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
 
 	hitron "github.com/hairyhenderson/hitron_coda"
 )
 
-func cmdRouter(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
-	f.Usage = func() {
-		f.PrintDefaults()
-		fmt.Fprintf(f.Output(), `
-subcommands:
-	capability
-		Print cable modem capabilities
-	location
-		Print cable modem location
-	sysInfo
-		Print cable modem router system information
-`)
+func cmdRouter(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"backup": {
+			usage: "backup -o <file>",
+			help:  `Save a backup of the router configuration to a file ("-" for stdout)`,
+			fn: func(ctx context.Context, argv []string) error {
+				return routerBackup(ctx, cm, flag.NewFlagSet("backup", flag.ExitOnError), argv)
+			},
+		},
+		"dmz": {
+			usage: "dmz [set <host>|disable]",
+			help:  "Print the DMZ settings, or expose a LAN host to all incoming traffic",
+			fn: func(ctx context.Context, argv []string) error {
+				return routerDMZ(ctx, cm, format, argv)
+			},
+		},
+		"trigger": {
+			usage: "trigger [add|update|delete|enable|disable|all] ...",
+			help:  "Print or change the port triggering rules, or turn port triggering on or off",
+			fn: func(ctx context.Context, argv []string) error {
+				return routerTrigger(ctx, cm, format, argv)
+			},
+		},
+		"restore": {
+			usage: "restore -i <file>",
+			help:  "Restore the router configuration from a backup file, and reboot",
+			fn: func(ctx context.Context, argv []string) error {
+				return routerRestore(ctx, cm, flag.NewFlagSet("restore", flag.ExitOnError), argv)
+			},
+		},
 	}
 
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func routerBackup(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
+	out := f.String("o", "", "`file` to write the backup to (\"-\" for stdout)")
 	_ = f.Parse(argv)
 
-	args := f.Args()
-	if len(args) == 0 {
+	if *out == "" {
 		f.Usage()
 
-		return nil
+		return fmt.Errorf("no output file given")
 	}
 
-	cmds := map[string]func(ctx context.Context) (fmt.Stringer, error){
-		"capability": func(ctx context.Context) (fmt.Stringer, error) { return cm.RouterCapability(ctx) },
-		"location":   func(ctx context.Context) (fmt.Stringer, error) { return cm.RouterLocation(ctx) },
-		"sysInfo":    func(ctx context.Context) (fmt.Stringer, error) { return cm.RouterSysInfo(ctx) },
+	if *out == "-" {
+		return cm.RouterBackup(ctx, os.Stdout)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
 	}
 
-	c, ok := cmds[args[0]]
-	if !ok {
-		return fmt.Errorf("unknown subcommand: %s", args[0])
+	err = cm.RouterBackup(ctx, file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
 	}
 
-	if err := cm.Login(ctx); err != nil {
+	if err != nil {
+		// don't leave a partial backup behind
+		_ = os.Remove(*out)
+
 		return err
 	}
 
-	defer func() { _ = cm.Logout(ctx) }()
+	fmt.Fprintf(os.Stderr, "Saved backup to %s\n", *out)
 
-	if c != nil {
-		out, err := c(ctx)
-		fmt.Printf("%s", out)
+	return nil
+}
+
+func routerRestore(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
+	in := f.String("i", "", "backup `file` to restore from (\"-\" for stdin)")
+	_ = f.Parse(argv)
 
+	if *in == "" {
+		f.Usage()
+
+		return fmt.Errorf("no input file given")
+	}
+
+	var r io.Reader = os.Stdin
+
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	err := cm.RouterRestore(ctx, r)
+	if err != nil {
 		return err
 	}
 
+	fmt.Fprintln(os.Stderr, "Restored backup - the modem will now reboot")
+
+	return nil
+}
+
+func routerDMZ(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	var (
+		out hitron.RouterDMZ
+		err error
+	)
+
+	switch {
+	case len(argv) == 0:
+		out, err = cm.RouterDMZ(ctx)
+	case argv[0] == "set" && len(argv) == 2:
+		host := net.ParseIP(argv[1])
+		if host == nil {
+			return fmt.Errorf("invalid host address %q", argv[1])
+		}
+
+		var warnings []string
+
+		out, warnings, err = cm.SetDMZ(ctx, host)
+
+		for _, w := range warnings {
+			slog.WarnContext(ctx, w)
+		}
+	case argv[0] == "disable" && len(argv) == 1:
+		out, err = cm.DisableDMZ(ctx)
+	default:
+		return fmt.Errorf("usage: dmz [set <host>|disable]")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+func routerTrigger(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	name := ""
+	if len(argv) > 0 {
+		name, argv = argv[0], argv[1:]
+	}
+
+	var (
+		out interface{}
+		err error
+	)
+
+	switch name {
+	case "":
+		out, err = cm.RouterPortTriggerall(ctx)
+	case "add":
+		rule := hitron.PortTriggerRule{Protocol: "BOTH", Timeout: 5 * time.Minute, Enable: true}
+
+		err = triggerFlags(flag.NewFlagSet("add", flag.ExitOnError), &rule, argv)
+		if err != nil {
+			return err
+		}
+
+		out, err = cm.AddPortTriggerRule(ctx, rule)
+	case "update":
+		rule, rest, terr := triggerArg(ctx, cm, argv)
+		if terr != nil {
+			return terr
+		}
+
+		err = triggerFlags(flag.NewFlagSet("update", flag.ExitOnError), &rule, rest)
+		if err != nil {
+			return err
+		}
+
+		out, err = cm.UpdatePortTriggerRule(ctx, rule)
+	case "delete", "enable", "disable":
+		if len(argv) != 1 {
+			return fmt.Errorf("usage: trigger %s <id>", name)
+		}
+
+		id, perr := strconv.Atoi(argv[0])
+		if perr != nil {
+			return fmt.Errorf("invalid rule ID %q", argv[0])
+		}
+
+		if name == "delete" {
+			out, err = cm.DeletePortTriggerRule(ctx, id)
+		} else {
+			out, err = cm.SetPortTriggerEnabled(ctx, id, name == "enable")
+		}
+	case "all":
+		if len(argv) != 1 || (argv[0] != "on" && argv[0] != "off") {
+			return fmt.Errorf("usage: trigger all on|off")
+		}
+
+		out, err = cm.SetAllPortTriggersEnabled(ctx, argv[0] == "on")
+	default:
+		return fmt.Errorf("unknown trigger subcommand: %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// triggerArg finds the port triggering rule with the ID given as the first
+// argument, and returns it with the remaining arguments
+func triggerArg(ctx context.Context, cm *hitron.CableModem, argv []string) (hitron.PortTriggerRule, []string, error) {
+	if len(argv) < 1 {
+		return hitron.PortTriggerRule{}, nil, fmt.Errorf("usage: trigger update <id> [flags]")
+	}
+
+	id, err := strconv.Atoi(argv[0])
+	if err != nil {
+		return hitron.PortTriggerRule{}, nil, fmt.Errorf("invalid rule ID %q", argv[0])
+	}
+
+	all, err := cm.RouterPortTriggerall(ctx)
+	if err != nil {
+		return hitron.PortTriggerRule{}, nil, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID == id {
+			return rule, argv[1:], nil
+		}
+	}
+
+	return hitron.PortTriggerRule{}, nil, fmt.Errorf("no port triggering rule with ID %d", id)
+}
+
+// triggerFlags parses flags into the given rule, leaving fields alone when
+// their flags aren't given
+func triggerFlags(f *flag.FlagSet, rule *hitron.PortTriggerRule, argv []string) error {
+	var twoWay, enable *bool
+
+	f.StringVar(&rule.AppName, "name", rule.AppName, "application `name`")
+	f.StringVar(&rule.Protocol, "protocol", rule.Protocol, "`protocol` (TCP, UDP, or BOTH)")
+	f.Func("trigger", "outgoing `ports` which trigger the rule (e.g. 6000 or 6000-6010)", func(s string) (err error) {
+		rule.TriggerPorts, err = hitron.ParsePortRange(s)
+
+		return err
+	})
+	f.Func("target", "incoming `ports` to open when triggered", func(s string) (err error) {
+		rule.TargetPorts, err = hitron.ParsePortRange(s)
+
+		return err
+	})
+	f.DurationVar(&rule.Timeout, "timeout", rule.Timeout, "how long the target ports stay open without traffic")
+	boolFlag(f, &twoWay, "twoway", "allow connections in both directions")
+	boolFlag(f, &enable, "enable", "enable the rule")
+
+	if err := f.Parse(argv); err != nil {
+		return err
+	}
+
+	if twoWay != nil {
+		rule.TwoWay = *twoWay
+	}
+
+	if enable != nil {
+		rule.Enable = *enable
+	}
+
 	return nil
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+func cmdTime(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"set": {
+			usage: "set [-zone name] [-sntp host] [-enable on|off] [-dst on|off]",
+			help:  "Change the time zone, SNTP server, and daylight saving settings",
+			fn: func(ctx context.Context, argv []string) error {
+				return timeSet(ctx, cm, format, flag.NewFlagSet("set", flag.ExitOnError), argv)
+			},
+		},
+	}
+
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func timeSet(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	changes := hitron.TimeChanges{}
+
+	f.Func("zone", "IANA time zone `name` (e.g. America/Toronto)", func(s string) error {
+		loc, err := time.LoadLocation(s)
+		changes.TZ = loc
+
+		return err
+	})
+	stringFlag(f, &changes.SNTPServer, "sntp", "SNTP server `host`")
+	boolFlag(f, &changes.Enable, "enable", "set the time with SNTP")
+	boolFlag(f, &changes.Daylight, "dst", "adjust for daylight saving time")
+
+	_ = f.Parse(argv)
+
+	if f.NFlag() == 0 {
+		f.Usage()
+
+		return fmt.Errorf("no changes given")
+	}
+
+	out, err := cm.UpdateTime(ctx, changes)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"github.com/hairyhenderson/hitron_coda/internal/qrcode"
+)
+
+func cmdWiFi(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	actions := map[string]action{
+		"guest": {
+			usage: "guest [enable|disable|rotate|set|qr] ...",
+			help:  "Print or change the guest network settings, or print a QR code to join it",
+			fn: func(ctx context.Context, argv []string) error {
+				return wifiGuest(ctx, cm, format, argv)
+			},
+		},
+		"acl": {
+			usage: "acl [add|remove|mode|import|export] ...",
+			help:  "Print or change the WiFi access control list, or import/export it as CSV",
+			fn: func(ctx context.Context, argv []string) error {
+				return wifiACL(ctx, cm, format, argv)
+			},
+		},
+		"wps": {
+			usage: "wps [start [-pin <pin>] [-timeout <duration>]]",
+			help:  "Print the WPS status, or start a WPS session and wait for a device to join",
+			fn: func(ctx context.Context, argv []string) error {
+				return wifiWPS(ctx, cm, format, argv)
+			},
+		},
+		"radio": {
+			usage: "radio <n> [-channel n] [-auto on|off] [-bandwidth bw] [-mode m] ...",
+			help:  "Print or change the settings of a radio",
+			fn: func(ctx context.Context, argv []string) error {
+				return wifiRadio(ctx, cm, format, flag.NewFlagSet("radio", flag.ExitOnError), argv)
+			},
+		},
+		"advanced": {
+			usage: "advanced <n> [-bandsteering on|off] [-coexistence on|off] ...",
+			help:  "Print or change the advanced settings of a radio",
+			fn: func(ctx context.Context, argv []string) error {
+				return wifiRadioAdvanced(ctx, cm, format, flag.NewFlagSet("advanced", flag.ExitOnError), argv)
+			},
+		},
+	}
+
+	return cmdGroup(ctx, cm, format, f, actions, argv)
+}
+
+func wifiGuest(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	name := ""
+	if len(argv) > 0 {
+		name, argv = argv[0], argv[1:]
+	}
+
+	var (
+		out hitron.WiFiGuestSSID
+		err error
+	)
+
+	switch name {
+	case "":
+		out, err = cm.WiFiGuestSSID(ctx)
+	case "enable":
+		out, err = cm.SetGuestEnabled(ctx, true)
+	case "disable":
+		out, err = cm.SetGuestEnabled(ctx, false)
+	case "rotate":
+		out, err = cm.RotateGuestPassword(ctx)
+	case "set":
+		out, err = wifiGuestSet(ctx, cm, flag.NewFlagSet("set", flag.ExitOnError), argv)
+	case "qr":
+		return wifiGuestQR(ctx, cm, flag.NewFlagSet("qr", flag.ExitOnError), argv)
+	default:
+		return fmt.Errorf("unknown guest subcommand: %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+func wifiGuestSet(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) (hitron.WiFiGuestSSID, error) {
+	changes := hitron.GuestSSIDChanges{}
+
+	stringFlag(f, &changes.SSID, "ssid", "guest network `name` (2.4GHz)")
+	stringFlag(f, &changes.SSID5G, "ssid5g", "guest network `name` (5GHz)")
+	stringFlag(f, &changes.Password, "password", "guest network `passphrase`")
+	intFlag(f, &changes.MaxUsers, "max-users", "maximum number of guest `users`")
+	_ = f.Parse(argv)
+
+	return cm.UpdateGuestSSID(ctx, changes)
+}
+
+func wifiGuestQR(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
+	pngFile := f.String("png", "", "write the QR code to a PNG `file`, instead of the terminal")
+	use5G := f.Bool("5g", false, "use the 5GHz guest network name")
+	scale := f.Int("scale", 8, "size of each QR code module in the PNG, in `pixels`")
+	_ = f.Parse(argv)
+
+	guest, err := cm.WiFiGuestSSID(ctx)
+	if err != nil {
+		return err
+	}
+
+	ssid := guest.SSID
+	if *use5G {
+		ssid = guest.SSID5G
+	}
+
+	code, err := qrcode.Encode([]byte(hitron.WiFiQRPayload(ssid, guest.Password)))
+	if err != nil {
+		return err
+	}
+
+	if *pngFile == "" {
+		fmt.Print(code.Terminal())
+
+		return nil
+	}
+
+	file, err := os.Create(*pngFile)
+	if err != nil {
+		return err
+	}
+
+	err = code.PNG(file, *scale)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Saved QR code for %q to %s\n", ssid, *pngFile)
+
+	return nil
+}
+
+func wifiACL(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	name := ""
+	if len(argv) > 0 {
+		name, argv = argv[0], argv[1:]
+	}
+
+	var (
+		out interface{}
+		err error
+	)
+
+	switch name {
+	case "":
+		out, err = cm.WiFiAccessControl(ctx)
+	case "add":
+		if len(argv) < 1 || len(argv) > 2 {
+			return fmt.Errorf("usage: acl add <mac> [hostname]")
+		}
+
+		rule := hitron.WiFiAccessControlRule{}
+
+		rule.MACAddr, err = net.ParseMAC(argv[0])
+		if err != nil {
+			return err
+		}
+
+		if len(argv) == 2 {
+			rule.Hostname = argv[1]
+		}
+
+		out, err = cm.AddAccessControlRule(ctx, rule)
+	case "remove":
+		if len(argv) != 1 {
+			return fmt.Errorf("usage: acl remove <mac>")
+		}
+
+		mac, perr := net.ParseMAC(argv[0])
+		if perr != nil {
+			return perr
+		}
+
+		out, err = cm.DeleteAccessControlRule(ctx, mac)
+	case "mode":
+		modes := map[string]string{
+			"allow":    hitron.AccessControlAllow,
+			"block":    hitron.AccessControlBlock,
+			"disabled": hitron.AccessControlDisabled,
+		}
+
+		mode, ok := "", false
+		if len(argv) == 1 {
+			mode, ok = modes[argv[0]]
+		}
+
+		if !ok {
+			return fmt.Errorf("usage: acl mode allow|block|disabled")
+		}
+
+		out, err = cm.SetAccessControlMode(ctx, mode)
+	case "import":
+		out, err = aclImport(ctx, cm, flag.NewFlagSet("import", flag.ExitOnError), argv)
+	case "export":
+		return aclExport(ctx, cm, flag.NewFlagSet("export", flag.ExitOnError), argv)
+	default:
+		return fmt.Errorf("unknown acl subcommand: %s", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// aclImport replaces the access control list with the rules in a CSV file,
+// with "mac" and "hostname" columns (as written by aclExport)
+func aclImport(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) (hitron.WiFiAccessControl, error) {
+	in := f.String("i", "-", "CSV `file` to read (\"-\" for stdin)")
+	_ = f.Parse(argv)
+
+	var r io.Reader = os.Stdin
+
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return hitron.WiFiAccessControl{}, err
+		}
+
+		defer file.Close()
+
+		r = file
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return hitron.WiFiAccessControl{}, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	rules := make([]hitron.WiFiAccessControlRule, 0, len(records))
+
+	for i, rec := range records {
+		if len(rec) != 2 {
+			return hitron.WiFiAccessControl{}, fmt.Errorf("line %d: expected 2 columns (mac,hostname), got %d", i+1, len(rec))
+		}
+
+		// skip the header
+		if i == 0 && rec[0] == "mac" {
+			continue
+		}
+
+		mac, err := net.ParseMAC(rec[0])
+		if err != nil {
+			return hitron.WiFiAccessControl{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		rules = append(rules, hitron.WiFiAccessControlRule{MACAddr: mac, Hostname: rec[1]})
+	}
+
+	return cm.ReplaceAccessControlRules(ctx, rules)
+}
+
+// aclExport writes the access control list as CSV, sorted by MAC address so
+// that the output is stable
+func aclExport(ctx context.Context, cm *hitron.CableModem, f *flag.FlagSet, argv []string) error {
+	out := f.String("o", "-", "CSV `file` to write (\"-\" for stdout)")
+	_ = f.Parse(argv)
+
+	all, err := cm.WiFiAccessControl(ctx)
+	if err != nil {
+		return err
+	}
+
+	records := make([][]string, 0, len(all.RulesList))
+	for _, r := range all.RulesList {
+		records = append(records, []string{r.MACAddr.String(), r.Hostname})
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i][0] < records[j][0] })
+
+	buf := &bytes.Buffer{}
+
+	cw := csv.NewWriter(buf)
+	_ = cw.Write([]string{"mac", "hostname"})
+
+	if err = cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	if *out == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+
+		return err
+	}
+
+	return os.WriteFile(*out, buf.Bytes(), 0o600)
+}
+
+func wifiWPS(ctx context.Context, cm *hitron.CableModem, format OutputFormat, argv []string) error {
+	if len(argv) == 0 {
+		out, err := cm.WiFiWPS(ctx)
+		if err != nil {
+			return err
+		}
+
+		return render(os.Stdout, format, out)
+	}
+
+	if argv[0] != "start" {
+		return fmt.Errorf("unknown wps subcommand: %s", argv[0])
+	}
+
+	f := flag.NewFlagSet("start", flag.ExitOnError)
+	pin := f.String("pin", "", "the device's WPS `PIN` (otherwise push-button mode is used)")
+	timeout := f.Duration("timeout", hitron.WPSWalkTime, "how long to wait for the device to join")
+	_ = f.Parse(argv[1:])
+
+	method := hitron.WPSMethodPushButton
+	if *pin != "" {
+		method = hitron.WPSMethodPIN
+	}
+
+	if _, err := cm.StartWPS(ctx, method, *pin); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Started WPS (%s), waiting for a device to join...\n", method)
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	result, err := cm.WaitWPS(ctx, func(w hitron.WiFiWPS) {
+		fmt.Fprintf(os.Stderr, "\r[%4s] %-40s", w.TimeElapsed, w.Status)
+	})
+
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return err
+	}
+
+	if err = render(os.Stdout, format, result); err != nil {
+		return err
+	}
+
+	if result.Outcome != hitron.WPSSuccess {
+		return fmt.Errorf("WPS session did not succeed: %s", result.Outcome)
+	}
+
+	return nil
+}
+
+// radioArg parses the radio number from the first argument, leaving the rest
+// to be parsed as flags
+func radioArg(f *flag.FlagSet, argv []string) (int, []string, error) {
+	if len(argv) == 0 {
+		f.Usage()
+
+		return 0, nil, fmt.Errorf("no radio given")
+	}
+
+	radio, err := strconv.Atoi(argv[0])
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid radio %q: %w", argv[0], err)
+	}
+
+	return radio, argv[1:], nil
+}
+
+func wifiRadio(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	changes := hitron.RadioChanges{}
+
+	intFlag(f, &changes.Channel, "channel", "fixed `channel` to use")
+	boolFlag(f, &changes.AutoChannel, "auto", "choose the channel automatically")
+	stringFlag(f, &changes.ChanBandwidth, "bandwidth", "channel `bandwidth` (20MHZ, 20/40MHZ, 40MHZ, or 80MHZ)")
+	f.Func("mode", "WiFi `mode` (e.g. b/g/n, or a/n/ac)", func(s string) error {
+		m, err := hitron.ParseWiFiMode(s)
+		changes.Mode = &m
+
+		return err
+	})
+	boolFlag(f, &changes.EnableDFS, "dfs", "use channels shared with radar (DFS)")
+	boolFlag(f, &changes.EnableDCS, "dcs", "change channels when there's interference (DCS)")
+	boolFlag(f, &changes.Enable, "enable", "enable the radio")
+
+	radio, argv, err := radioArg(f, argv)
+	if err != nil {
+		return err
+	}
+
+	_ = f.Parse(argv)
+
+	var out hitron.WiFiRadio
+	if f.NFlag() == 0 {
+		out, err = cm.WiFiRadioDetails(ctx, radio)
+	} else {
+		out, err = cm.UpdateRadio(ctx, radio, changes)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+func wifiRadioAdvanced(ctx context.Context, cm *hitron.CableModem, format OutputFormat, f *flag.FlagSet, argv []string) error {
+	changes := hitron.AdvancedRadioChanges{}
+
+	boolFlag(f, &changes.BandSteering, "bandsteering", "steer dual-band clients to the best band")
+	boolFlag(f, &changes.NCoexistence, "coexistence", "fall back to 20MHz when neighbouring networks overlap (20/40 coexistence)")
+	stringFlag(f, &changes.NGuardInterval, "guard-interval", "guard `interval` (Long, Short, or Auto)")
+	intFlag(f, &changes.NMCS, "mcs", "MCS `index`")
+	boolFlag(f, &changes.NRDG, "rdg", "enable reverse direction grant (RDG)")
+	boolFlag(f, &changes.Namsdu, "amsdu", "enable A-MSDU aggregation")
+	boolFlag(f, &changes.Nautoba, "autoba", "enable automatic block ACK")
+	boolFlag(f, &changes.Nbadecline, "badecline", "decline block ACK requests")
+	stringFlag(f, &changes.TxStream, "tx-stream", "number of transmit `streams`")
+	stringFlag(f, &changes.RxStream, "rx-stream", "number of receive `streams`")
+
+	radio, argv, err := radioArg(f, argv)
+	if err != nil {
+		return err
+	}
+
+	_ = f.Parse(argv)
+
+	var out hitron.WiFiRadioAdvanced
+	if f.NFlag() == 0 {
+		out, err = cm.WiFiRadioAdvancedDetails(ctx, radio)
+	} else {
+		out, err = cm.UpdateRadioAdvanced(ctx, radio, changes)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+// stringFlag defines a flag which sets *p only when it's given
+func stringFlag(f *flag.FlagSet, p **string, name, usage string) {
+	f.Func(name, usage, func(s string) error {
+		*p = &s
+
+		return nil
+	})
+}
+
+// intFlag defines a flag which sets *p only when it's given
+func intFlag(f *flag.FlagSet, p **int, name, usage string) {
+	f.Func(name, usage, func(s string) error {
+		n, err := strconv.Atoi(s)
+		*p = &n
+
+		return err
+	})
+}
+
+// boolFlag defines an on/off flag which sets *p only when it's given
+func boolFlag(f *flag.FlagSet, p **bool, name, usage string) {
+	f.Func(name, usage+" (`on|off`)", func(s string) error {
+		var b bool
+
+		switch strings.ToLower(s) {
+		case "on", "yes":
+			b = true
+		case "off", "no":
+		default:
+			var err error
+
+			b, err = strconv.ParseBool(s)
+			if err != nil {
+				return fmt.Errorf("expected on or off, not %q", s)
+			}
+		}
+
+		*p = &b
+
+		return nil
+	})
+}
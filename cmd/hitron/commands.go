@@ -0,0 +1,191 @@
+// File generated with 'go generate'. Do not edit!
+
+package main
+
+import (
+	"context"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+// getters returns a command for each GET endpoint listed in apilist.yaml
+func getters(cm *hitron.CableModem) []getter {
+	return []getter{
+		{
+			group: "cm", name: "docsisProvision", path: "/CM/DocsisProvision",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMDocsisProvision(ctx) },
+		},
+		{
+			group: "cm", name: "dsInfo", path: "/CM/DsInfo",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMDsInfo(ctx) },
+		},
+		{
+			group: "cm", name: "dsOfdm", path: "/CM/DsOfdm",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMDsOfdm(ctx) },
+		},
+		{
+			group: "cm", name: "log", path: "/CM/Log",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMLog(ctx) },
+		},
+		{
+			group: "cm", name: "sysInfo", path: "/CM/SysInfo",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMSysInfo(ctx) },
+		},
+		{
+			group: "cm", name: "usInfo", path: "/CM/UsInfo",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMUsInfo(ctx) },
+		},
+		{
+			group: "cm", name: "usOfdm", path: "/CM/UsOfdm",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMUsOfdm(ctx) },
+		},
+		{
+			group: "cm", name: "version", path: "/CM/Version",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.CMVersion(ctx) },
+		},
+		{
+			group: "ddns", name: "", path: "/DDNS",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.DDNS(ctx) },
+		},
+		{
+			group: "dhcp", name: "reservations", path: "/DHCP/Reservation",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.DHCPReservation(ctx) },
+		},
+		{
+			group: "dns", name: "", path: "/DNS",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.DNS(ctx) },
+		},
+		{
+			group: "firewall", name: "deviceFilterRules", path: "/Firewall/DeviceFilter/Rules",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallDeviceFilterRules(ctx) },
+		},
+		{
+			group: "firewall", name: "deviceFilterType", path: "/Firewall/DeviceFilter/Type",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallDeviceFilterType(ctx) },
+		},
+		{
+			group: "firewall", name: "inboundRules", path: "/Firewall/Inbound/Rules",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallInboundRules(ctx) },
+		},
+		{
+			group: "firewall", name: "inboundStatus", path: "/Firewall/Inbound/Status",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallInboundStatus(ctx) },
+		},
+		{
+			group: "firewall", name: "keywordFilterRules", path: "/Firewall/KeywordFilter/Rules",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallKeywordFilterRules(ctx) },
+		},
+		{
+			group: "firewall", name: "keywordFilterStatus", path: "/Firewall/KeywordFilter/Status",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallKeywordFilterStatus(ctx) },
+		},
+		{
+			group: "firewall", name: "keywordFilterTrustRules", path: "/Firewall/KeywordFilter/TrustRules",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallKeywordFilterTrustRules(ctx) },
+		},
+		{
+			group: "firewall", name: "level", path: "/Firewall/Level",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallLevel(ctx) },
+		},
+		{
+			group: "firewall", name: "serviceFilterRules", path: "/Firewall/ServiceFilter/Rules",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallServiceFilterRules(ctx) },
+		},
+		{
+			group: "firewall", name: "serviceFilterStatus", path: "/Firewall/ServiceFilter/Status",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallServiceFilterStatus(ctx) },
+		},
+		{
+			group: "firewall", name: "serviceFilterTrustRules", path: "/Firewall/ServiceFilter/TrustRules",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallServiceFilterTrustRules(ctx) },
+		},
+		{
+			group: "firewall", name: "vpn", path: "/Firewall/VPN",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.FirewallVPN(ctx) },
+		},
+		{
+			group: "hosts", name: "", path: "/Hosts",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.Hosts(ctx) },
+		},
+		{
+			group: "router", name: "capability", path: "/Router/Capability",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterCapability(ctx) },
+		},
+		{
+			group: "router", name: "dmz", path: "/Router/DMZ",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterDMZ(ctx) },
+		},
+		{
+			group: "router", name: "location", path: "/Router/Location",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterLocation(ctx) },
+		},
+		{
+			group: "router", name: "portForwardStatus", path: "/Router/PortForward/Status",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterPortForwardStatus(ctx) },
+		},
+		{
+			group: "router", name: "portForwards", path: "/Router/PortForward/all",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterPortForwardall(ctx) },
+		},
+		{
+			group: "router", name: "portTriggerStatus", path: "/Router/PortTrigger/Status",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterPortTriggerStatus(ctx) },
+		},
+		{
+			group: "router", name: "portTriggers", path: "/Router/PortTrigger/all",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterPortTriggerall(ctx) },
+		},
+		{
+			group: "router", name: "sysInfo", path: "/Router/SysInfo",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterSysInfo(ctx) },
+		},
+		{
+			group: "router", name: "tr069", path: "/Router/TR069",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.RouterTR069(ctx) },
+		},
+		{
+			group: "time", name: "", path: "/Time",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.Time(ctx) },
+		},
+		{
+			group: "users", name: "csrf", path: "/Users/CSRF",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.UsersCSRF(ctx) },
+		},
+		{
+			group: "wifi", name: "accessControl", path: "/WiFi/AccessControl",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiAccessControl(ctx) },
+		},
+		{
+			group: "wifi", name: "accessControlStatus", path: "/WiFi/AccessControl/Status",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiAccessControlStatus(ctx) },
+		},
+		{
+			group: "wifi", name: "clients", path: "/WiFi/Client",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiClient(ctx) },
+		},
+		{
+			group: "wifi", name: "guest", path: "/WiFi/GuestSSID",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiGuestSSID(ctx) },
+		},
+		{
+			group: "wifi", name: "radios", path: "/WiFi/Radios",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiRadios(ctx) },
+		},
+		{
+			group: "wifi", name: "radiosAdvanced", path: "/WiFi/Radios/Advanced",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiRadiosAdvanced(ctx) },
+		},
+		{
+			group: "wifi", name: "survey", path: "/WiFi/Radios/Survey",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiRadiosSurvey(ctx) },
+		},
+		{
+			group: "wifi", name: "ssids", path: "/WiFi/SSIDs",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiSSIDs(ctx) },
+		},
+		{
+			group: "wifi", name: "wps", path: "/WiFi/WPS",
+			fn: func(ctx context.Context) (interface{}, error) { return cm.WiFiWPS(ctx) },
+		},
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 
 	hitron "github.com/hairyhenderson/hitron_coda"
@@ -16,6 +18,7 @@ type opts struct {
 	username string
 	password string
 	logLevel LevelValue
+	output   OutputFormat
 }
 
 func flags(args []string, o *opts) ([]string, error) {
@@ -25,11 +28,13 @@ func flags(args []string, o *opts) ([]string, error) {
 
 	// default to info
 	_ = o.logLevel.Set("info")
+	o.output = formatText
 
 	fs.StringVar(&o.host, "host", "192.168.0.1", "hostname or IP address of the cable modem")
 	fs.StringVar(&o.username, "username", "cusadmin", "username for the cable modem")
 	fs.StringVar(&o.password, "password", os.Getenv("HITRON_CODA_PASSWORD"), "password for the cable modem")
 	fs.Var(&o.logLevel, "log.level", "log messages with the given severity or above. Valid levels: [debug, info, warn, error]")
+	fs.Var(&o.output, "o", "output format. Valid formats: [text, json, yaml, table]")
 
 	fs.Usage = func() {
 		fmt.Fprintf(fs.Output(), `usage: %s <flags> <command> ...
@@ -42,9 +47,15 @@ func flags(args []string, o *opts) ([]string, error) {
 		Cable Modem subcommands
 		router <flags>
 		Router subcommands
+		exporter <flags>
+		Serve Prometheus metrics
+		apply -f <file> [-dry-run]
+		Apply a desired-state configuration
+		%s <flags>
+		Other modem settings and status
 		
 		Run %s <command> -h for more information
-		`, prog)
+		`, strings.Join(otherGroups(), ", "), prog)
 	}
 
 	err := fs.Parse(args[1:])
@@ -83,17 +94,45 @@ func run(args []string) error {
 
 	initLogger(o.logLevel)
 
-	cm, err := hitron.New(o.host, o.username, o.password)
+	cm, err := hitron.New(o.host, o.username, o.password, hitron.WithAutoRelogin())
 	if err != nil {
 		return err
 	}
 
 	switch fsArgs[0] {
 	case "cm":
-		return cmdCM(ctx, cm, flag.NewFlagSet("cm", flag.ExitOnError), fsArgs[1:])
+		return cmdCM(ctx, cm, o.output, flag.NewFlagSet("cm", flag.ExitOnError), fsArgs[1:])
 	case "router":
-		return cmdRouter(ctx, cm, flag.NewFlagSet("router", flag.ExitOnError), fsArgs[1:])
+		return cmdRouter(ctx, cm, o.output, flag.NewFlagSet("router", flag.ExitOnError), fsArgs[1:])
+	case "wifi":
+		return cmdWiFi(ctx, cm, o.output, flag.NewFlagSet("wifi", flag.ExitOnError), fsArgs[1:])
+	case "ddns":
+		return cmdDDNS(ctx, cm, o.output, flag.NewFlagSet("ddns", flag.ExitOnError), fsArgs[1:])
+	case "dns":
+		return cmdDNS(ctx, cm, o.output, flag.NewFlagSet("dns", flag.ExitOnError), fsArgs[1:])
+	case "time":
+		return cmdTime(ctx, cm, o.output, flag.NewFlagSet("time", flag.ExitOnError), fsArgs[1:])
+	case "firewall":
+		return cmdFirewall(ctx, cm, o.output, flag.NewFlagSet("firewall", flag.ExitOnError), fsArgs[1:])
+	case "hosts":
+		return cmdHosts(ctx, cm, o.output, flag.NewFlagSet("hosts", flag.ExitOnError), fsArgs[1:])
+	case "apply":
+		return cmdApply(ctx, cm, flag.NewFlagSet("apply", flag.ExitOnError), fsArgs[1:])
+	case "exporter":
+		return cmdExporter(ctx, cm, flag.NewFlagSet("exporter", flag.ExitOnError), fsArgs[1:])
 	default:
-		return fmt.Errorf("invalid subcommand %q", fsArgs[0])
+		if !slices.Contains(groups(), fsArgs[0]) {
+			return fmt.Errorf("invalid subcommand %q", fsArgs[0])
+		}
+
+		return cmdGroup(ctx, cm, o.output, flag.NewFlagSet(fsArgs[0], flag.ExitOnError), nil, fsArgs[1:])
 	}
 }
+
+// otherGroups returns the command groups which don't have their own entry in
+// the usage text
+func otherGroups() []string {
+	return slices.DeleteFunc(groups(), func(g string) bool {
+		return g == "cm" || g == "router"
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// metricSet accumulates samples and renders them in the Prometheus text
+// exposition format, with all samples of a metric family grouped together.
+type metricSet struct {
+	families map[string]*metricFamily
+	order    []string
+}
+
+type metricFamily struct {
+	name    string
+	help    string
+	typ     string
+	samples []metricSample
+}
+
+type metricSample struct {
+	labels string
+	value  float64
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{families: map[string]*metricFamily{}}
+}
+
+func (m *metricSet) gauge(name, help string, value float64, labels ...string) {
+	m.add(name, help, "gauge", value, labels...)
+}
+
+func (m *metricSet) counter(name, help string, value float64, labels ...string) {
+	m.add(name, help, "counter", value, labels...)
+}
+
+// add a sample - labels are given as alternating name/value pairs
+func (m *metricSet) add(name, help, typ string, value float64, labels ...string) {
+	f, ok := m.families[name]
+	if !ok {
+		f = &metricFamily{name: name, help: help, typ: typ}
+		m.families[name] = f
+		m.order = append(m.order, name)
+	}
+
+	f.samples = append(f.samples, metricSample{labels: formatLabels(labels), value: value})
+}
+
+func (m *metricSet) WriteTo(w io.Writer) (int64, error) {
+	sb := strings.Builder{}
+
+	for _, name := range m.order {
+		f := m.families[name]
+
+		sb.WriteString("# HELP ")
+		sb.WriteString(f.name)
+		sb.WriteString(" ")
+		sb.WriteString(strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(f.help))
+		sb.WriteString("\n# TYPE ")
+		sb.WriteString(f.name)
+		sb.WriteString(" ")
+		sb.WriteString(f.typ)
+		sb.WriteString("\n")
+
+		for _, s := range f.samples {
+			sb.WriteString(f.name)
+			sb.WriteString(s.labels)
+			sb.WriteString(" ")
+			sb.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+			sb.WriteString("\n")
+		}
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	return int64(n), err
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escape.Replace(labels[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
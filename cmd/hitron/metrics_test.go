@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricSetWriteTo(t *testing.T) {
+	m := newMetricSet()
+	m.gauge("test_temp_celsius", "Temperature, with a \\ and a\nnewline.", 21.5, "room", `the "big" one`, "floor", "1")
+	m.counter("test_requests_total", "Requests served.", 3)
+	m.gauge("test_temp_celsius", "ignored", 19, "room", "back\\room\n", "floor", "2")
+
+	sb := &strings.Builder{}
+	n, err := m.WriteTo(sb)
+	require.NoError(t, err)
+	assert.Equal(t, int64(sb.Len()), n)
+
+	// samples are grouped by family, in the order the families were first
+	// seen, and labels keep the order they were given in
+	assert.Equal(t, `# HELP test_temp_celsius Temperature, with a \\ and a\nnewline.
+# TYPE test_temp_celsius gauge
+test_temp_celsius{room="the \"big\" one",floor="1"} 21.5
+test_temp_celsius{room="back\\room\n",floor="2"} 19
+# HELP test_requests_total Requests served.
+# TYPE test_requests_total counter
+test_requests_total 3
+`, sb.String())
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", formatLabels(nil))
+	assert.Equal(t, "", formatLabels([]string{"dangling"}))
+	assert.Equal(t, `{a="1",b="2"}`, formatLabels([]string{"a", "1", "b", "2", "c"}))
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat - the format command output is rendered in
+type OutputFormat string
+
+const (
+	formatText  OutputFormat = "text"
+	formatJSON  OutputFormat = "json"
+	formatYAML  OutputFormat = "yaml"
+	formatTable OutputFormat = "table"
+)
+
+var _ flag.Value = (*OutputFormat)(nil)
+
+// String implements flag.Value
+func (f OutputFormat) String() string {
+	return string(f)
+}
+
+func (f *OutputFormat) Set(s string) error {
+	switch OutputFormat(s) {
+	case formatText, formatJSON, formatYAML, formatTable:
+		*f = OutputFormat(s)
+	default:
+		return fmt.Errorf("unrecognized output format %q", s)
+	}
+
+	return nil
+}
+
+// render writes v to w in the given format. The text format uses the type's
+// own String method where it has one, and otherwise falls back to the table
+// format.
+func render(w io.Writer, format OutputFormat, v interface{}) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(toValue(reflect.ValueOf(v)))
+	case formatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(toValue(reflect.ValueOf(v))); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	case formatText:
+		if s, ok := ownString(v); ok {
+			_, err := fmt.Fprint(w, s)
+
+			return err
+		}
+	case formatTable:
+	default:
+		return fmt.Errorf("unrecognized output format %q", format)
+	}
+
+	return renderTable(w, toValue(reflect.ValueOf(v)))
+}
+
+// ownString returns the result of v's String method, unless v has none, or
+// only has the one promoted from its embedded Error
+func ownString(v interface{}) (string, bool) {
+	s, ok := v.(fmt.Stringer)
+	if !ok {
+		return "", false
+	}
+
+	out := s.String()
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() == reflect.Struct {
+		if e := rv.FieldByName("Error"); e.IsValid() && e.Type() == errorType {
+			if out == e.Interface().(hitron.Error).String() { //nolint:forcetypeassert
+				return "", false
+			}
+		}
+	}
+
+	return out, true
+}
+
+// field - a named value in an object, in declaration order
+type field struct {
+	value interface{}
+	name  string
+}
+
+// object - an ordered set of fields, so that output is stable
+type object []field
+
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(f.name) //nolint:errchkjson
+
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (o object) MarshalYAML() (interface{}, error) {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, f := range o {
+		v := &yaml.Node{}
+		if err := v.Encode(f.value); err != nil {
+			return nil, err
+		}
+
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.name}, v)
+	}
+
+	return n, nil
+}
+
+//nolint:gochecknoglobals
+var (
+	errorType    = reflect.TypeOf(hitron.Error{})
+	timeType     = reflect.TypeOf(time.Time{})
+	ipMaskType   = reflect.TypeOf(net.IPMask{})
+	rawJSONType  = reflect.TypeOf(json.RawMessage{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// toValue converts v into a tree of objects, slices and scalars, which can be
+// rendered in any format. Field names are the Go field names in lowerCamelCase,
+// embedded structs are flattened (except for Error, which is omitted when
+// there's no error), and network addresses, durations, and times are
+// formatted as strings.
+//
+//nolint:gocyclo,funlen
+func toValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+	default:
+	}
+
+	// pointers to types with value String methods are rendered like the value
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Implements(stringerType) {
+		return toValue(v.Elem())
+	}
+
+	switch v.Type() {
+	case errorType:
+		e := v.Interface().(hitron.Error) //nolint:forcetypeassert
+		if e.Err() == nil {
+			return nil
+		}
+
+		return object{{name: "code", value: e.Code}, {name: "message", value: e.Message}}
+	case timeType:
+		t := v.Interface().(time.Time) //nolint:forcetypeassert
+		if t.IsZero() {
+			return nil
+		}
+
+		return t.Format(time.RFC3339)
+	case ipMaskType:
+		return net.IP(v.Bytes()).String()
+	case rawJSONType:
+		var o interface{}
+		if err := json.Unmarshal(v.Bytes(), &o); err != nil {
+			return string(v.Bytes())
+		}
+
+		return o
+	}
+
+	// types like net.IP, time.Duration, and PortRange have a useful String
+	// method, but response types (which embed Error) are rendered field by field
+	if v.Type().Implements(stringerType) && !embedsError(v.Type()) {
+		return v.Interface().(fmt.Stringer).String() //nolint:forcetypeassert
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return toValue(v.Elem())
+	case reflect.Struct:
+		return structValue(v)
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = toValue(v.Index(i))
+		}
+
+		return out
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func structValue(v reflect.Value) object {
+	o := object{}
+
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := toValue(v.Field(i))
+
+		if sf.Anonymous {
+			if sub, ok := fv.(object); ok && sf.Type != errorType {
+				o = append(o, sub...)
+
+				continue
+			}
+
+			if fv == nil {
+				continue
+			}
+		}
+
+		o = append(o, field{name: fieldName(sf.Name), value: fv})
+	}
+
+	return o
+}
+
+func embedsError(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	f, ok := t.FieldByName("Error")
+
+	return ok && f.Anonymous && f.Type == errorType
+}
+
+// fieldName converts a Go field name to lowerCamelCase, keeping initialisms
+// together (e.g. "APIVersion" becomes "apiVersion", and "IP" becomes "ip")
+func fieldName(name string) string {
+	r := []rune(name)
+
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+
+	// the last capital of an initialism starts the next word
+	if n > 1 && n < len(r) && unicode.IsLower(r[n]) {
+		n--
+	}
+
+	if n == 0 {
+		n = 1
+	}
+
+	for i := range n {
+		r[i] = unicode.ToLower(r[i])
+	}
+
+	return string(r)
+}
+
+// renderTable writes scalar fields as a two-column list, and lists of objects
+// as tables with a column per field
+func renderTable(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	switch v := v.(type) {
+	case object:
+		var lists []field
+
+		for _, f := range v {
+			if isObjectList(f.value) {
+				lists = append(lists, f)
+
+				continue
+			}
+
+			fmt.Fprintf(tw, "%s:\t%s\n", f.name, cellString(f.value))
+		}
+
+		// lists are separated from whatever comes before them by a blank line
+		written := len(lists) < len(v)
+
+		for _, f := range lists {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			if written {
+				fmt.Fprintln(tw)
+			}
+
+			written = true
+
+			fmt.Fprintf(tw, "%s:\n", f.name)
+			writeRows(tw, f.value.([]interface{})) //nolint:forcetypeassert
+		}
+	case []interface{}:
+		writeRows(tw, v)
+	case nil:
+	default:
+		fmt.Fprintln(tw, cellString(v))
+	}
+
+	return tw.Flush()
+}
+
+func isObjectList(v interface{}) bool {
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return false
+	}
+
+	_, ok = l[0].(object)
+
+	return ok
+}
+
+func writeRows(w io.Writer, rows []interface{}) {
+	if len(rows) == 0 {
+		return
+	}
+
+	first, ok := rows[0].(object)
+	if !ok {
+		for _, r := range rows {
+			fmt.Fprintln(w, cellString(r))
+		}
+
+		return
+	}
+
+	headers := make([]string, len(first))
+	for i, f := range first {
+		headers[i] = strings.ToUpper(f.name)
+	}
+
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for _, r := range rows {
+		o, _ := r.(object)
+
+		cells := make([]string, len(o))
+		for i, f := range o {
+			cells[i] = cellString(f.value)
+		}
+
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+// cellString formats a value for a single table cell
+func cellString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case object:
+		parts := make([]string, len(v))
+		for i, f := range v {
+			parts[i] = f.name + "=" + cellString(f.value)
+		}
+
+		return strings.Join(parts, " ")
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = cellString(e)
+		}
+
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRule struct {
+	IP      net.IP
+	Name    string
+	Ports   []int
+	Timeout time.Duration
+}
+
+type testResponse struct {
+	hitron.Error
+	APIVersion string
+	Uptime     time.Duration
+	Rules      []testRule
+}
+
+func TestRender(t *testing.T) {
+	resp := testResponse{
+		Error:      hitron.NoError,
+		APIVersion: "1.11",
+		Uptime:     90 * time.Minute,
+		Rules: []testRule{
+			{IP: net.ParseIP("192.168.0.16"), Name: "ssh", Ports: []int{22, 2222}, Timeout: time.Minute},
+			{Name: "web", Ports: []int{80}},
+		},
+	}
+
+	dsInfo := hitron.CMDsInfo{
+		Error: hitron.NoError,
+		Ports: hitron.PortInfos{
+			{PortID: "1", ChannelID: "11", Modulation: "QAM256", Frequency: 615000000, SignalStrength: 3.2, SNR: 38.605},
+		},
+	}
+
+	testdata := []struct {
+		v        interface{}
+		format   OutputFormat
+		expected string
+	}{
+		{resp, formatJSON, `{
+  "apiVersion": "1.11",
+  "uptime": "1h30m0s",
+  "rules": [
+    {
+      "ip": "192.168.0.16",
+      "name": "ssh",
+      "ports": [
+        22,
+        2222
+      ],
+      "timeout": "1m0s"
+    },
+    {
+      "ip": null,
+      "name": "web",
+      "ports": [
+        80
+      ],
+      "timeout": "0s"
+    }
+  ]
+}
+`},
+		{resp, formatYAML, `apiVersion: "1.11"
+uptime: 1h30m0s
+rules:
+  - ip: 192.168.0.16
+    name: ssh
+    ports:
+      - 22
+      - 2222
+    timeout: 1m0s
+  - ip: null
+    name: web
+    ports:
+      - 80
+    timeout: 0s
+`},
+		{resp, formatTable, `apiVersion:  1.11
+uptime:      1h30m0s
+
+rules:
+IP            NAME  PORTS    TIMEOUT
+192.168.0.16  ssh   22,2222  1m0s
+              web   80       0s
+`},
+		// without a String method, text falls back to the table format
+		{resp, formatText, `apiVersion:  1.11
+uptime:      1h30m0s
+
+rules:
+IP            NAME  PORTS    TIMEOUT
+192.168.0.16  ssh   22,2222  1m0s
+              web   80       0s
+`},
+		// lists with nothing before them aren't preceded by a blank line
+		{dsInfo, formatTable, `ports:
+PORTID  MODULATION  CHANNELID  SIGNALSTRENGTH  FREQUENCY  BANDWIDTH  SNR     DSOCTETS  CORRECTEDS  UNCORRECT
+1       QAM256      11         3.2             615000000  0          38.605  0         0           0
+`},
+		{[]net.IP{net.ParseIP("192.168.0.1"), net.ParseIP("2001:db8::1")}, formatTable, "192.168.0.1\n2001:db8::1\n"},
+		{hitron.Error{Code: "006", Message: "oops"}, formatJSON, "{\n  \"code\": \"006\",\n  \"message\": \"oops\"\n}\n"},
+		{hitron.PortRange{Start: 8080, End: 8088}, formatYAML, "8080-8088\n"},
+	}
+
+	for _, d := range testdata {
+		buf := &bytes.Buffer{}
+
+		require.NoError(t, render(buf, d.format, d.v))
+		assert.Equal(t, d.expected, buf.String(), "%s: %T", d.format, d.v)
+	}
+}
+
+func TestRender_UnknownFormat(t *testing.T) {
+	assert.Error(t, render(&bytes.Buffer{}, "xml", testResponse{}))
+}
+
+func TestFieldName(t *testing.T) {
+	for in, expected := range map[string]string{
+		"APIVersion": "apiVersion",
+		"IP":         "ip",
+		"MacAddr":    "macAddr",
+		"SNR":        "snr",
+		"DsOctets":   "dsOctets",
+	} {
+		assert.Equal(t, expected, fieldName(in), in)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+//go:generate gomplate -c .=../../apilist.yaml -f commands.go.tmpl -o commands.go
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+)
+
+// getter - a command which prints the response from a GET endpoint
+type getter struct {
+	fn    func(ctx context.Context) (interface{}, error)
+	group string
+	name  string // empty for the group's default command
+	path  string
+}
+
+// action - a hand-written command, which parses its own arguments. Actions
+// take precedence over getters with the same name.
+type action struct {
+	fn    func(ctx context.Context, argv []string) error
+	usage string
+	help  string
+}
+
+// groups returns the names of all command groups with generated getters
+func groups() []string {
+	seen := map[string]bool{}
+	names := []string{}
+
+	for _, g := range getters(nil) {
+		if !seen[g.group] {
+			seen[g.group] = true
+
+			names = append(names, g.group)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// cmdGroup runs a subcommand from the group named by the flagset - either one
+// of the given actions, or a generated getter. With no subcommand, the group's
+// default getter (if any) is run.
+func cmdGroup(ctx context.Context, cm *hitron.CableModem, format OutputFormat,
+	f *flag.FlagSet, actions map[string]action, argv []string,
+) error {
+	cmds := map[string]getter{}
+
+	for _, g := range getters(cm) {
+		if g.group == f.Name() {
+			cmds[g.name] = g
+		}
+	}
+
+	f.Usage = func() { groupUsage(f, cmds, actions) }
+
+	_ = f.Parse(argv)
+
+	name := ""
+
+	args := f.Args()
+	if len(args) > 0 {
+		name = args[0]
+		args = args[1:]
+	}
+
+	a, isAction := actions[name]
+	g, ok := cmds[name]
+
+	switch {
+	case isAction:
+	case ok:
+	case name == "":
+		f.Usage()
+
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand: %s", name)
+	}
+
+	if err := cm.Login(ctx); err != nil {
+		return err
+	}
+
+	defer logout(ctx, cm)
+
+	if isAction {
+		return a.fn(ctx, args)
+	}
+
+	out, err := g.fn(ctx)
+	if err != nil {
+		return err
+	}
+
+	return render(os.Stdout, format, out)
+}
+
+func groupUsage(f *flag.FlagSet, cmds map[string]getter, actions map[string]action) {
+	f.PrintDefaults()
+
+	help := map[string]string{}
+	for name, g := range cmds {
+		help[name] = fmt.Sprintf("\t%s\n\t\tPrint %s\n", name, g.path)
+	}
+
+	for name, a := range actions {
+		help[name] = fmt.Sprintf("\t%s\n\t\t%s\n", a.usage, a.help)
+	}
+
+	names := make([]string, 0, len(help))
+	for name := range help {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintf(f.Output(), "\nsubcommands:\n")
+
+	for _, name := range names {
+		if name == "" {
+			fmt.Fprintf(f.Output(), "\t(none)\n\t\tPrint %s\n", cmds[name].path)
+
+			continue
+		}
+
+		fmt.Fprint(f.Output(), help[name])
+	}
+}
+
+// logout ends the session, even when ctx has been cancelled (on Ctrl-C or a
+// timeout), so that sessions aren't left open on the modem
+func logout(ctx context.Context, cm *hitron.CableModem) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	_ = cm.Logout(ctx)
+}
@@ -0,0 +1,82 @@
+package hitron
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors, for use with errors.Is
+//
+//nolint:gochecknoglobals
+var (
+	// ErrUnauthorized indicates that the request was rejected because there is
+	// no valid session - Login is required
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrCSRFMismatch indicates that the modem rejected the CSRF token sent
+	// with a write request
+	ErrCSRFMismatch = errors.New("CSRF token mismatch")
+)
+
+// StatusError is returned when the modem responds with an unexpected HTTP
+// status, or with a login page instead of the expected JSON response.
+type StatusError struct {
+	Header     http.Header
+	Body       []byte
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed with status %d: %s", e.StatusCode, string(e.Body))
+}
+
+// Is - supports errors.Is for ErrUnauthorized and ErrCSRFMismatch
+func (e *StatusError) Is(target error) bool {
+	csrf := mentionsCSRF(string(e.Body))
+
+	switch target {
+	case ErrCSRFMismatch:
+		return csrf && e.StatusCode != http.StatusOK
+	case ErrUnauthorized:
+		return !csrf && sessionExpired(e.StatusCode, e.Body)
+	default:
+		return false
+	}
+}
+
+// APIError is returned when the modem responds successfully at the HTTP level,
+// but with a non-zero error code in the response body.
+type APIError struct {
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("error %s: %s", e.Code, e.Message)
+}
+
+// Is - supports errors.Is for ErrCSRFMismatch
+func (e *APIError) Is(target error) bool {
+	return target == ErrCSRFMismatch && mentionsCSRF(e.Message)
+}
+
+// Err returns the error code as an *APIError, or nil if the code indicates
+// success (or is absent).
+func (e Error) Err() error {
+	if e.Code == "" || e.Code == NoError.Code {
+		return nil
+	}
+
+	return &APIError{Code: e.Code, Message: e.Message}
+}
+
+// apiErrorer is implemented by every response type which embeds Error
+type apiErrorer interface {
+	Err() error
+}
+
+func mentionsCSRF(s string) bool {
+	return strings.Contains(strings.ToLower(s), "csrf")
+}
@@ -0,0 +1,109 @@
+package hitron
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorErr(t *testing.T) {
+	assert.NoError(t, NoError.Err())
+	assert.NoError(t, Error{}.Err())
+
+	err := Error{Code: "001", Message: "oops"}.Err()
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "001", apiErr.Code)
+	assert.Equal(t, "oops", apiErr.Message)
+	assert.EqualError(t, err, "error 001: oops")
+	assert.NotErrorIs(t, err, ErrCSRFMismatch)
+
+	err = Error{Code: "002", Message: "CSRF token invalid"}.Err()
+	assert.ErrorIs(t, err, ErrCSRFMismatch)
+}
+
+func TestStatusErrorIs(t *testing.T) {
+	testdata := []struct {
+		err          *StatusError
+		unauthorized bool
+		csrf         bool
+	}{
+		{&StatusError{StatusCode: http.StatusForbidden}, true, false},
+		{&StatusError{StatusCode: http.StatusUnauthorized}, true, false},
+		{&StatusError{StatusCode: http.StatusFound}, true, false},
+		{&StatusError{StatusCode: http.StatusOK, Body: []byte("<html>login</html>")}, true, false},
+		{&StatusError{StatusCode: http.StatusForbidden, Body: []byte("invalid csrf")}, false, true},
+		{&StatusError{StatusCode: http.StatusInternalServerError}, false, false},
+	}
+
+	for _, d := range testdata {
+		assert.Equal(t, d.unauthorized, errors.Is(d.err, ErrUnauthorized), d.err.Error())
+		assert.Equal(t, d.csrf, errors.Is(d.err, ErrCSRFMismatch), d.err.Error())
+	}
+}
+
+func TestSendRequest_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	// non-zero errCode in the body is surfaced as an *APIError
+	srv := staticResponseServer(t, `{"errCode":"006","errMsg":"Something went wrong","locationText":""}`)
+	d := testCableModem(srv)
+
+	_, err := d.RouterLocation(ctx)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "006", apiErr.Code)
+	assert.Equal(t, "Something went wrong", apiErr.Message)
+
+	// bad statuses are surfaced as a *StatusError
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	t.Cleanup(srv.Close)
+
+	d = testCableModem(srv)
+
+	_, err = d.RouterLocation(ctx)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	assert.Equal(t, "boom", string(statusErr.Body))
+	assert.NotErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestSendRequest_ErrorBodyAllGetters(t *testing.T) {
+	ctx := context.Background()
+
+	// error responses carry only the error code, so must be reported before
+	// the response types try to decode their other fields
+	srv := staticResponseServer(t, `{"errCode":"006","errMsg":"Something went wrong"}`)
+	d := testCableModem(srv)
+
+	apiErrorerType := reflect.TypeFor[apiErrorer]()
+
+	v := reflect.ValueOf(d)
+	for i := range v.NumMethod() {
+		m := v.Type().Method(i)
+		if m.Type.NumIn() != 2 || m.Type.NumOut() != 2 || !m.Type.Out(0).Implements(apiErrorerType) {
+			continue
+		}
+
+		out := v.Method(i).Call([]reflect.Value{reflect.ValueOf(ctx)})
+		err, _ := out[1].Interface().(error)
+
+		var apiErr *APIError
+		if assert.ErrorAs(t, err, &apiErr, m.Name) {
+			assert.Equal(t, "006", apiErr.Code, m.Name)
+		}
+	}
+}
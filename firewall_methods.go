@@ -0,0 +1,581 @@
+package hitron
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// AddInboundRule - adds a new inbound firewall rule, and returns the refreshed
+// list of rules. The rule's ID is ignored, as the modem assigns a new one.
+func (c *CableModem) AddInboundRule(ctx context.Context, rule InboundRule) (FirewallInboundRules, error) {
+	rule.ID = 0
+
+	if err := c.checkInboundRule(ctx, rule, false); err != nil {
+		return FirewallInboundRules{}, err
+	}
+
+	err := c.sendModel(ctx, http.MethodPost, "/Firewall/Inbound/Rules", rule)
+	if err != nil {
+		return FirewallInboundRules{}, fmt.Errorf("failed to add inbound rule %q: %w", rule.Name, err)
+	}
+
+	return c.FirewallInboundRules(ctx)
+}
+
+// UpdateInboundRule - replaces the inbound firewall rule with the same ID, and
+// returns the refreshed list of rules.
+func (c *CableModem) UpdateInboundRule(ctx context.Context, rule InboundRule) (FirewallInboundRules, error) {
+	if err := c.checkInboundRule(ctx, rule, true); err != nil {
+		return FirewallInboundRules{}, err
+	}
+
+	err := c.sendModel(ctx, http.MethodPut, inboundRulePath(rule.ID), rule)
+	if err != nil {
+		return FirewallInboundRules{}, fmt.Errorf("failed to update inbound rule %d: %w", rule.ID, err)
+	}
+
+	return c.FirewallInboundRules(ctx)
+}
+
+// DeleteInboundRule - deletes the inbound firewall rule with the given ID, and
+// returns the refreshed list of rules.
+func (c *CableModem) DeleteInboundRule(ctx context.Context, id int) (FirewallInboundRules, error) {
+	rule, err := c.inboundRule(ctx, id)
+	if err != nil {
+		return FirewallInboundRules{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodDelete, inboundRulePath(id), rule)
+	if err != nil {
+		return FirewallInboundRules{}, fmt.Errorf("failed to delete inbound rule %d: %w", id, err)
+	}
+
+	return c.FirewallInboundRules(ctx)
+}
+
+// SetInboundRuleEnabled - turns the inbound firewall rule with the given ID on
+// or off, and returns the refreshed list of rules.
+func (c *CableModem) SetInboundRuleEnabled(ctx context.Context, id int, enable bool) (FirewallInboundRules, error) {
+	rule, err := c.inboundRule(ctx, id)
+	if err != nil {
+		return FirewallInboundRules{}, err
+	}
+
+	rule.Enable = enable
+
+	return c.UpdateInboundRule(ctx, rule)
+}
+
+// SetInboundEnabled - turns inbound firewall rules as a whole on or off (the
+// AllRulesOnOff setting), without changing the individual rules.
+func (c *CableModem) SetInboundEnabled(ctx context.Context, enable bool) (FirewallInboundStatus, error) {
+	err := c.sendModel(ctx, http.MethodPut, "/Firewall/Inbound/Status", map[string]string{"allRulesOnOff": onOff(enable)})
+	if err != nil {
+		return FirewallInboundStatus{}, fmt.Errorf("failed to set inbound rules status: %w", err)
+	}
+
+	return c.FirewallInboundStatus(ctx)
+}
+
+// checkInboundRule validates the rule, and checks that it doesn't duplicate
+// an existing rule. Rules being updated must replace an existing rule.
+func (c *CableModem) checkInboundRule(ctx context.Context, rule InboundRule, update bool) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	all, err := c.FirewallInboundRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	if update && !slices.ContainsFunc(all.Rules, func(r InboundRule) bool { return r.ID == rule.ID }) {
+		return fmt.Errorf("no inbound rule with ID %d", rule.ID)
+	}
+
+	return rule.duplicates(all.Rules)
+}
+
+func (c *CableModem) inboundRule(ctx context.Context, id int) (InboundRule, error) {
+	all, err := c.FirewallInboundRules(ctx)
+	if err != nil {
+		return InboundRule{}, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+
+	return InboundRule{}, fmt.Errorf("no inbound rule with ID %d", id)
+}
+
+func inboundRulePath(id int) string {
+	return "/Firewall/Inbound/Rules/" + strconv.Itoa(id)
+}
+
+// AddDeviceFilterRule - adds a device to the device filter, and returns the
+// refreshed list of rules. Each device may only have one rule.
+func (c *CableModem) AddDeviceFilterRule(ctx context.Context, rule DeviceFilterRule) (FirewallDeviceFilterRules, error) {
+	if err := rule.Validate(); err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	all, err := c.FirewallDeviceFilterRules(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	if existing, ok := all.findRule(rule.MacAddr); ok {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("%s is already in the device filter (as %q)", rule.MacAddr, existing.Name)
+	}
+
+	rule.ID = 0
+
+	err = c.sendModel(ctx, http.MethodPost, "/Firewall/DeviceFilter/Rules", rule)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("failed to add device filter rule for %s: %w", rule.MacAddr, err)
+	}
+
+	return c.FirewallDeviceFilterRules(ctx)
+}
+
+// UpdateDeviceFilterRule - replaces the device filter rule with the same ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) UpdateDeviceFilterRule(ctx context.Context, rule DeviceFilterRule) (FirewallDeviceFilterRules, error) {
+	if err := rule.Validate(); err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	all, err := c.FirewallDeviceFilterRules(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	if !slices.ContainsFunc(all.Rules, func(r DeviceFilterRule) bool { return r.ID == rule.ID }) {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("no device filter rule with ID %d", rule.ID)
+	}
+
+	if existing, ok := all.findRule(rule.MacAddr); ok && existing.ID != rule.ID {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("%s is already in the device filter (as %q)", rule.MacAddr, existing.Name)
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, deviceFilterRulePath(rule.ID), rule)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("failed to update device filter rule %d: %w", rule.ID, err)
+	}
+
+	return c.FirewallDeviceFilterRules(ctx)
+}
+
+// DeleteDeviceFilterRule - deletes the device filter rule with the given ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) DeleteDeviceFilterRule(ctx context.Context, id int) (FirewallDeviceFilterRules, error) {
+	all, err := c.FirewallDeviceFilterRules(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID != id {
+			continue
+		}
+
+		err = c.sendModel(ctx, http.MethodDelete, deviceFilterRulePath(id), rule)
+		if err != nil {
+			return FirewallDeviceFilterRules{}, fmt.Errorf("failed to delete device filter rule %d: %w", id, err)
+		}
+
+		return c.FirewallDeviceFilterRules(ctx)
+	}
+
+	return FirewallDeviceFilterRules{}, fmt.Errorf("no device filter rule with ID %d", id)
+}
+
+// SetDeviceFilterType - sets the device filter mode: DeviceFilterBlock,
+// DeviceFilterAllow, or DeviceFilterDisabled
+func (c *CableModem) SetDeviceFilterType(ctx context.Context, blockType string) (FirewallDeviceFilterType, error) {
+	switch blockType {
+	case DeviceFilterAllow, DeviceFilterBlock, DeviceFilterDisabled:
+	default:
+		return FirewallDeviceFilterType{}, fmt.Errorf("invalid device filter type %q", blockType)
+	}
+
+	err := c.sendModel(ctx, http.MethodPut, "/Firewall/DeviceFilter/Type", map[string]string{"blockType": blockType})
+	if err != nil {
+		return FirewallDeviceFilterType{}, fmt.Errorf("failed to set device filter type: %w", err)
+	}
+
+	return c.FirewallDeviceFilterType(ctx)
+}
+
+// BlockDevice - blocks the device with the given MAC address from reaching the
+// Internet during the given schedule (or at all times, if the schedule has no
+// days). The device's existing rule is replaced, if it has one. If the device
+// filter is disabled it's switched to DeviceFilterBlock, but an error is
+// returned if it's in DeviceFilterAllow mode, where listed devices are the
+// only ones allowed through.
+func (c *CableModem) BlockDevice(ctx context.Context, mac net.HardwareAddr, name string, sched Schedule) (FirewallDeviceFilterRules, error) {
+	if len(sched.Days) == 0 {
+		sched = Always
+	}
+
+	rule := DeviceFilterRule{Name: name, MacAddr: mac, Schedule: sched, Enable: true}
+	if err := rule.Validate(); err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	filterType, err := c.FirewallDeviceFilterType(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	if filterType.BlockType == DeviceFilterAllow {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("can't block %s: the device filter is in %q mode", mac, DeviceFilterAllow)
+	}
+
+	all, err := c.FirewallDeviceFilterRules(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	if existing, ok := all.findRule(mac); ok {
+		rule.ID = existing.ID
+		if rule.Name == "" {
+			rule.Name = existing.Name
+		}
+
+		all, err = c.UpdateDeviceFilterRule(ctx, rule)
+	} else {
+		all, err = c.AddDeviceFilterRule(ctx, rule)
+	}
+
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	if filterType.BlockType == DeviceFilterDisabled {
+		if _, err = c.SetDeviceFilterType(ctx, DeviceFilterBlock); err != nil {
+			return FirewallDeviceFilterRules{}, err
+		}
+	}
+
+	return all, nil
+}
+
+// UnblockDevice - removes the device filter rule for the device with the given
+// MAC address, and returns the refreshed list of rules. As with BlockDevice,
+// this fails when the filter is in "Allow Listed" mode, where removing the
+// rule would block the device instead.
+func (c *CableModem) UnblockDevice(ctx context.Context, mac net.HardwareAddr) (FirewallDeviceFilterRules, error) {
+	filterType, err := c.FirewallDeviceFilterType(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	if filterType.BlockType == DeviceFilterAllow {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("can't unblock %s: the device filter is in %q mode", mac, DeviceFilterAllow)
+	}
+
+	all, err := c.FirewallDeviceFilterRules(ctx)
+	if err != nil {
+		return FirewallDeviceFilterRules{}, err
+	}
+
+	rule, ok := all.findRule(mac)
+	if !ok {
+		return FirewallDeviceFilterRules{}, fmt.Errorf("%s is not in the device filter", mac)
+	}
+
+	return c.DeleteDeviceFilterRule(ctx, rule.ID)
+}
+
+func deviceFilterRulePath(id int) string {
+	return "/Firewall/DeviceFilter/Rules/" + strconv.Itoa(id)
+}
+
+// AddKeywordFilterRule - adds a URL keyword to be blocked, and returns the
+// refreshed list of rules. Keywords are compared without regard to case.
+func (c *CableModem) AddKeywordFilterRule(ctx context.Context, rule KeywordFilterRule) (FirewallKeywordFilterRules, error) {
+	if err := rule.Validate(); err != nil {
+		return FirewallKeywordFilterRules{}, err
+	}
+
+	all, err := c.FirewallKeywordFilterRules(ctx)
+	if err != nil {
+		return FirewallKeywordFilterRules{}, err
+	}
+
+	if existing, ok := all.findRule(rule.Keyword); ok {
+		return FirewallKeywordFilterRules{}, fmt.Errorf("keyword %q is already filtered (rule %d)", rule.Keyword, existing.ID)
+	}
+
+	rule.ID = 0
+
+	err = c.sendModel(ctx, http.MethodPost, "/Firewall/KeywordFilter/Rules", rule)
+	if err != nil {
+		return FirewallKeywordFilterRules{}, fmt.Errorf("failed to add keyword filter rule %q: %w", rule.Keyword, err)
+	}
+
+	return c.FirewallKeywordFilterRules(ctx)
+}
+
+// UpdateKeywordFilterRule - replaces the keyword filter rule with the same ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) UpdateKeywordFilterRule(ctx context.Context, rule KeywordFilterRule) (FirewallKeywordFilterRules, error) {
+	if err := rule.Validate(); err != nil {
+		return FirewallKeywordFilterRules{}, err
+	}
+
+	all, err := c.FirewallKeywordFilterRules(ctx)
+	if err != nil {
+		return FirewallKeywordFilterRules{}, err
+	}
+
+	if !slices.ContainsFunc(all.Rules, func(r KeywordFilterRule) bool { return r.ID == rule.ID }) {
+		return FirewallKeywordFilterRules{}, fmt.Errorf("no keyword filter rule with ID %d", rule.ID)
+	}
+
+	if existing, ok := all.findRule(rule.Keyword); ok && existing.ID != rule.ID {
+		return FirewallKeywordFilterRules{}, fmt.Errorf("keyword %q is already filtered (rule %d)", rule.Keyword, existing.ID)
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, "/Firewall/KeywordFilter/Rules/"+strconv.Itoa(rule.ID), rule)
+	if err != nil {
+		return FirewallKeywordFilterRules{}, fmt.Errorf("failed to update keyword filter rule %d: %w", rule.ID, err)
+	}
+
+	return c.FirewallKeywordFilterRules(ctx)
+}
+
+// DeleteKeywordFilterRule - deletes the keyword filter rule with the given ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) DeleteKeywordFilterRule(ctx context.Context, id int) (FirewallKeywordFilterRules, error) {
+	all, err := c.FirewallKeywordFilterRules(ctx)
+	if err != nil {
+		return FirewallKeywordFilterRules{}, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID != id {
+			continue
+		}
+
+		err = c.sendModel(ctx, http.MethodDelete, "/Firewall/KeywordFilter/Rules/"+strconv.Itoa(id), rule)
+		if err != nil {
+			return FirewallKeywordFilterRules{}, fmt.Errorf("failed to delete keyword filter rule %d: %w", id, err)
+		}
+
+		return c.FirewallKeywordFilterRules(ctx)
+	}
+
+	return FirewallKeywordFilterRules{}, fmt.Errorf("no keyword filter rule with ID %d", id)
+}
+
+// SetKeywordFilterEnabled - turns keyword filtering as a whole on or off,
+// without changing the individual rules.
+func (c *CableModem) SetKeywordFilterEnabled(ctx context.Context, enable bool) (FirewallKeywordFilterStatus, error) {
+	err := c.sendModel(ctx, http.MethodPut, "/Firewall/KeywordFilter/Status", map[string]string{"allRulesOnOff": onOff(enable)})
+	if err != nil {
+		return FirewallKeywordFilterStatus{}, fmt.Errorf("failed to set keyword filter status: %w", err)
+	}
+
+	return c.FirewallKeywordFilterStatus(ctx)
+}
+
+// AddKeywordFilterTrust - exempts a device from keyword filtering, and returns
+// the refreshed list of trusted devices.
+func (c *CableModem) AddKeywordFilterTrust(ctx context.Context, rule TrustRule) (FirewallKeywordFilterTrustRules, error) {
+	all, err := c.FirewallKeywordFilterTrustRules(ctx)
+	if err != nil {
+		return FirewallKeywordFilterTrustRules{}, err
+	}
+
+	err = c.addTrustRule(ctx, "/Firewall/KeywordFilter/TrustRules", all.Rules, rule)
+	if err != nil {
+		return FirewallKeywordFilterTrustRules{}, err
+	}
+
+	return c.FirewallKeywordFilterTrustRules(ctx)
+}
+
+// DeleteKeywordFilterTrust - removes the keyword filtering exemption for the
+// device with the given MAC address, and returns the refreshed list of trusted
+// devices.
+func (c *CableModem) DeleteKeywordFilterTrust(ctx context.Context, mac net.HardwareAddr) (FirewallKeywordFilterTrustRules, error) {
+	all, err := c.FirewallKeywordFilterTrustRules(ctx)
+	if err != nil {
+		return FirewallKeywordFilterTrustRules{}, err
+	}
+
+	err = c.deleteTrustRule(ctx, "/Firewall/KeywordFilter/TrustRules", all.Rules, mac)
+	if err != nil {
+		return FirewallKeywordFilterTrustRules{}, err
+	}
+
+	return c.FirewallKeywordFilterTrustRules(ctx)
+}
+
+// AddServiceFilterRule - adds a service to be blocked, and returns the
+// refreshed list of rules. Rule names must be unique.
+func (c *CableModem) AddServiceFilterRule(ctx context.Context, rule ServiceFilterRule) (FirewallServiceFilterRules, error) {
+	if err := rule.Validate(); err != nil {
+		return FirewallServiceFilterRules{}, err
+	}
+
+	all, err := c.FirewallServiceFilterRules(ctx)
+	if err != nil {
+		return FirewallServiceFilterRules{}, err
+	}
+
+	if existing, ok := all.findRule(rule.Name); ok {
+		return FirewallServiceFilterRules{}, fmt.Errorf("there is already a service filter rule named %q (rule %d)", rule.Name, existing.ID)
+	}
+
+	rule.ID = 0
+
+	err = c.sendModel(ctx, http.MethodPost, "/Firewall/ServiceFilter/Rules", rule)
+	if err != nil {
+		return FirewallServiceFilterRules{}, fmt.Errorf("failed to add service filter rule %q: %w", rule.Name, err)
+	}
+
+	return c.FirewallServiceFilterRules(ctx)
+}
+
+// UpdateServiceFilterRule - replaces the service filter rule with the same ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) UpdateServiceFilterRule(ctx context.Context, rule ServiceFilterRule) (FirewallServiceFilterRules, error) {
+	if err := rule.Validate(); err != nil {
+		return FirewallServiceFilterRules{}, err
+	}
+
+	all, err := c.FirewallServiceFilterRules(ctx)
+	if err != nil {
+		return FirewallServiceFilterRules{}, err
+	}
+
+	if !slices.ContainsFunc(all.Rules, func(r ServiceFilterRule) bool { return r.ID == rule.ID }) {
+		return FirewallServiceFilterRules{}, fmt.Errorf("no service filter rule with ID %d", rule.ID)
+	}
+
+	if existing, ok := all.findRule(rule.Name); ok && existing.ID != rule.ID {
+		return FirewallServiceFilterRules{}, fmt.Errorf("there is already a service filter rule named %q (rule %d)", rule.Name, existing.ID)
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, "/Firewall/ServiceFilter/Rules/"+strconv.Itoa(rule.ID), rule)
+	if err != nil {
+		return FirewallServiceFilterRules{}, fmt.Errorf("failed to update service filter rule %d: %w", rule.ID, err)
+	}
+
+	return c.FirewallServiceFilterRules(ctx)
+}
+
+// DeleteServiceFilterRule - deletes the service filter rule with the given ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) DeleteServiceFilterRule(ctx context.Context, id int) (FirewallServiceFilterRules, error) {
+	all, err := c.FirewallServiceFilterRules(ctx)
+	if err != nil {
+		return FirewallServiceFilterRules{}, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID != id {
+			continue
+		}
+
+		err = c.sendModel(ctx, http.MethodDelete, "/Firewall/ServiceFilter/Rules/"+strconv.Itoa(id), rule)
+		if err != nil {
+			return FirewallServiceFilterRules{}, fmt.Errorf("failed to delete service filter rule %d: %w", id, err)
+		}
+
+		return c.FirewallServiceFilterRules(ctx)
+	}
+
+	return FirewallServiceFilterRules{}, fmt.Errorf("no service filter rule with ID %d", id)
+}
+
+// SetServiceFilterEnabled - turns service filtering as a whole on or off,
+// without changing the individual rules.
+func (c *CableModem) SetServiceFilterEnabled(ctx context.Context, enable bool) (FirewallServiceFilterStatus, error) {
+	err := c.sendModel(ctx, http.MethodPut, "/Firewall/ServiceFilter/Status", map[string]string{"allRulesOnOff": onOff(enable)})
+	if err != nil {
+		return FirewallServiceFilterStatus{}, fmt.Errorf("failed to set service filter status: %w", err)
+	}
+
+	return c.FirewallServiceFilterStatus(ctx)
+}
+
+// AddServiceFilterTrust - exempts a device from service filtering, and returns
+// the refreshed list of trusted devices.
+func (c *CableModem) AddServiceFilterTrust(ctx context.Context, rule TrustRule) (FirewallServiceFilterTrustRules, error) {
+	all, err := c.FirewallServiceFilterTrustRules(ctx)
+	if err != nil {
+		return FirewallServiceFilterTrustRules{}, err
+	}
+
+	err = c.addTrustRule(ctx, "/Firewall/ServiceFilter/TrustRules", all.Rules, rule)
+	if err != nil {
+		return FirewallServiceFilterTrustRules{}, err
+	}
+
+	return c.FirewallServiceFilterTrustRules(ctx)
+}
+
+// DeleteServiceFilterTrust - removes the service filtering exemption for the
+// device with the given MAC address, and returns the refreshed list of trusted
+// devices.
+func (c *CableModem) DeleteServiceFilterTrust(ctx context.Context, mac net.HardwareAddr) (FirewallServiceFilterTrustRules, error) {
+	all, err := c.FirewallServiceFilterTrustRules(ctx)
+	if err != nil {
+		return FirewallServiceFilterTrustRules{}, err
+	}
+
+	err = c.deleteTrustRule(ctx, "/Firewall/ServiceFilter/TrustRules", all.Rules, mac)
+	if err != nil {
+		return FirewallServiceFilterTrustRules{}, err
+	}
+
+	return c.FirewallServiceFilterTrustRules(ctx)
+}
+
+// addTrustRule validates the rule, and adds it to the trust list at the given
+// path unless the device is already listed
+func (c *CableModem) addTrustRule(ctx context.Context, p string, existing []TrustRule, rule TrustRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if r, ok := findTrustRule(existing, rule.MacAddr); ok {
+		return fmt.Errorf("%s is already trusted (as %q)", rule.MacAddr, r.Name)
+	}
+
+	rule.ID = 0
+
+	err := c.sendModel(ctx, http.MethodPost, p, rule)
+	if err != nil {
+		return fmt.Errorf("failed to add trusted device %s: %w", rule.MacAddr, err)
+	}
+
+	return nil
+}
+
+// deleteTrustRule removes the device with the given MAC address from the trust
+// list at the given path
+func (c *CableModem) deleteTrustRule(ctx context.Context, p string, existing []TrustRule, mac net.HardwareAddr) error {
+	rule, ok := findTrustRule(existing, mac)
+	if !ok {
+		return fmt.Errorf("%s is not a trusted device", mac)
+	}
+
+	err := c.sendModel(ctx, http.MethodDelete, p+"/"+strconv.Itoa(rule.ID), rule)
+	if err != nil {
+		return fmt.Errorf("failed to delete trusted device %s: %w", mac, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,601 @@
+package hitron
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inboundRulesBody = `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","ruleName":"SSH","protocol":"TCP","action":"Allow",
+		"portStart":"22","portEnd":"22",
+		"srcIpStart":"203.0.113.1","srcIpEnd":"203.0.113.254","ruleOnOff":"ON"},
+		{"id":"2","ruleName":"no telnet","protocol":"BOTH","action":"Deny",
+		"portStart":"23","portEnd":"23",
+		"srcIpStart":"0.0.0.0","srcIpEnd":"255.255.255.255","ruleOnOff":"ON"}
+	]}`
+
+func TestInboundRuleValidate(t *testing.T) {
+	valid := InboundRule{
+		Name:     "SSH",
+		Protocol: "TCP",
+		Action:   FirewallAllow,
+		Ports:    PortRange{22, 22},
+		Sources:  IPRange{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.254")},
+	}
+	assert.NoError(t, valid.Validate())
+
+	r := valid
+	r.Sources = IPRange{}
+	assert.NoError(t, r.Validate())
+
+	r = valid
+	r.Name = ""
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Protocol = "ICMP"
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Action = "Drop"
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Ports = PortRange{0, 22}
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Sources = IPRange{net.ParseIP("203.0.113.254"), net.ParseIP("203.0.113.1")}
+	assert.Error(t, r.Validate())
+}
+
+func TestInboundRuleDuplicates(t *testing.T) {
+	rules := []InboundRule{
+		{
+			ID: 1, Name: "SSH", Protocol: "TCP", Action: FirewallAllow, Ports: PortRange{22, 22},
+			Sources: IPRange{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.254")},
+		},
+		{
+			ID: 2, Name: "no telnet", Protocol: "BOTH", Action: FirewallDeny, Ports: PortRange{23, 23},
+			Sources: IPRange{net.IPv4zero, net.IPv4bcast},
+		},
+	}
+
+	r := InboundRule{
+		Name: "ssh again", Protocol: "TCP", Action: FirewallAllow, Ports: PortRange{22, 22},
+		Sources: IPRange{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.254")},
+	}
+	assert.ErrorContains(t, r.duplicates(rules), `duplicates rule "SSH"`)
+
+	// an unset source range is the same as "any address"
+	r = InboundRule{Name: "telnet", Protocol: "BOTH", Action: FirewallDeny, Ports: PortRange{23, 23}}
+	assert.ErrorContains(t, r.duplicates(rules), `duplicates rule "no telnet"`)
+
+	// protocols are compared without regard to case
+	r.Protocol = "both"
+	assert.ErrorContains(t, r.duplicates(rules), `duplicates rule "no telnet"`)
+
+	// overlapping, but not identical, rules are allowed
+	r.Protocol = "TCP"
+	assert.NoError(t, r.duplicates(rules))
+
+	r.Protocol = "BOTH"
+	r.Action = FirewallAllow
+	assert.NoError(t, r.duplicates(rules))
+
+	// a rule doesn't duplicate itself
+	r = rules[0]
+	assert.NoError(t, r.duplicates(rules))
+}
+
+func TestInboundRuleMarshalJSON(t *testing.T) {
+	in := InboundRule{
+		ID: 1, Enable: true,
+		Name:     "SSH",
+		Protocol: "TCP",
+		Action:   FirewallAllow,
+		Ports:    PortRange{22, 2222},
+		Sources:  IPRange{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.254")},
+	}
+
+	b, err := in.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","ruleName":"SSH","protocol":"TCP","action":"Allow",
+		"portStart":"22","portEnd":"2222",
+		"srcIpStart":"203.0.113.1","srcIpEnd":"203.0.113.254","ruleOnOff":"ON"}`, string(b))
+
+	out := InboundRule{}
+	require.NoError(t, out.UnmarshalJSON(b))
+	assert.Equal(t, in, out)
+
+	// an unset source range is sent as "any address"
+	in.Sources = IPRange{}
+	b, err = in.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"srcIpStart":"0.0.0.0","srcIpEnd":"255.255.255.255"`)
+}
+
+func TestAddInboundRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/Inbound/Rules": inboundRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.AddInboundRule(ctx, InboundRule{Name: "bad"})
+	require.Error(t, err)
+
+	rule := InboundRule{
+		Enable:   true,
+		Name:     "telnet",
+		Protocol: "BOTH",
+		Action:   FirewallDeny,
+		Ports:    PortRange{23, 23},
+	}
+
+	// duplicates the "no telnet" rule
+	_, err = d.AddInboundRule(ctx, rule)
+	require.ErrorContains(t, err, "duplicates")
+	assert.Empty(t, writes())
+
+	rule.Name = "web"
+	rule.Action = FirewallAllow
+	rule.Protocol = "TCP"
+	rule.Ports = PortRange{443, 443}
+
+	p, err := d.AddInboundRule(ctx, rule)
+	require.NoError(t, err)
+	assert.Len(t, p.Rules, 2)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/Inbound/Rules", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.Equal(t, "csrf-token", w[0].CSRF)
+	assert.JSONEq(t, `{"id":"0","ruleName":"web","protocol":"TCP","action":"Allow",
+		"portStart":"443","portEnd":"443",
+		"srcIpStart":"0.0.0.0","srcIpEnd":"255.255.255.255","ruleOnOff":"ON"}`, w[0].Model)
+}
+
+func TestUpdateInboundRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/Inbound/Rules": inboundRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.SetInboundRuleEnabled(ctx, 42, false)
+	require.Error(t, err)
+
+	_, err = d.SetInboundRuleEnabled(ctx, 1, false)
+	require.NoError(t, err)
+
+	// changing rule 2 to match rule 1 would duplicate it
+	_, err = d.UpdateInboundRule(ctx, InboundRule{
+		ID: 2, Name: "SSH too", Protocol: "TCP", Action: FirewallAllow, Ports: PortRange{22, 22},
+		Sources: IPRange{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.254")},
+	})
+	require.ErrorContains(t, err, "duplicates")
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/Inbound/Rules/1", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+
+	rule := InboundRule{}
+	require.NoError(t, rule.UnmarshalJSON([]byte(w[0].Model)))
+	assert.False(t, rule.Enable)
+	assert.Equal(t, "SSH", rule.Name)
+	assert.Equal(t, PortRange{22, 22}, rule.Ports)
+}
+
+func TestDeleteInboundRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/Inbound/Rules": inboundRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.DeleteInboundRule(ctx, 42)
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, err = d.DeleteInboundRule(ctx, 2)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/Inbound/Rules/2", w[0].Path)
+	assert.Equal(t, http.MethodDelete, w[0].Method)
+}
+
+func TestSetInboundEnabled(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/Inbound/Status": `{"allRulesOnOff":"ON"}`,
+	})
+	d := testCableModem(srv)
+
+	_, err := d.SetInboundEnabled(context.Background(), false)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/Inbound/Status", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"allRulesOnOff":"OFF"}`, w[0].Model)
+}
+
+const deviceFilterRulesBody = `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","hostName":"tablet","macAddr":"de:ad:be:ef:ca:fe","ruleOnOff":"ON",
+		"days":"Mon,Tue,Wed,Thu,Fri","allDay":"OFF","startTime":"21:00","endTime":"23:59"}
+	]}`
+
+func TestDeviceFilterRuleMarshalJSON(t *testing.T) {
+	in := DeviceFilterRule{
+		ID: 1, Enable: true,
+		Name:    "tablet",
+		MacAddr: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe},
+		Schedule: Schedule{
+			Days:  []time.Weekday{time.Saturday, time.Sunday},
+			Start: 7*time.Hour + 30*time.Minute,
+			End:   9 * time.Hour,
+		},
+	}
+
+	b, err := in.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","hostName":"tablet","macAddr":"de:ad:be:ef:ca:fe","ruleOnOff":"ON",
+		"days":"Sat,Sun","allDay":"OFF","startTime":"07:30","endTime":"09:00"}`, string(b))
+
+	out := DeviceFilterRule{}
+	require.NoError(t, out.UnmarshalJSON(b))
+	assert.Equal(t, in, out)
+}
+
+func TestAddDeviceFilterRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/DeviceFilter/Rules": deviceFilterRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.AddDeviceFilterRule(ctx, DeviceFilterRule{Name: "bad", Schedule: Always})
+	require.Error(t, err)
+
+	rule := DeviceFilterRule{
+		Name:     "tablet again",
+		MacAddr:  net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe},
+		Schedule: Always,
+		Enable:   true,
+	}
+
+	_, err = d.AddDeviceFilterRule(ctx, rule)
+	require.ErrorContains(t, err, "already in the device filter")
+	assert.Empty(t, writes())
+
+	rule.Name = "console"
+	rule.MacAddr = net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01}
+
+	_, err = d.AddDeviceFilterRule(ctx, rule)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/DeviceFilter/Rules", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.JSONEq(t, `{"id":"0","hostName":"console","macAddr":"ba:dd:ca:fe:00:01","ruleOnOff":"ON",
+		"days":"Sun,Mon,Tue,Wed,Thu,Fri,Sat","allDay":"ON","startTime":"00:00","endTime":"00:00"}`, w[0].Model)
+}
+
+func TestBlockDevice(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/DeviceFilter/Rules": deviceFilterRulesBody,
+		"/Firewall/DeviceFilter/Type":  `{"errCode":"000","errMsg":"","blockType":"Disabled"}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	// an existing rule is replaced, keeping its name
+	_, err := d.BlockDevice(ctx, net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe}, "", Schedule{})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, "/Firewall/DeviceFilter/Rules/1", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+
+	rule := DeviceFilterRule{}
+	require.NoError(t, rule.UnmarshalJSON([]byte(w[0].Model)))
+	assert.Equal(t, "tablet", rule.Name)
+	assert.Equal(t, Always, rule.Schedule)
+
+	// the disabled filter is switched to block mode
+	assert.Equal(t, "/Firewall/DeviceFilter/Type", w[1].Path)
+	assert.JSONEq(t, `{"blockType":"Block Listed"}`, w[1].Model)
+
+	_, err = d.BlockDevice(ctx, net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01}, "console",
+		Schedule{Days: []time.Weekday{time.Monday}, Start: 2 * time.Hour, End: time.Hour})
+	require.Error(t, err)
+	assert.Len(t, writes(), 2)
+}
+
+func TestBlockDevice_AllowListed(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/DeviceFilter/Type": `{"errCode":"000","errMsg":"","blockType":"Allow Listed"}`,
+	})
+	d := testCableModem(srv)
+
+	_, err := d.BlockDevice(context.Background(), net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01}, "console", Always)
+	require.ErrorContains(t, err, "Allow Listed")
+	assert.Empty(t, writes())
+}
+
+func TestUnblockDevice(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/DeviceFilter/Rules": deviceFilterRulesBody,
+		"/Firewall/DeviceFilter/Type":  `{"errCode":"000","errMsg":"","blockType":"Block Listed"}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.UnblockDevice(ctx, net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01})
+	require.ErrorContains(t, err, "not in the device filter")
+	assert.Empty(t, writes())
+
+	_, err = d.UnblockDevice(ctx, net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/DeviceFilter/Rules/1", w[0].Path)
+	assert.Equal(t, http.MethodDelete, w[0].Method)
+}
+
+func TestUnblockDevice_AllowListed(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/DeviceFilter/Type": `{"errCode":"000","errMsg":"","blockType":"Allow Listed"}`,
+	})
+	d := testCableModem(srv)
+
+	_, err := d.UnblockDevice(context.Background(), net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe})
+	require.ErrorContains(t, err, "Allow Listed")
+	assert.Empty(t, writes())
+}
+
+func TestSetDeviceFilterType(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/DeviceFilter/Type": `{"errCode":"000","errMsg":"","blockType":"Disabled"}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.SetDeviceFilterType(ctx, "Sometimes")
+	require.Error(t, err)
+
+	_, err = d.SetDeviceFilterType(ctx, DeviceFilterAllow)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.JSONEq(t, `{"blockType":"Allow Listed"}`, w[0].Model)
+}
+
+const keywordFilterRulesBody = `{"errCode":"000","errMsg":"","Rules_List":[
+	{"id":"1","keyword":"casino","ruleOnOff":"ON"}
+]}`
+
+func TestKeywordFilterRuleValidate(t *testing.T) {
+	assert.NoError(t, KeywordFilterRule{Keyword: "casino"}.Validate())
+	assert.Error(t, KeywordFilterRule{}.Validate())
+	assert.Error(t, KeywordFilterRule{Keyword: "online casino"}.Validate())
+}
+
+func TestAddKeywordFilterRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/KeywordFilter/Rules": keywordFilterRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.AddKeywordFilterRule(ctx, KeywordFilterRule{Keyword: "CASINO", Enable: true})
+	require.ErrorContains(t, err, "already filtered")
+	assert.Empty(t, writes())
+
+	_, err = d.AddKeywordFilterRule(ctx, KeywordFilterRule{ID: 7, Keyword: "poker", Enable: true})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/KeywordFilter/Rules", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.JSONEq(t, `{"id":"0","keyword":"poker","ruleOnOff":"ON"}`, w[0].Model)
+}
+
+func TestUpdateKeywordFilterRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/KeywordFilter/Rules": keywordFilterRulesBody})
+	d := testCableModem(srv)
+
+	_, err := d.UpdateKeywordFilterRule(context.Background(), KeywordFilterRule{ID: 1, Keyword: "casino"})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/KeywordFilter/Rules/1", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"id":"1","keyword":"casino","ruleOnOff":"OFF"}`, w[0].Model)
+}
+
+func TestDeleteKeywordFilterRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/KeywordFilter/Rules": keywordFilterRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.DeleteKeywordFilterRule(ctx, 2)
+	require.ErrorContains(t, err, "no keyword filter rule with ID 2")
+
+	_, err = d.DeleteKeywordFilterRule(ctx, 1)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/KeywordFilter/Rules/1", w[0].Path)
+	assert.Equal(t, http.MethodDelete, w[0].Method)
+}
+
+const serviceFilterRulesBody = `{"errCode":"000","errMsg":"","Rules_List":[
+	{"id":"1","serviceName":"IRC","protocol":"TCP","portStart":"6660","portEnd":"6669",
+	"ruleOnOff":"ON","days":"Sun,Mon,Tue,Wed,Thu,Fri,Sat","allDay":"ON","startTime":"00:00","endTime":"00:00"}
+]}`
+
+func TestServiceFilterRuleValidate(t *testing.T) {
+	rule := ServiceFilterRule{Name: "IRC", Protocol: "TCP", Ports: PortRange{6660, 6669}, Schedule: Always}
+	assert.NoError(t, rule.Validate())
+
+	bad := rule
+	bad.Name = ""
+	assert.Error(t, bad.Validate())
+
+	bad = rule
+	bad.Protocol = "ICMP"
+	assert.Error(t, bad.Validate())
+
+	bad = rule
+	bad.Ports = PortRange{6669, 6660}
+	assert.Error(t, bad.Validate())
+
+	bad = rule
+	bad.Schedule = Schedule{}
+	assert.Error(t, bad.Validate())
+}
+
+func TestAddServiceFilterRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Firewall/ServiceFilter/Rules": serviceFilterRulesBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	rule := ServiceFilterRule{
+		Name:     "IRC",
+		Protocol: "TCP",
+		Ports:    PortRange{6697, 6697},
+		Schedule: Schedule{Days: []time.Weekday{time.Saturday}, Start: 9 * time.Hour, End: 17 * time.Hour},
+		Enable:   true,
+	}
+
+	_, err := d.AddServiceFilterRule(ctx, rule)
+	require.ErrorContains(t, err, "already a service filter rule")
+	assert.Empty(t, writes())
+
+	rule.Name = "IRC over TLS"
+
+	_, err = d.AddServiceFilterRule(ctx, rule)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Firewall/ServiceFilter/Rules", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.JSONEq(t, `{"id":"0","serviceName":"IRC over TLS","protocol":"TCP","portStart":"6697","portEnd":"6697",
+		"ruleOnOff":"ON","days":"Sat","allDay":"OFF","startTime":"09:00","endTime":"17:00"}`, w[0].Model)
+}
+
+func TestUpdateRules_UnknownID(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/Inbound/Rules":       inboundRulesBody,
+		"/Firewall/DeviceFilter/Rules":  deviceFilterRulesBody,
+		"/Firewall/KeywordFilter/Rules": keywordFilterRulesBody,
+		"/Firewall/ServiceFilter/Rules": serviceFilterRulesBody,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.UpdateInboundRule(ctx, InboundRule{
+		Name: "web", Protocol: "TCP", Action: FirewallAllow, Ports: PortRange{443, 443},
+	})
+	require.ErrorContains(t, err, "no inbound rule with ID 0")
+
+	_, err = d.UpdateDeviceFilterRule(ctx, DeviceFilterRule{
+		Name: "console", MacAddr: net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01}, Schedule: Always,
+	})
+	require.ErrorContains(t, err, "no device filter rule with ID 0")
+
+	_, err = d.UpdateKeywordFilterRule(ctx, KeywordFilterRule{Keyword: "poker"})
+	require.ErrorContains(t, err, "no keyword filter rule with ID 0")
+
+	_, err = d.UpdateServiceFilterRule(ctx, ServiceFilterRule{
+		Name: "IRC over TLS", Protocol: "TCP", Ports: PortRange{6697, 6697}, Schedule: Always,
+	})
+	require.ErrorContains(t, err, "no service filter rule with ID 0")
+
+	assert.Empty(t, writes())
+}
+
+func TestSetKeywordAndServiceFilterEnabled(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/KeywordFilter/Status": `{"errCode":"000","errMsg":"","allRulesOnOff":"OFF"}`,
+		"/Firewall/ServiceFilter/Status": `{"errCode":"000","errMsg":"","allRulesOnOff":"ON"}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.SetKeywordFilterEnabled(ctx, false)
+	require.NoError(t, err)
+
+	_, err = d.SetServiceFilterEnabled(ctx, true)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, "/Firewall/KeywordFilter/Status", w[0].Path)
+	assert.JSONEq(t, `{"allRulesOnOff":"OFF"}`, w[0].Model)
+	assert.Equal(t, "/Firewall/ServiceFilter/Status", w[1].Path)
+	assert.JSONEq(t, `{"allRulesOnOff":"ON"}`, w[1].Model)
+}
+
+func TestFilterTrust(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"3","hostName":"laptop","macAddr":"de:ad:be:ef:ca:fe"}
+	]}`
+
+	srv, writes := writeServer(t, map[string]string{
+		"/Firewall/KeywordFilter/TrustRules": body,
+		"/Firewall/ServiceFilter/TrustRules": body,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	laptop := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe}
+	tablet := net.HardwareAddr{0xba, 0xdd, 0xca, 0xfe, 0x00, 0x01}
+
+	_, err := d.AddKeywordFilterTrust(ctx, TrustRule{Name: "laptop", MacAddr: laptop})
+	require.ErrorContains(t, err, "already trusted")
+
+	_, err = d.AddKeywordFilterTrust(ctx, TrustRule{Name: "tablet"})
+	require.Error(t, err)
+
+	_, err = d.DeleteServiceFilterTrust(ctx, tablet)
+	require.ErrorContains(t, err, "not a trusted device")
+	assert.Empty(t, writes())
+
+	_, err = d.AddKeywordFilterTrust(ctx, TrustRule{Name: "tablet", MacAddr: tablet})
+	require.NoError(t, err)
+
+	_, err = d.DeleteServiceFilterTrust(ctx, laptop)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, "/Firewall/KeywordFilter/TrustRules", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.JSONEq(t, `{"id":"0","hostName":"tablet","macAddr":"ba:dd:ca:fe:00:01"}`, w[0].Model)
+	assert.Equal(t, "/Firewall/ServiceFilter/TrustRules/3", w[1].Path)
+	assert.Equal(t, http.MethodDelete, w[1].Method)
+}
@@ -0,0 +1,276 @@
+package hitron
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirewallDeviceFilterRules(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","hostName":"tablet","macAddr":"de:ad:be:ef:ca:fe","ruleOnOff":"ON",
+		"days":"Mon,Tue,Wed,Thu,Fri","allDay":"OFF","startTime":"21:00","endTime":"23:59"},
+		{"id":"2","hostName":"console","macAddr":"13:37:be:ef:ca:fe","ruleOnOff":"OFF",
+		"days":"Sat,Sun","allDay":"ON","startTime":"","endTime":""}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallDeviceFilterRules(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallDeviceFilterRules{
+		Error: NoError,
+		Rules: []DeviceFilterRule{
+			{
+				ID:      1,
+				Name:    "tablet",
+				MacAddr: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe},
+				Enable:  true,
+				Schedule: Schedule{
+					Days: []time.Weekday{
+						time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+					},
+					Start: 21 * time.Hour,
+					End:   23*time.Hour + 59*time.Minute,
+				},
+			},
+			{
+				ID:      2,
+				Name:    "console",
+				MacAddr: net.HardwareAddr{0x13, 0x37, 0xbe, 0xef, 0xca, 0xfe},
+				Schedule: Schedule{
+					Days:   []time.Weekday{time.Saturday, time.Sunday},
+					AllDay: true,
+				},
+			},
+		},
+	}, p)
+}
+
+func TestFirewallDeviceFilterRules_InvalidSchedule(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","hostName":"tablet","macAddr":"de:ad:be:ef:ca:fe","ruleOnOff":"ON",
+		"days":"Someday","allDay":"ON"}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	_, err := d.FirewallDeviceFilterRules(context.Background())
+	assert.ErrorContains(t, err, `invalid day "Someday"`)
+}
+
+func TestFirewallDeviceFilterType(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","blockType":"Block Listed"}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallDeviceFilterType(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallDeviceFilterType{Error: NoError, BlockType: DeviceFilterBlock}, p)
+}
+
+func TestFirewallInboundRules(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","ruleName":"SSH","protocol":"TCP","action":"Allow",
+		"portStart":"22","portEnd":"22",
+		"srcIpStart":"203.0.113.1","srcIpEnd":"203.0.113.254","ruleOnOff":"ON"}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallInboundRules(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallInboundRules{
+		Error: NoError,
+		Rules: []InboundRule{
+			{
+				ID:       1,
+				Name:     "SSH",
+				Protocol: "TCP",
+				Action:   FirewallAllow,
+				Ports:    PortRange{22, 22},
+				Sources:  IPRange{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.254")},
+				Enable:   true,
+			},
+		},
+	}, p)
+}
+
+func TestFirewallStatus(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","allRulesOnOff":"ON"}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	inbound, err := d.FirewallInboundStatus(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, FirewallInboundStatus{Error: NoError, Enable: true}, inbound)
+
+	keyword, err := d.FirewallKeywordFilterStatus(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, FirewallKeywordFilterStatus{Error: NoError, Enable: true}, keyword)
+
+	service, err := d.FirewallServiceFilterStatus(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, FirewallServiceFilterStatus{Error: NoError, Enable: true}, service)
+}
+
+func TestFirewallKeywordFilterRules(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","keyword":"casino","ruleOnOff":"ON"},
+		{"id":"2","keyword":"poker","ruleOnOff":"OFF"}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallKeywordFilterRules(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallKeywordFilterRules{
+		Error: NoError,
+		Rules: []KeywordFilterRule{
+			{ID: 1, Keyword: "casino", Enable: true},
+			{ID: 2, Keyword: "poker"},
+		},
+	}, p)
+}
+
+func TestFirewallTrustRules(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","hostName":"laptop","macAddr":"13:37:be:ef:ca:fe"}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	expected := []TrustRule{
+		{ID: 1, Name: "laptop", MacAddr: net.HardwareAddr{0x13, 0x37, 0xbe, 0xef, 0xca, 0xfe}},
+	}
+
+	keyword, err := d.FirewallKeywordFilterTrustRules(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, FirewallKeywordFilterTrustRules{Error: NoError, Rules: expected}, keyword)
+
+	service, err := d.FirewallServiceFilterTrustRules(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, FirewallServiceFilterTrustRules{Error: NoError, Rules: expected}, service)
+}
+
+func TestFirewallLevel(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","firewallLevel":"Low"}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallLevel(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallLevel{Error: NoError, Level: FirewallLevelLow}, p)
+}
+
+func TestFirewallServiceFilterRules(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","serviceName":"IRC","protocol":"TCP","portStart":"6660","portEnd":"6669",
+		"ruleOnOff":"ON","days":"Sun,Sat","allDay":"OFF","startTime":"08:30","endTime":"17:00"}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallServiceFilterRules(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallServiceFilterRules{
+		Error: NoError,
+		Rules: []ServiceFilterRule{
+			{
+				ID:       1,
+				Name:     "IRC",
+				Protocol: "TCP",
+				Ports:    PortRange{6660, 6669},
+				Enable:   true,
+				Schedule: Schedule{
+					Days:  []time.Weekday{time.Sunday, time.Saturday},
+					Start: 8*time.Hour + 30*time.Minute,
+					End:   17 * time.Hour,
+				},
+			},
+		},
+	}, p)
+}
+
+func TestFirewallVPN(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","ipsecOnOff":"ON","pptpOnOff":"OFF","l2tpOnOff":"ON"}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.FirewallVPN(context.Background())
+	require.NoError(t, err)
+
+	assert.EqualValues(t, FirewallVPN{Error: NoError, IPSec: true, L2TP: true}, p)
+}
+
+func TestParseSchedule(t *testing.T) {
+	s, err := ParseSchedule("", "")
+	require.NoError(t, err)
+	assert.Equal(t, Always, s)
+
+	s, err = ParseSchedule("Mon-Fri", "21:00-23:59")
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{
+		Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Start: 21 * time.Hour,
+		End:   23*time.Hour + 59*time.Minute,
+	}, s)
+	assert.Equal(t, "Mon,Tue,Wed,Thu,Fri 21:00-23:59", s.String())
+
+	s, err = ParseSchedule("fri-mon, wed", "")
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{
+		Days:   []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday, time.Wednesday},
+		AllDay: true,
+	}, s)
+	assert.Equal(t, "Fri,Sat,Sun,Mon,Wed all day", s.String())
+
+	_, err = ParseSchedule("Mon,Funday", "")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("Mon,Mon", "")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("Mon", "21:00")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("Mon", "21:00-25:00")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("Mon", "21:00-07:00")
+	assert.ErrorContains(t, err, "split it into 21:00-23:59 and 00:00-07:00")
+}
+
+func TestScheduleValidate(t *testing.T) {
+	assert.NoError(t, Always.Validate())
+	assert.Error(t, Schedule{AllDay: true}.Validate())
+	assert.Error(t, Schedule{Days: []time.Weekday{7}, AllDay: true}.Validate())
+	assert.Error(t, Schedule{Days: []time.Weekday{time.Monday}, Start: time.Hour, End: time.Hour}.Validate())
+	assert.Error(t, Schedule{Days: []time.Weekday{time.Monday}, Start: time.Second, End: time.Hour}.Validate())
+	assert.Error(t, Schedule{Days: []time.Weekday{time.Monday}, End: 24 * time.Hour}.Validate())
+	assert.NoError(t, Schedule{Days: []time.Weekday{time.Monday}, End: time.Minute}.Validate())
+}
@@ -0,0 +1,857 @@
+package hitron
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FirewallDeviceFilterRules - devices which are blocked (or allowed, depending
+// on the filter type) from reaching the Internet, optionally only during a
+// schedule
+type FirewallDeviceFilterRules struct {
+	Error
+	Rules []DeviceFilterRule `json:"Rules_List"`
+}
+
+// DeviceFilterRule -
+type DeviceFilterRule struct {
+	Name     string
+	MacAddr  net.HardwareAddr
+	Schedule Schedule
+	ID       int
+	Enable   bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *DeviceFilterRule) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID        string
+		HostName  string
+		MacAddr   string
+		RuleOnOff string
+		Days      string
+		AllDay    string
+		StartTime string
+		EndTime   string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal DeviceFilterRule %q: %w", string(b), err)
+	}
+
+	s.ID, _ = strconv.Atoi(raw.ID)
+	s.Name = raw.HostName
+	s.MacAddr, _ = net.ParseMAC(raw.MacAddr)
+	s.Enable = raw.RuleOnOff == on
+
+	s.Schedule, err = parseSchedule(raw.Days, raw.AllDay, raw.StartTime, raw.EndTime)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal DeviceFilterRule %q: %w", string(b), err)
+	}
+
+	return nil
+}
+
+// Validate checks that the rule can be sent to the modem
+func (s DeviceFilterRule) Validate() error {
+	if err := validateMAC(s.MacAddr); err != nil {
+		return fmt.Errorf("invalid device filter rule %q: %w", s.Name, err)
+	}
+
+	if err := s.Schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid device filter rule %q: %w", s.Name, err)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s DeviceFilterRule) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		ID        string `json:"id"`
+		HostName  string `json:"hostName"`
+		MacAddr   string `json:"macAddr"`
+		RuleOnOff string `json:"ruleOnOff"`
+		Days      string `json:"days"`
+		AllDay    string `json:"allDay"`
+		StartTime string `json:"startTime"`
+		EndTime   string `json:"endTime"`
+	}{
+		ID:        strconv.Itoa(s.ID),
+		HostName:  s.Name,
+		MacAddr:   s.MacAddr.String(),
+		RuleOnOff: onOff(s.Enable),
+		Days:      formatWeekdays(s.Schedule.Days),
+		AllDay:    onOff(s.Schedule.AllDay),
+		StartTime: formatTimeOfDay(s.Schedule.Start),
+		EndTime:   formatTimeOfDay(s.Schedule.End),
+	}
+
+	return json.Marshal(raw)
+}
+
+// findRule returns the rule with the given MAC address, if there is one
+func (s FirewallDeviceFilterRules) findRule(mac net.HardwareAddr) (DeviceFilterRule, bool) {
+	for _, r := range s.Rules {
+		if bytes.Equal(r.MacAddr, mac) {
+			return r, true
+		}
+	}
+
+	return DeviceFilterRule{}, false
+}
+
+// FirewallDeviceFilterType -
+type FirewallDeviceFilterType struct {
+	Error
+	BlockType string
+}
+
+// Device filter modes (BlockType values)
+const (
+	DeviceFilterDisabled = "Disabled"
+	DeviceFilterAllow    = "Allow Listed" // only listed devices may reach the Internet
+	DeviceFilterBlock    = "Block Listed" // listed devices may not reach the Internet
+)
+
+// FirewallInboundRules - rules allowing or denying traffic from the WAN
+type FirewallInboundRules struct {
+	Error
+	Rules []InboundRule `json:"Rules_List"`
+}
+
+// InboundRule -
+type InboundRule struct {
+	Name     string
+	Protocol string // TCP, UDP, BOTH
+	Action   string // Allow, Deny
+	Sources  IPRange
+	Ports    PortRange
+	ID       int
+	Enable   bool
+}
+
+// Inbound rule actions
+const (
+	FirewallAllow = "Allow"
+	FirewallDeny  = "Deny"
+)
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *InboundRule) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID                 string
+		RuleName           string
+		Protocol           string
+		Action             string
+		PortStart, PortEnd string
+		SrcIPStart         net.IP `json:"srcIpStart"`
+		SrcIPEnd           net.IP `json:"srcIpEnd"`
+		RuleOnOff          string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal InboundRule %q: %w", string(b), err)
+	}
+
+	s.ID, _ = strconv.Atoi(raw.ID)
+	s.Name = raw.RuleName
+	s.Protocol = raw.Protocol
+	s.Action = raw.Action
+	s.Enable = raw.RuleOnOff == on
+
+	s.Ports = PortRange{parsePort(raw.PortStart), parsePort(raw.PortEnd)}
+	s.Sources = IPRange{raw.SrcIPStart, raw.SrcIPEnd}
+
+	return nil
+}
+
+// Validate checks that the rule can be sent to the modem
+func (s InboundRule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("invalid inbound rule: Name must be set")
+	}
+
+	if err := validateProtocol(s.Protocol); err != nil {
+		return fmt.Errorf("invalid inbound rule %q: %w", s.Name, err)
+	}
+
+	if s.Action != FirewallAllow && s.Action != FirewallDeny {
+		return fmt.Errorf("invalid inbound rule %q: action %q must be one of %s or %s",
+			s.Name, s.Action, FirewallAllow, FirewallDeny)
+	}
+
+	if err := s.Ports.Validate(); err != nil {
+		return fmt.Errorf("invalid inbound rule %q: ports: %w", s.Name, err)
+	}
+
+	if err := s.Sources.Validate(); err != nil {
+		return fmt.Errorf("invalid inbound rule %q: sources: %w", s.Name, err)
+	}
+
+	return nil
+}
+
+// duplicates returns an error if any other rule matches exactly the same
+// traffic (protocol, ports, and sources) with the same action. Names and
+// on/off state aren't considered, since they don't change what the rule does,
+// and protocols are compared without regard to case.
+func (s InboundRule) duplicates(rules []InboundRule) error {
+	for _, r := range rules {
+		if r.ID == s.ID {
+			continue
+		}
+
+		if strings.EqualFold(r.Protocol, s.Protocol) && r.Action == s.Action && r.Ports == s.Ports &&
+			r.Sources.orAny().Equal(s.Sources.orAny()) {
+			return fmt.Errorf("inbound rule %q duplicates rule %q (%s %s %s from %s)",
+				s.Name, r.Name, r.Action, r.Protocol, r.Ports, r.Sources.orAny())
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s InboundRule) MarshalJSON() ([]byte, error) {
+	src := s.Sources.orAny()
+
+	raw := struct {
+		ID         string `json:"id"`
+		RuleName   string `json:"ruleName"`
+		Protocol   string `json:"protocol"`
+		Action     string `json:"action"`
+		PortStart  string `json:"portStart"`
+		PortEnd    string `json:"portEnd"`
+		SrcIPStart string `json:"srcIpStart"`
+		SrcIPEnd   string `json:"srcIpEnd"`
+		RuleOnOff  string `json:"ruleOnOff"`
+	}{
+		ID:         strconv.Itoa(s.ID),
+		RuleName:   s.Name,
+		Protocol:   s.Protocol,
+		Action:     s.Action,
+		PortStart:  strconv.Itoa(s.Ports.Start),
+		PortEnd:    strconv.Itoa(s.Ports.End),
+		SrcIPStart: src.Start.String(),
+		SrcIPEnd:   src.End.String(),
+		RuleOnOff:  onOff(s.Enable),
+	}
+
+	return json.Marshal(raw)
+}
+
+// FirewallInboundStatus -
+type FirewallInboundStatus struct {
+	Error
+	Enable bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *FirewallInboundStatus) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Error
+
+		AllRulesOnOff string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal FirewallInboundStatus %q: %w", string(b), err)
+	}
+
+	s.Error = raw.Error
+	s.Enable = raw.AllRulesOnOff == on
+
+	return nil
+}
+
+// FirewallKeywordFilterRules - URL keywords which are blocked
+type FirewallKeywordFilterRules struct {
+	Error
+	Rules []KeywordFilterRule `json:"Rules_List"`
+}
+
+// KeywordFilterRule -
+type KeywordFilterRule struct {
+	Keyword string
+	ID      int
+	Enable  bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *KeywordFilterRule) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID        string
+		Keyword   string
+		RuleOnOff string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal KeywordFilterRule %q: %w", string(b), err)
+	}
+
+	s.ID, _ = strconv.Atoi(raw.ID)
+	s.Keyword = raw.Keyword
+	s.Enable = raw.RuleOnOff == on
+
+	return nil
+}
+
+// Validate checks that the rule can be sent to the modem
+func (s KeywordFilterRule) Validate() error {
+	if s.Keyword == "" {
+		return fmt.Errorf("invalid keyword filter rule: Keyword must be set")
+	}
+
+	if strings.ContainsFunc(s.Keyword, unicode.IsSpace) {
+		return fmt.Errorf("invalid keyword filter rule %q: keywords must not contain spaces", s.Keyword)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s KeywordFilterRule) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		ID        string `json:"id"`
+		Keyword   string `json:"keyword"`
+		RuleOnOff string `json:"ruleOnOff"`
+	}{
+		ID:        strconv.Itoa(s.ID),
+		Keyword:   s.Keyword,
+		RuleOnOff: onOff(s.Enable),
+	}
+
+	return json.Marshal(raw)
+}
+
+// findRule returns the rule for the given keyword (ignoring case), if there is
+// one
+func (s FirewallKeywordFilterRules) findRule(keyword string) (KeywordFilterRule, bool) {
+	for _, r := range s.Rules {
+		if strings.EqualFold(r.Keyword, keyword) {
+			return r, true
+		}
+	}
+
+	return KeywordFilterRule{}, false
+}
+
+// FirewallKeywordFilterStatus -
+type FirewallKeywordFilterStatus struct {
+	Error
+	Enable bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *FirewallKeywordFilterStatus) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Error
+
+		AllRulesOnOff string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal FirewallKeywordFilterStatus %q: %w", string(b), err)
+	}
+
+	s.Error = raw.Error
+	s.Enable = raw.AllRulesOnOff == on
+
+	return nil
+}
+
+// FirewallKeywordFilterTrustRules - devices exempt from keyword filtering
+type FirewallKeywordFilterTrustRules struct {
+	Error
+	Rules []TrustRule `json:"Rules_List"`
+}
+
+// TrustRule - a trusted device, which is exempt from a filter
+type TrustRule struct {
+	Name    string
+	MacAddr net.HardwareAddr
+	ID      int
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *TrustRule) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID       string
+		HostName string
+		MacAddr  string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal TrustRule %q: %w", string(b), err)
+	}
+
+	s.ID, _ = strconv.Atoi(raw.ID)
+	s.Name = raw.HostName
+	s.MacAddr, _ = net.ParseMAC(raw.MacAddr)
+
+	return nil
+}
+
+// Validate checks that the rule can be sent to the modem
+func (s TrustRule) Validate() error {
+	if err := validateMAC(s.MacAddr); err != nil {
+		return fmt.Errorf("invalid trusted device %q: %w", s.Name, err)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s TrustRule) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		ID       string `json:"id"`
+		HostName string `json:"hostName"`
+		MacAddr  string `json:"macAddr"`
+	}{
+		ID:       strconv.Itoa(s.ID),
+		HostName: s.Name,
+		MacAddr:  s.MacAddr.String(),
+	}
+
+	return json.Marshal(raw)
+}
+
+// findTrustRule returns the rule for the given MAC address, if there is one
+func findTrustRule(rules []TrustRule, mac net.HardwareAddr) (TrustRule, bool) {
+	for _, r := range rules {
+		if bytes.Equal(r.MacAddr, mac) {
+			return r, true
+		}
+	}
+
+	return TrustRule{}, false
+}
+
+// FirewallLevel -
+type FirewallLevel struct {
+	Error
+	Level string
+}
+
+// Firewall protection levels
+const (
+	FirewallLevelOff    = "Off"
+	FirewallLevelLow    = "Low"
+	FirewallLevelMedium = "Medium"
+	FirewallLevelHigh   = "High"
+)
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *FirewallLevel) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Error
+
+		FirewallLevel string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal FirewallLevel %q: %w", string(b), err)
+	}
+
+	s.Error = raw.Error
+	s.Level = raw.FirewallLevel
+
+	return nil
+}
+
+// FirewallServiceFilterRules - services (by port) which are blocked,
+// optionally only during a schedule
+type FirewallServiceFilterRules struct {
+	Error
+	Rules []ServiceFilterRule `json:"Rules_List"`
+}
+
+// ServiceFilterRule -
+type ServiceFilterRule struct {
+	Name     string
+	Protocol string // TCP, UDP, BOTH
+	Schedule Schedule
+	Ports    PortRange
+	ID       int
+	Enable   bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *ServiceFilterRule) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID                 string
+		ServiceName        string
+		Protocol           string
+		PortStart, PortEnd string
+		RuleOnOff          string
+		Days               string
+		AllDay             string
+		StartTime          string
+		EndTime            string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal ServiceFilterRule %q: %w", string(b), err)
+	}
+
+	s.ID, _ = strconv.Atoi(raw.ID)
+	s.Name = raw.ServiceName
+	s.Protocol = raw.Protocol
+	s.Enable = raw.RuleOnOff == on
+
+	s.Ports = PortRange{parsePort(raw.PortStart), parsePort(raw.PortEnd)}
+
+	s.Schedule, err = parseSchedule(raw.Days, raw.AllDay, raw.StartTime, raw.EndTime)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal ServiceFilterRule %q: %w", string(b), err)
+	}
+
+	return nil
+}
+
+// Validate checks that the rule can be sent to the modem
+func (s ServiceFilterRule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("invalid service filter rule: Name must be set")
+	}
+
+	if err := validateProtocol(s.Protocol); err != nil {
+		return fmt.Errorf("invalid service filter rule %q: %w", s.Name, err)
+	}
+
+	if err := s.Ports.Validate(); err != nil {
+		return fmt.Errorf("invalid service filter rule %q: ports: %w", s.Name, err)
+	}
+
+	if err := s.Schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid service filter rule %q: %w", s.Name, err)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s ServiceFilterRule) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		ID          string `json:"id"`
+		ServiceName string `json:"serviceName"`
+		Protocol    string `json:"protocol"`
+		PortStart   string `json:"portStart"`
+		PortEnd     string `json:"portEnd"`
+		RuleOnOff   string `json:"ruleOnOff"`
+		Days        string `json:"days"`
+		AllDay      string `json:"allDay"`
+		StartTime   string `json:"startTime"`
+		EndTime     string `json:"endTime"`
+	}{
+		ID:          strconv.Itoa(s.ID),
+		ServiceName: s.Name,
+		Protocol:    s.Protocol,
+		PortStart:   strconv.Itoa(s.Ports.Start),
+		PortEnd:     strconv.Itoa(s.Ports.End),
+		RuleOnOff:   onOff(s.Enable),
+		Days:        formatWeekdays(s.Schedule.Days),
+		AllDay:      onOff(s.Schedule.AllDay),
+		StartTime:   formatTimeOfDay(s.Schedule.Start),
+		EndTime:     formatTimeOfDay(s.Schedule.End),
+	}
+
+	return json.Marshal(raw)
+}
+
+// findRule returns the rule with the given name, if there is one
+func (s FirewallServiceFilterRules) findRule(name string) (ServiceFilterRule, bool) {
+	for _, r := range s.Rules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+
+	return ServiceFilterRule{}, false
+}
+
+// FirewallServiceFilterStatus -
+type FirewallServiceFilterStatus struct {
+	Error
+	Enable bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *FirewallServiceFilterStatus) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Error
+
+		AllRulesOnOff string
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal FirewallServiceFilterStatus %q: %w", string(b), err)
+	}
+
+	s.Error = raw.Error
+	s.Enable = raw.AllRulesOnOff == on
+
+	return nil
+}
+
+// FirewallServiceFilterTrustRules - devices exempt from service filtering
+type FirewallServiceFilterTrustRules struct {
+	Error
+	Rules []TrustRule `json:"Rules_List"`
+}
+
+// FirewallVPN - VPN passthrough settings
+type FirewallVPN struct {
+	Error
+	IPSec bool
+	PPTP  bool
+	L2TP  bool
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *FirewallVPN) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Error
+
+		IPSecOnOff string `json:"ipsecOnOff"`
+		PPTPOnOff  string `json:"pptpOnOff"`
+		L2TPOnOff  string `json:"l2tpOnOff"`
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal FirewallVPN %q: %w", string(b), err)
+	}
+
+	s.Error = raw.Error
+	s.IPSec = raw.IPSecOnOff == on
+	s.PPTP = raw.PPTPOnOff == on
+	s.L2TP = raw.L2TPOnOff == on
+
+	return nil
+}
+
+// Schedule - the days, and the time of day on those days, when a filter rule
+// is in effect. Start and End are offsets from midnight, and are ignored when
+// AllDay is set. The firmware doesn't document how windows that span midnight
+// are handled, so Start must be before End - an overnight window like
+// 21:00-07:00 must be split into 21:00-23:59 and 00:00-07:00 instead.
+type Schedule struct {
+	Days   []time.Weekday
+	Start  time.Duration
+	End    time.Duration
+	AllDay bool
+}
+
+// Always - a schedule covering every day, all day
+//
+//nolint:gochecknoglobals
+var Always = Schedule{
+	Days: []time.Weekday{
+		time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+		time.Thursday, time.Friday, time.Saturday,
+	},
+	AllDay: true,
+}
+
+// Validate checks that the schedule covers at least one day, and that the
+// start of the time window is before its end (windows can't span midnight)
+func (s Schedule) Validate() error {
+	if len(s.Days) == 0 {
+		return fmt.Errorf("invalid schedule: at least one day must be set")
+	}
+
+	seen := map[time.Weekday]bool{}
+
+	for _, d := range s.Days {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("invalid schedule: invalid day %d", d)
+		}
+
+		if seen[d] {
+			return fmt.Errorf("invalid schedule: %s is given more than once", d)
+		}
+
+		seen[d] = true
+	}
+
+	if s.AllDay {
+		return nil
+	}
+
+	for _, t := range []time.Duration{s.Start, s.End} {
+		if t < 0 || t >= 24*time.Hour || t%time.Minute != 0 {
+			return fmt.Errorf("invalid schedule: time %s must be a whole minute between 00:00 and 23:59", t)
+		}
+	}
+
+	if s.Start >= s.End {
+		return fmt.Errorf("invalid schedule: start %s must be before end %s - "+
+			"windows can't span midnight, so split it into %s-23:59 and 00:00-%s",
+			formatTimeOfDay(s.Start), formatTimeOfDay(s.End),
+			formatTimeOfDay(s.Start), formatTimeOfDay(s.End))
+	}
+
+	return nil
+}
+
+func (s Schedule) String() string {
+	if s.AllDay {
+		return formatWeekdays(s.Days) + " all day"
+	}
+
+	return formatWeekdays(s.Days) + " " + formatTimeOfDay(s.Start) + "-" + formatTimeOfDay(s.End)
+}
+
+// ParseSchedule parses a schedule from a list of days ("Mon,Wed", or a range
+// like "Mon-Fri" - every day if empty), and an HH:MM time window
+// ("21:00-23:59" - all day if empty). The window can't span midnight.
+func ParseSchedule(days, window string) (Schedule, error) {
+	sched := Schedule{}
+
+	if strings.TrimSpace(days) == "" {
+		sched.Days = slices.Clone(Always.Days)
+	}
+
+	for d := range strings.SplitSeq(days, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+
+		first, last, found := strings.Cut(d, "-")
+		if !found {
+			last = first
+		}
+
+		start, err := parseWeekday(strings.TrimSpace(first))
+		if err != nil {
+			return Schedule{}, err
+		}
+
+		end, err := parseWeekday(strings.TrimSpace(last))
+		if err != nil {
+			return Schedule{}, err
+		}
+
+		// ranges may wrap around the end of the week, like "Fri-Mon"
+		for wd := start; ; wd = (wd + 1) % 7 {
+			sched.Days = append(sched.Days, wd)
+
+			if wd == end {
+				break
+			}
+		}
+	}
+
+	if strings.TrimSpace(window) == "" {
+		sched.AllDay = true
+
+		return sched, sched.Validate()
+	}
+
+	start, end, found := strings.Cut(window, "-")
+	if !found {
+		return Schedule{}, fmt.Errorf("invalid time window %q: must be in the form HH:MM-HH:MM", window)
+	}
+
+	var err error
+
+	sched.Start, err = parseTimeOfDay(strings.TrimSpace(start))
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	sched.End, err = parseTimeOfDay(strings.TrimSpace(end))
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return sched, sched.Validate()
+}
+
+// parseSchedule parses the firmware's representation of a schedule: a
+// comma-separated list of abbreviated day names, and HH:MM start and end times
+func parseSchedule(days, allDay, start, end string) (sched Schedule, err error) {
+	sched.AllDay = allDay == on
+
+	for d := range strings.SplitSeq(days, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+
+		wd, err := parseWeekday(d)
+		if err != nil {
+			return Schedule{}, err
+		}
+
+		sched.Days = append(sched.Days, wd)
+	}
+
+	if sched.AllDay {
+		return sched, nil
+	}
+
+	sched.Start, err = parseTimeOfDay(start)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	sched.End, err = parseTimeOfDay(end)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return sched, nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid day %q", s)
+}
+
+func formatWeekdays(days []time.Weekday) string {
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = d.String()[:3]
+	}
+
+	return strings.Join(names, ",")
+}
+
+// parseTimeOfDay parses an HH:MM time into an offset from midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -1,17 +1,19 @@
 package hitron
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"net/http/cookiejar"
 	"net/http/httputil"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 //go:generate gomplate -c .=apilist.yaml -f methods.go.tmpl -o methods.go
@@ -20,7 +22,10 @@ import (
 type CableModem struct {
 	base        *url.URL
 	hc          *http.Client
+	onRelogin   func(ctx context.Context, cause, err error)
 	credentials credentials
+	loginMu     sync.Mutex
+	autoRelogin bool
 }
 
 // debugTransport - logs the request and response if debug is enabled
@@ -45,30 +50,36 @@ func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
-// New instantiates a default CableModem struct
-func New(host, username, password string) (*CableModem, error) {
-	u, err := url.Parse(fmt.Sprintf("http://%s/1/Device/", host))
-	if err != nil {
-		return nil, err
+// userAgentTransport - sets the User-Agent header on every request
+type userAgentTransport struct {
+	rt http.RoundTripper
+	ua string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.ua)
+
+	return t.rt.RoundTrip(req)
+}
+
+// New instantiates a default CableModem struct. The host may include a port
+// (e.g. "192.168.0.1:8080"). Behaviour can be customized with the given
+// Options.
+func New(host, username, password string, opts ...Option) (*CableModem, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	jar, err := cookiejar.New(nil)
+	u, err := url.Parse(o.baseURL(host))
 	if err != nil {
 		return nil, err
 	}
 
-	tr := http.DefaultTransport
-	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
-		tr = &debugTransport{tr}
-	}
-
-	client := &http.Client{
-		Jar: jar,
-		// Ignore redirects
-		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-		Transport: tr,
+	client, err := o.httpClient()
+	if err != nil {
+		return nil, err
 	}
 
 	creds := credentials{username, password}
@@ -77,6 +88,8 @@ func New(host, username, password string) (*CableModem, error) {
 		credentials: creds,
 		base:        u,
 		hc:          client,
+		autoRelogin: o.autoRelogin,
+		onRelogin:   o.onRelogin,
 	}, nil
 }
 
@@ -105,52 +118,164 @@ func (c *CableModem) sendRequest(ctx context.Context, method, path string, body,
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	u := c.url(path).String()
-
 	contentType := ""
 
-	var reqBody io.Reader
+	var reqBody []byte
 
 	switch b := body.(type) {
 	case io.Reader:
-		reqBody = b
+		// buffer the body so the request can be replayed after a re-login
+		rb, err := io.ReadAll(b)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		reqBody = rb
 	case url.Values:
 		contentType = "application/x-www-form-urlencoded"
-		reqBody = strings.NewReader(b.Encode())
+		reqBody = []byte(b.Encode())
 	default:
 		return fmt.Errorf("unsupported body type %T", body)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	var b []byte
+
+	err := c.withRelogin(ctx, func() (err error) {
+		b, err = c.do(ctx, method, path, contentType, reqBody)
+
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	return decodeResponse(b, o)
+}
+
+// decodeResponse - decodes a response body into o, surfacing non-zero error
+// codes from the body as an *APIError
+func decodeResponse(b []byte, o interface{}) error {
+	// check the error code before decoding the body fully, since error
+	// responses may lack the fields the response type expects
+	if _, ok := o.(apiErrorer); ok {
+		e := Error{}
+		if json.Unmarshal(b, &e) == nil && e.Err() != nil {
+			return e.Err()
+		}
+	}
+
+	err := json.Unmarshal(b, o)
+	if err != nil {
+		return fmt.Errorf("JSON decoding failed: %w", err)
+	}
+
+	if e, ok := o.(apiErrorer); ok {
+		return e.Err()
+	}
+
+	return nil
+}
+
+// sendModel - sends a CSRF-protected write request the same way the web UI
+// does: the JSON-encoded model is POSTed as a form, along with a fresh CSRF
+// token and (for methods other than POST) the intended method in "_method".
+// When the request is retried after a re-login, a new token is fetched, since
+// tokens belong to the session.
+func (c *CableModem) sendModel(ctx context.Context, method, path string, model interface{}) error {
+	return c.sendModelInto(ctx, method, path, model, &Error{})
+}
+
+// sendModelInto - like sendModel, but decodes the response into o
+func (c *CableModem) sendModelInto(ctx context.Context, method, path string, model, o interface{}) error {
+	mb, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal model: %w", err)
+	}
+
+	var b []byte
+
+	err = c.withRelogin(ctx, func() error {
+		csrf, err := c.UsersCSRF(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve CSRF token: %w", err)
+		}
+
+		form := url.Values{
+			"model": []string{string(mb)},
+			"csrf":  []string{csrf.CSRF},
+		}
+
+		if method != http.MethodPost {
+			form.Set("_method", method)
+		}
+
+		b, err = c.do(ctx, http.MethodPost, path, "application/x-www-form-urlencoded", []byte(form.Encode()))
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(b, o)
+}
+
+// withRelogin - calls fn, and if automatic re-login is enabled and fn failed
+// because the session expired, logs in again and calls fn once more
+func (c *CableModem) withRelogin(ctx context.Context, fn func() error) error {
+	err := fn()
+	if err != nil && c.autoRelogin && errors.Is(err, ErrUnauthorized) {
+		err = c.relogin(ctx, err)
+		if err != nil {
+			return err
+		}
+
+		err = fn()
+	}
+
+	return err
+}
+
+func (c *CableModem) newRequest(ctx context.Context, method, path, contentType string, body []byte) (*http.Request, error) {
+	u := c.url(path).String()
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
 	if contentType != "" {
 		req.Header.Set("Content-Type", contentType)
 	}
 
+	return req, nil
+}
+
+// do sends a single request and returns the response body. Unexpected
+// responses, including those indicating that the session is no longer valid,
+// are reported as a *StatusError.
+func (c *CableModem) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := c.newRequest(ctx, method, path, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := c.hc.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed with status %d: %s (Header: %v)", resp.StatusCode, string(b), resp.Header)
+		return nil, fmt.Errorf("failed to read body: %w", err)
 	}
 
-	err = json.Unmarshal(b, o)
-	if err != nil {
-		return fmt.Errorf("JSON decoding failed: %w", err)
+	if resp.StatusCode != http.StatusOK || sessionExpired(resp.StatusCode, b) {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: b, Header: resp.Header}
 	}
 
-	return nil
+	return b, nil
 }
 
 func atoi64(s string) int64 {
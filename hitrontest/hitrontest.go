@@ -0,0 +1,1156 @@
+// Package hitrontest provides a fake Hitron CODA-4x8x cable modem, for use in
+// integration tests and offline development.
+//
+// The fake modem enforces login sessions and CSRF tokens the same way the
+// real device does, serves every read-only endpoint from fixture JSON, and
+// accepts a subset of writes (reboot, log clearing, time, DNS, and DDNS
+// settings, DMZ host, port forwarding and triggering rules, inbound firewall
+// rules, device, keyword, and service filters and their trusted devices, SSID,
+// guest network, and radio settings, WiFi access control, WPS sessions,
+// configuration restore) which mutate its state.
+//
+// WPS sessions succeed after the status has been read WPSReads times.
+package hitrontest
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BasePath - the path under which the fake modem serves the API
+const BasePath = "/1/Device"
+
+// SessionCookie - the name of the cookie holding the session ID
+const SessionCookie = "userid"
+
+// DefaultBackup - the configuration file served from /Router/Backup until a
+// different one is restored
+const DefaultBackup = "hitrontest backup\n"
+
+//go:embed fixtures/*.json
+var fixtures embed.FS //nolint:gochecknoglobals
+
+// Write - a write request accepted by the fake modem
+type Write struct {
+	Path   string // the path, relative to BasePath
+	Method string // the method from the "_method" field, or POST
+	Model  string
+}
+
+// Server - a fake modem, listening on a local port
+type Server struct {
+	*httptest.Server
+
+	mux      *http.ServeMux
+	state    map[string]map[string]interface{}
+	sessions map[string]string // session ID -> CSRF token
+	username string
+	password string
+	backup   []byte
+	writes   []Write
+	reboots  int
+	wpsReads int // status reads since a WPS session started, or -1 if none is running
+	mu       sync.Mutex
+}
+
+// WPSReads - the number of times the WPS status is read before a session
+// succeeds
+const WPSReads = 3
+
+// NewServer starts a fake modem which accepts the given credentials. The
+// caller should call Close when finished, to shut it down.
+func NewServer(username, password string) *Server {
+	s := &Server{
+		state:    map[string]map[string]interface{}{},
+		sessions: map[string]string{},
+		username: username,
+		password: password,
+		backup:   []byte(DefaultBackup),
+		wpsReads: -1,
+	}
+
+	if err := s.loadFixtures(); err != nil {
+		panic(fmt.Sprintf("hitrontest: %v", err))
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("POST "+BasePath+"/CM/Reboot", s.reboot)
+	s.mux.HandleFunc("PUT "+BasePath+"/CM/Log", s.clearLog)
+	s.mux.HandleFunc("PUT "+BasePath+"/DDNS", s.updateDDNS)
+	s.mux.HandleFunc("PUT "+BasePath+"/DNS", s.updateDNS)
+	s.mux.HandleFunc("PUT "+BasePath+"/Router/DMZ", s.updateDMZ)
+	s.mux.HandleFunc("PUT "+BasePath+"/Time", s.updateTime)
+	s.mux.HandleFunc("POST "+BasePath+"/Router/PortForward", s.addPortForward)
+	s.mux.HandleFunc("PUT "+BasePath+"/Router/PortForward/{id}", s.updatePortForward)
+	s.mux.HandleFunc("DELETE "+BasePath+"/Router/PortForward/{id}", s.deletePortForward)
+	s.mux.HandleFunc("POST "+BasePath+"/Router/PortTrigger", s.addPortTrigger)
+	s.mux.HandleFunc("PUT "+BasePath+"/Router/PortTrigger/{id}", s.updatePortTrigger)
+	s.mux.HandleFunc("DELETE "+BasePath+"/Router/PortTrigger/{id}", s.deletePortTrigger)
+	s.mux.HandleFunc("PUT "+BasePath+"/Router/PortTrigger/Status", s.setPortTriggerStatus)
+	s.mux.HandleFunc("POST "+BasePath+"/Firewall/DeviceFilter/Rules", s.addRule("/Firewall/DeviceFilter/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/DeviceFilter/Rules/{id}", s.updateRule("/Firewall/DeviceFilter/Rules"))
+	s.mux.HandleFunc("DELETE "+BasePath+"/Firewall/DeviceFilter/Rules/{id}", s.deleteRule("/Firewall/DeviceFilter/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/DeviceFilter/Type", s.setDeviceFilterType)
+	s.mux.HandleFunc("POST "+BasePath+"/Firewall/Inbound/Rules", s.addRule("/Firewall/Inbound/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/Inbound/Rules/{id}", s.updateRule("/Firewall/Inbound/Rules"))
+	s.mux.HandleFunc("DELETE "+BasePath+"/Firewall/Inbound/Rules/{id}", s.deleteRule("/Firewall/Inbound/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/Inbound/Status", s.setRulesStatus("/Firewall/Inbound/Status"))
+	s.mux.HandleFunc("POST "+BasePath+"/Firewall/KeywordFilter/Rules", s.addRule("/Firewall/KeywordFilter/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/KeywordFilter/Rules/{id}", s.updateRule("/Firewall/KeywordFilter/Rules"))
+	s.mux.HandleFunc("DELETE "+BasePath+"/Firewall/KeywordFilter/Rules/{id}", s.deleteRule("/Firewall/KeywordFilter/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/KeywordFilter/Status", s.setRulesStatus("/Firewall/KeywordFilter/Status"))
+	s.mux.HandleFunc("POST "+BasePath+"/Firewall/KeywordFilter/TrustRules", s.addRule("/Firewall/KeywordFilter/TrustRules"))
+	s.mux.HandleFunc("DELETE "+BasePath+"/Firewall/KeywordFilter/TrustRules/{id}", s.deleteRule("/Firewall/KeywordFilter/TrustRules"))
+	s.mux.HandleFunc("POST "+BasePath+"/Firewall/ServiceFilter/Rules", s.addRule("/Firewall/ServiceFilter/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/ServiceFilter/Rules/{id}", s.updateRule("/Firewall/ServiceFilter/Rules"))
+	s.mux.HandleFunc("DELETE "+BasePath+"/Firewall/ServiceFilter/Rules/{id}", s.deleteRule("/Firewall/ServiceFilter/Rules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/Firewall/ServiceFilter/Status", s.setRulesStatus("/Firewall/ServiceFilter/Status"))
+	s.mux.HandleFunc("POST "+BasePath+"/Firewall/ServiceFilter/TrustRules", s.addRule("/Firewall/ServiceFilter/TrustRules"))
+	s.mux.HandleFunc("DELETE "+BasePath+"/Firewall/ServiceFilter/TrustRules/{id}", s.deleteRule("/Firewall/ServiceFilter/TrustRules"))
+	s.mux.HandleFunc("PUT "+BasePath+"/WiFi/SSIDs/{id}", s.updateSSID)
+	s.mux.HandleFunc("PUT "+BasePath+"/WiFi/GuestSSID", s.updateGuestSSID)
+	s.mux.HandleFunc("PUT "+BasePath+"/WiFi/Radios/{id}", s.updateRadio)
+	s.mux.HandleFunc("POST "+BasePath+"/WiFi/AccessControl", s.addAccessControlRule)
+	s.mux.HandleFunc("PUT "+BasePath+"/WiFi/AccessControl/{id}", s.updateAccessControlRule)
+	s.mux.HandleFunc("DELETE "+BasePath+"/WiFi/AccessControl/{id}", s.deleteAccessControlRule)
+	s.mux.HandleFunc("PUT "+BasePath+"/WiFi/AccessControl/Status", s.setAccessControlMode)
+	s.mux.HandleFunc("PUT "+BasePath+"/WiFi/WPS", s.startWPS)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Host - the host:port the fake modem is listening on, suitable for passing
+// to hitron.New
+func (s *Server) Host() string {
+	u, _ := url.Parse(s.URL)
+
+	return u.Host
+}
+
+// Paths - all paths (relative to BasePath) which can be read with a GET
+func (s *Server) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]string, 0, len(s.state))
+	for p := range s.state {
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+// SetFixture replaces the response for the given path (relative to BasePath)
+// with the given JSON object
+func (s *Server) SetFixture(p string, body []byte) error {
+	o := map[string]interface{}{}
+
+	err := json.Unmarshal(body, &o)
+	if err != nil {
+		return fmt.Errorf("invalid fixture for %s: %w", p, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state[p] = o
+
+	return nil
+}
+
+// Fixture returns the current response for the given path (relative to
+// BasePath), reflecting any writes made so far
+func (s *Server) Fixture(p string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.state[p]
+	if !ok {
+		return nil, false
+	}
+
+	b, _ := json.Marshal(o) //nolint:errchkjson
+
+	return b, true
+}
+
+// Writes returns all writes accepted so far
+func (s *Server) Writes() []Write {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Write{}, s.writes...)
+}
+
+// Reboots - the number of times the modem has been rebooted (including by
+// restoring a configuration backup)
+func (s *Server) Reboots() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reboots
+}
+
+// Backup returns the current configuration backup file
+func (s *Server) Backup() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]byte{}, s.backup...)
+}
+
+// ExpireSessions invalidates all current sessions, as if they had timed out
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = map[string]string{}
+}
+
+func (s *Server) loadFixtures() error {
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		b, err := fixtures.ReadFile(path.Join("fixtures", e.Name()))
+		if err != nil {
+			return err
+		}
+
+		o := map[string]interface{}{}
+
+		err = json.Unmarshal(b, &o)
+		if err != nil {
+			return fmt.Errorf("invalid fixture %s: %w", e.Name(), err)
+		}
+
+		s.state[fixturePath(e.Name())] = o
+	}
+
+	return nil
+}
+
+// fixturePath - converts a fixture file name (like "CM_DsInfo.json") to the
+// path it's served at (like "/CM/DsInfo")
+func fixturePath(name string) string {
+	return "/" + strings.ReplaceAll(strings.TrimSuffix(name, ".json"), "_", "/")
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := strings.CutPrefix(r.URL.Path, BasePath)
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	switch p {
+	case "/Users/Login":
+		s.login(w, r)
+
+		return
+	case "/Users/Logout":
+		s.logout(w, r)
+
+		return
+	}
+
+	csrf, ok := s.session(r)
+	if !ok {
+		// the real modem responds to unauthenticated requests with a 403
+		http.Error(w, "Forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		s.read(w, p, csrf)
+
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	s.write(w, r, p, csrf)
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	creds := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{}
+
+	err := json.Unmarshal([]byte(r.PostFormValue("model")), &creds)
+	if err != nil || creds.Username != s.username || creds.Password != s.password {
+		http.Error(w, "Login failed", http.StatusForbidden)
+
+		return
+	}
+
+	id := randomToken()
+
+	s.mu.Lock()
+	s.sessions[id] = randomToken()
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: id, Path: "/"})
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(SessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, c.Value)
+		s.mu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Path: "/", MaxAge: -1})
+	writeJSON(w, map[string]interface{}{})
+}
+
+// session - returns the CSRF token for the request's session, if the session
+// is valid
+func (s *Server) session(r *http.Request) (string, bool) {
+	c, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	csrf, ok := s.sessions[c.Value]
+
+	return csrf, ok
+}
+
+func (s *Server) read(w http.ResponseWriter, p, csrf string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch p {
+	case "/Users/CSRF":
+		writeJSON(w, map[string]interface{}{"CSRF": csrf})
+
+		return
+	case "/Router/Backup":
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="backup.cfg"`)
+		_, _ = w.Write(s.backup)
+
+		return
+	}
+
+	if p == "/WiFi/WPS" {
+		s.advanceWPS()
+	}
+
+	// individual radios are read from the list of all radios
+	if radio, ok := s.radio(p); ok {
+		writeJSON(w, radio)
+
+		return
+	}
+
+	o, ok := s.state[p]
+	if !ok {
+		http.NotFound(w, nil)
+
+		return
+	}
+
+	writeJSON(w, o)
+}
+
+func (s *Server) write(w http.ResponseWriter, r *http.Request, p, csrf string) {
+	if p == "/Router/Restore" {
+		s.restore(w, r, csrf)
+
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if r.PostForm.Get("csrf") != csrf {
+		http.Error(w, "CSRF token mismatch", http.StatusForbidden)
+
+		return
+	}
+
+	method := r.PostForm.Get("_method")
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	// dispatch to the handler for the method the modem would see
+	r.Method = method
+
+	h, pattern := s.mux.Handler(r)
+	if pattern == "" {
+		h.ServeHTTP(w, r)
+
+		return
+	}
+
+	s.mu.Lock()
+	s.writes = append(s.writes, Write{Path: p, Method: method, Model: r.PostForm.Get("model")})
+	s.mu.Unlock()
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) reboot(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.reboots++
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) clearLog(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.state["/CM/Log"]["Log_List"] = []interface{}{}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) restore(w http.ResponseWriter, r *http.Request, csrf string) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if r.FormValue("csrf") != csrf {
+		http.Error(w, "CSRF token mismatch", http.StatusForbidden)
+
+		return
+	}
+
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, "001", "no file uploaded")
+
+		return
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil || len(b) == 0 {
+		writeError(w, "001", "invalid backup file")
+
+		return
+	}
+
+	s.mu.Lock()
+	s.backup = b
+	s.reboots++
+	s.writes = append(s.writes, Write{Path: "/Router/Restore", Method: http.MethodPost})
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateDDNS(w http.ResponseWriter, r *http.Request) {
+	ddns, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.Copy(s.state["/DDNS"], ddns)
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateDNS(w http.ResponseWriter, r *http.Request) {
+	dns, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.Copy(s.state["/DNS"], dns)
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateDMZ(w http.ResponseWriter, r *http.Request) {
+	dmz, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.Copy(s.state["/Router/DMZ"], dmz)
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateTime(w http.ResponseWriter, r *http.Request) {
+	t, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.Copy(s.state["/Time"], t)
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) addPortForward(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.portForwardRules()
+
+	next := 1
+
+	for _, existing := range rules {
+		if id := ruleID(existing); id >= next {
+			next = id + 1
+		}
+	}
+
+	rule["id"] = strconv.Itoa(next)
+	s.setPortForwardRules(append(rules, rule))
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updatePortForward(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.portForwardRules()
+	for i, existing := range rules {
+		if ruleID(existing) == id {
+			rule["id"] = strconv.Itoa(id)
+			rules[i] = rule
+			s.setPortForwardRules(rules)
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such rule")
+}
+
+func (s *Server) deletePortForward(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.portForwardRules()
+	for i, existing := range rules {
+		if ruleID(existing) == id {
+			s.setPortForwardRules(append(rules[:i], rules[i+1:]...))
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such rule")
+}
+
+func (s *Server) addPortTrigger(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.portTriggerRules()
+
+	next := 1
+
+	for _, existing := range rules {
+		if id := ruleID(existing); id >= next {
+			next = id + 1
+		}
+	}
+
+	rule["id"] = strconv.Itoa(next)
+	s.setPortTriggerRules(append(rules, rule))
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updatePortTrigger(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.portTriggerRules()
+	for i, existing := range rules {
+		if ruleID(existing) == id {
+			rule["id"] = strconv.Itoa(id)
+			rules[i] = rule
+			s.setPortTriggerRules(rules)
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such rule")
+}
+
+func (s *Server) deletePortTrigger(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.portTriggerRules()
+	for i, existing := range rules {
+		if ruleID(existing) == id {
+			s.setPortTriggerRules(append(rules[:i], rules[i+1:]...))
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such rule")
+}
+
+func (s *Server) setPortTriggerStatus(w http.ResponseWriter, r *http.Request) {
+	status, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state["/Router/PortTrigger/Status"]["allRulesOnOff"] = status["allRulesOnOff"]
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+// addRule handles adding a rule to the list served from the given path, for
+// lists with string IDs which start at 1
+func (s *Server) addRule(list string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule, ok := decodeModel(w, r)
+		if !ok {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		rules := s.rules(list)
+
+		next := 1
+
+		for _, existing := range rules {
+			if id := ruleID(existing); id >= next {
+				next = id + 1
+			}
+		}
+
+		rule["id"] = strconv.Itoa(next)
+		s.setRules(list, append(rules, rule))
+
+		writeJSON(w, map[string]interface{}{})
+	}
+}
+
+// updateRule handles replacing a rule in the list served from the given path
+func (s *Server) updateRule(list string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule, ok := decodeModel(w, r)
+		if !ok {
+			return
+		}
+
+		id, _ := strconv.Atoi(r.PathValue("id"))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		rules := s.rules(list)
+		for i, existing := range rules {
+			if ruleID(existing) == id {
+				rule["id"] = strconv.Itoa(id)
+				rules[i] = rule
+				s.setRules(list, rules)
+
+				writeJSON(w, map[string]interface{}{})
+
+				return
+			}
+		}
+
+		writeError(w, "001", "no such rule")
+	}
+}
+
+// deleteRule handles deleting a rule from the list served from the given path
+func (s *Server) deleteRule(list string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.PathValue("id"))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		rules := s.rules(list)
+		for i, existing := range rules {
+			if ruleID(existing) == id {
+				s.setRules(list, append(rules[:i], rules[i+1:]...))
+
+				writeJSON(w, map[string]interface{}{})
+
+				return
+			}
+		}
+
+		writeError(w, "001", "no such rule")
+	}
+}
+
+// setRulesStatus handles turning a whole list of rules on or off
+func (s *Server) setRulesStatus(p string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, ok := decodeModel(w, r)
+		if !ok {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.state[p]["allRulesOnOff"] = status["allRulesOnOff"]
+
+		writeJSON(w, map[string]interface{}{})
+	}
+}
+
+func (s *Server) setDeviceFilterType(w http.ResponseWriter, r *http.Request) {
+	status, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state["/Firewall/DeviceFilter/Type"]["blockType"] = status["blockType"]
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateSSID(w http.ResponseWriter, r *http.Request) {
+	ssid, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ssids, _ := s.state["/WiFi/SSIDs"]["SSIDs_List"].([]interface{})
+	for i, existing := range ssids {
+		if ruleID(existing) == id {
+			ssid["id"] = strconv.Itoa(id)
+			ssids[i] = ssid
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such SSID")
+}
+
+func (s *Server) updateGuestSSID(w http.ResponseWriter, r *http.Request) {
+	guest, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.Copy(s.state["/WiFi/GuestSSID"], guest)
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateRadio(w http.ResponseWriter, r *http.Request) {
+	radio, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uri := BasePath + "/WiFi/Radios/" + r.PathValue("id")
+	found := false
+
+	// basic and advanced settings are written through the same URI, so both
+	// lists are updated with whichever fields they have in common
+	for _, l := range []string{"/WiFi/Radios", "/WiFi/Radios/Advanced"} {
+		for _, entry := range s.radioList(l) {
+			m, _ := entry.(map[string]interface{})
+			if m["Radio_URI"] != uri {
+				continue
+			}
+
+			found = true
+
+			for k, v := range radio {
+				if _, ok := m[k]; ok {
+					m[k] = v
+				}
+			}
+		}
+	}
+
+	if !found {
+		writeError(w, "001", "no such radio")
+
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+// radioList returns the list of radios from /WiFi/Radios or
+// /WiFi/Radios/Advanced - must be called with s.mu held
+func (s *Server) radioList(p string) []interface{} {
+	key := "Raidos_List"
+	if p == "/WiFi/Radios/Advanced" {
+		key = "Advanced_List"
+	}
+
+	radios, _ := s.state[p][key].([]interface{})
+
+	return radios
+}
+
+// radio returns the radio with the given path - must be called with s.mu held
+func (s *Server) radio(p string) (map[string]interface{}, bool) {
+	for _, entry := range s.radioList("/WiFi/Radios") {
+		m, _ := entry.(map[string]interface{})
+		if m["Radio_URI"] == BasePath+p {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s *Server) addAccessControlRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.accessControlRules()
+
+	// unlike port forwarding rules, IDs are numbers, and start at 0
+	next := 0
+
+	for _, existing := range rules {
+		if id := numericID(existing); id >= next {
+			next = id + 1
+		}
+	}
+
+	rule["id"] = next
+	s.setAccessControlRules(append(rules, rule))
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) updateAccessControlRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.accessControlRules()
+	for i, existing := range rules {
+		if numericID(existing) == id {
+			rule["id"] = id
+			rules[i] = rule
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such rule")
+}
+
+func (s *Server) deleteAccessControlRule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.accessControlRules()
+	for i, existing := range rules {
+		if numericID(existing) == id {
+			s.setAccessControlRules(append(rules[:i], rules[i+1:]...))
+
+			writeJSON(w, map[string]interface{}{})
+
+			return
+		}
+	}
+
+	writeError(w, "001", "no such rule")
+}
+
+func (s *Server) setAccessControlMode(w http.ResponseWriter, r *http.Request) {
+	status, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the mode is shown in both places
+	s.state["/WiFi/AccessControl"]["blockType"] = status["blockType"]
+	s.state["/WiFi/AccessControl/Status"]["blockType"] = status["blockType"]
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) startWPS(w http.ResponseWriter, r *http.Request) {
+	wps, ok := decodeModel(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wpsReads >= 0 {
+		writeError(w, "001", "WPS session already in progress")
+
+		return
+	}
+
+	state := s.state["/WiFi/WPS"]
+	maps.Copy(state, wps)
+	state["wlsWpsStatus"] = "In Progress"
+	state["wlsWpsTimeElapsed"] = "0"
+	s.wpsReads = 0
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+// advanceWPS moves a running WPS session along by a second, finishing it
+// successfully after WPSReads reads - must be called with s.mu held
+func (s *Server) advanceWPS() {
+	if s.wpsReads < 0 {
+		return
+	}
+
+	s.wpsReads++
+
+	state := s.state["/WiFi/WPS"]
+	state["wlsWpsTimeElapsed"] = strconv.Itoa(s.wpsReads)
+
+	if s.wpsReads >= WPSReads {
+		state["wlsWpsStatus"] = "Success"
+		s.wpsReads = -1
+	}
+}
+
+// accessControlRules - must be called with s.mu held
+func (s *Server) accessControlRules() []interface{} {
+	rules, _ := s.state["/WiFi/AccessControl"]["Rules_List"].([]interface{})
+
+	return rules
+}
+
+// setAccessControlRules - must be called with s.mu held
+func (s *Server) setAccessControlRules(rules []interface{}) {
+	all := s.state["/WiFi/AccessControl"]
+	all["Rules_List"] = rules
+	all["RuleNumberOfEntries"] = len(rules)
+}
+
+// numericID - the ID of a list entry with a numeric "id" field (a float64 when
+// decoded from JSON, or an int when assigned by the fake modem)
+func numericID(entry interface{}) int {
+	m, _ := entry.(map[string]interface{})
+
+	switch id := m["id"].(type) {
+	case float64:
+		return int(id)
+	case int:
+		return id
+	default:
+		return -1
+	}
+}
+
+// portForwardRules - must be called with s.mu held
+func (s *Server) portForwardRules() []interface{} {
+	rules, _ := s.state["/Router/PortForward/all"]["Rules_List"].([]interface{})
+
+	return rules
+}
+
+// setPortForwardRules - must be called with s.mu held
+func (s *Server) setPortForwardRules(rules []interface{}) {
+	all := s.state["/Router/PortForward/all"]
+	all["Rules_List"] = rules
+	all["total"] = len(rules)
+}
+
+// portTriggerRules - must be called with s.mu held
+func (s *Server) portTriggerRules() []interface{} {
+	rules, _ := s.state["/Router/PortTrigger/all"]["Rules_List"].([]interface{})
+
+	return rules
+}
+
+// setPortTriggerRules - must be called with s.mu held
+func (s *Server) setPortTriggerRules(rules []interface{}) {
+	all := s.state["/Router/PortTrigger/all"]
+	all["Rules_List"] = rules
+	all["total"] = len(rules)
+}
+
+// rules - the Rules_List of the given path. Must be called with s.mu held
+func (s *Server) rules(p string) []interface{} {
+	rules, _ := s.state[p]["Rules_List"].([]interface{})
+
+	return rules
+}
+
+// setRules - must be called with s.mu held
+func (s *Server) setRules(p string, rules []interface{}) {
+	s.state[p]["Rules_List"] = rules
+}
+
+// ruleID - the ID of a rule (or other list entry) with a string "id" field
+func ruleID(rule interface{}) int {
+	m, _ := rule.(map[string]interface{})
+	s, _ := m["id"].(string)
+	id, _ := strconv.Atoi(s)
+
+	return id
+}
+
+func decodeModel(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	o := map[string]interface{}{}
+
+	err := json.Unmarshal([]byte(r.PostForm.Get("model")), &o)
+	if err != nil {
+		writeError(w, "001", "invalid model")
+
+		return nil, false
+	}
+
+	return o, true
+}
+
+// writeJSON - writes the object, with a successful error code unless it
+// already has one
+func writeJSON(w http.ResponseWriter, o map[string]interface{}) {
+	out := map[string]interface{}{"errCode": "000", "errMsg": ""}
+	for k, v := range o {
+		out[k] = v
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func writeError(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"errCode": code, "errMsg": msg})
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,763 @@
+package hitrontest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	hitron "github.com/hairyhenderson/hitron_coda"
+	"github.com/hairyhenderson/hitron_coda/hitrontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newModem(t *testing.T, opts ...hitron.Option) (*hitrontest.Server, *hitron.CableModem) {
+	t.Helper()
+
+	srv := hitrontest.NewServer("cusadmin", "secret")
+	t.Cleanup(srv.Close)
+
+	opts = append([]hitron.Option{hitron.WithScheme("http")}, opts...)
+
+	d, err := hitron.New(srv.Host(), "cusadmin", "secret", opts...)
+	require.NoError(t, err)
+
+	return srv, d
+}
+
+func TestFixturesCoverAPIList(t *testing.T) {
+	b, err := os.ReadFile("../apilist.yaml")
+	require.NoError(t, err)
+
+	list := struct {
+		Paths []string `yaml:"paths"`
+	}{}
+	require.NoError(t, yaml.Unmarshal(b, &list))
+
+	srv := hitrontest.NewServer("", "")
+	defer srv.Close()
+
+	paths := srv.Paths()
+	sort.Strings(paths)
+
+	assert.Equal(t, list.Paths, paths)
+}
+
+func TestReadAll(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	// call every generated getter, to make sure all fixtures decode cleanly
+	v := reflect.ValueOf(d)
+	for i := range v.NumMethod() {
+		m := v.Type().Method(i)
+		if m.Type.NumIn() != 2 || m.Type.NumOut() != 2 ||
+			!strings.HasSuffix(m.Type.Out(0).PkgPath(), "hitron_coda") {
+			continue
+		}
+
+		out := v.Method(i).Call([]reflect.Value{reflect.ValueOf(ctx)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			t.Errorf("%s: %v", m.Name, err)
+		}
+	}
+}
+
+func TestSessionEnforced(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	_, err := d.CMVersion(ctx)
+	assert.ErrorIs(t, err, hitron.ErrUnauthorized)
+
+	bad, err := hitron.New(srv.Host(), "cusadmin", "wrong", hitron.WithScheme("http"))
+	require.NoError(t, err)
+
+	err = bad.Login(ctx)
+
+	var serr *hitron.StatusError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, 403, serr.StatusCode)
+
+	require.NoError(t, d.Login(ctx))
+
+	v, err := d.CMVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "CODA-4680-TPIA", v.ModelName)
+
+	require.NoError(t, d.Logout(ctx))
+
+	_, err = d.CMVersion(ctx)
+	assert.ErrorIs(t, err, hitron.ErrUnauthorized)
+}
+
+func TestCSRFEnforced(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	csrf, err := d.UsersCSRF(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, csrf.CSRF)
+
+	// a second session gets a different token
+	other, err := hitron.New(srv.Host(), "cusadmin", "secret", hitron.WithScheme("http"))
+	require.NoError(t, err)
+	require.NoError(t, other.Login(ctx))
+
+	otherCSRF, err := other.UsersCSRF(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, csrf.CSRF, otherCSRF.CSRF)
+
+	// writes with a valid token are accepted
+	_, err = d.CMReboot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.Reboots())
+}
+
+func TestClearLog(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	l, err := d.CMLog(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, l.Logs)
+
+	_, err = d.CMClearLog(ctx)
+	require.NoError(t, err)
+
+	l, err = d.CMLog(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, l.Logs)
+}
+
+func TestPortForwardRules(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	rule := hitron.PortForwardRule{
+		AppName:      "web",
+		Protocol:     "TCP",
+		LocalIP:      net.ParseIP("192.168.0.20"),
+		PublicPorts:  hitron.PortRange{Start: 8080, End: 8080},
+		PrivatePorts: hitron.PortRange{Start: 80, End: 80},
+		Enable:       true,
+	}
+
+	all, err := d.AddPortForwardRule(ctx, rule)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+	assert.Equal(t, "web", all.Rules[1].AppName)
+	assert.Equal(t, 2, all.Rules[1].ID)
+
+	all, err = d.SetPortForwardEnabled(ctx, 2, false)
+	require.NoError(t, err)
+	assert.False(t, all.Rules[1].Enable)
+
+	all, err = d.DeletePortForwardRule(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 1)
+	assert.Equal(t, "web", all.Rules[0].AppName)
+
+	_, err = d.DeletePortForwardRule(ctx, 1)
+	assert.Error(t, err)
+
+	writes := srv.Writes()
+	require.Len(t, writes, 3)
+	assert.Equal(t, hitrontest.Write{Path: "/Router/PortForward/1", Method: "DELETE", Model: writes[2].Model}, writes[2])
+}
+
+func TestPortTriggerRules(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	rule := hitron.PortTriggerRule{
+		AppName:      "voip",
+		Protocol:     "UDP",
+		TriggerPorts: hitron.PortRange{Start: 5060, End: 5060},
+		TargetPorts:  hitron.PortRange{Start: 10000, End: 10010},
+		Timeout:      time.Minute,
+		Enable:       true,
+	}
+
+	all, err := d.AddPortTriggerRule(ctx, rule)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+	assert.Equal(t, "voip", all.Rules[1].AppName)
+	assert.Equal(t, 2, all.Rules[1].ID)
+	assert.Equal(t, time.Minute, all.Rules[1].Timeout)
+
+	// the fake modem's rules are checked for overlaps too
+	_, err = d.AddPortTriggerRule(ctx, rule)
+	assert.Error(t, err)
+
+	all, err = d.SetPortTriggerEnabled(ctx, 2, false)
+	require.NoError(t, err)
+	assert.False(t, all.Rules[1].Enable)
+
+	all, err = d.DeletePortTriggerRule(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 1)
+	assert.Equal(t, "voip", all.Rules[0].AppName)
+
+	status, err := d.SetAllPortTriggersEnabled(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, status.Enable)
+
+	writes := srv.Writes()
+	require.Len(t, writes, 4)
+	assert.Equal(t, hitrontest.Write{Path: "/Router/PortTrigger/1", Method: "DELETE", Model: writes[2].Model}, writes[2])
+	assert.Equal(t, "/Router/PortTrigger/Status", writes[3].Path)
+}
+
+func TestInboundRules(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	rule := hitron.InboundRule{
+		Name:     "web",
+		Protocol: "TCP",
+		Action:   hitron.FirewallAllow,
+		Ports:    hitron.PortRange{Start: 443, End: 443},
+		Enable:   true,
+	}
+
+	all, err := d.AddInboundRule(ctx, rule)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+	assert.Equal(t, "web", all.Rules[1].Name)
+	assert.Equal(t, 2, all.Rules[1].ID)
+	assert.Equal(t, net.IPv4bcast.String(), all.Rules[1].Sources.End.String())
+
+	// the fake modem's rules are checked for duplicates too
+	_, err = d.AddInboundRule(ctx, rule)
+	assert.Error(t, err)
+
+	all, err = d.SetInboundRuleEnabled(ctx, 2, false)
+	require.NoError(t, err)
+	assert.False(t, all.Rules[1].Enable)
+
+	all, err = d.DeleteInboundRule(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 1)
+	assert.Equal(t, "web", all.Rules[0].Name)
+
+	status, err := d.SetInboundEnabled(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, status.Enable)
+
+	writes := srv.Writes()
+	require.Len(t, writes, 4)
+	assert.Equal(t, hitrontest.Write{Path: "/Firewall/Inbound/Rules/1", Method: "DELETE", Model: writes[2].Model}, writes[2])
+	assert.Equal(t, "/Firewall/Inbound/Status", writes[3].Path)
+}
+
+func TestDeviceFilter(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	hosts, err := d.Hosts(ctx)
+	require.NoError(t, err)
+
+	console, err := hosts.Lookup("console")
+	require.NoError(t, err)
+
+	sched, err := hitron.ParseSchedule("Mon-Fri", "21:00-23:59")
+	require.NoError(t, err)
+
+	all, err := d.BlockDevice(ctx, console.MacAddr, console.Name, sched)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+	assert.Equal(t, "console", all.Rules[1].Name)
+	assert.Equal(t, 2, all.Rules[1].ID)
+	assert.Equal(t, sched, all.Rules[1].Schedule)
+
+	// blocking again replaces the schedule
+	all, err = d.BlockDevice(ctx, console.MacAddr, "", hitron.Schedule{})
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+	assert.Equal(t, hitron.Always, all.Rules[1].Schedule)
+
+	all, err = d.UnblockDevice(ctx, all.Rules[0].MacAddr)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 1)
+	assert.Equal(t, "console", all.Rules[0].Name)
+
+	filterType, err := d.SetDeviceFilterType(ctx, hitron.DeviceFilterDisabled)
+	require.NoError(t, err)
+	assert.Equal(t, hitron.DeviceFilterDisabled, filterType.BlockType)
+
+	writes := srv.Writes()
+	require.Len(t, writes, 4)
+	assert.Equal(t, hitrontest.Write{Path: "/Firewall/DeviceFilter/Rules/1", Method: "DELETE", Model: writes[2].Model}, writes[2])
+	assert.Equal(t, "/Firewall/DeviceFilter/Type", writes[3].Path)
+}
+
+func TestKeywordFilter(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	all, err := d.AddKeywordFilterRule(ctx, hitron.KeywordFilterRule{Keyword: "poker", Enable: true})
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+	assert.Equal(t, hitron.KeywordFilterRule{ID: 2, Keyword: "poker", Enable: true}, all.Rules[1])
+
+	_, err = d.AddKeywordFilterRule(ctx, hitron.KeywordFilterRule{Keyword: "Poker"})
+	assert.Error(t, err)
+
+	all, err = d.DeleteKeywordFilterRule(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 1)
+
+	status, err := d.SetKeywordFilterEnabled(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, status.Enable)
+
+	hosts, err := d.Hosts(ctx)
+	require.NoError(t, err)
+
+	console, err := hosts.Lookup("console")
+	require.NoError(t, err)
+
+	trust, err := d.AddKeywordFilterTrust(ctx, hitron.TrustRule{Name: console.Name, MacAddr: console.MacAddr})
+	require.NoError(t, err)
+	require.Len(t, trust.Rules, 2)
+	assert.Equal(t, console.MacAddr, trust.Rules[1].MacAddr)
+
+	trust, err = d.DeleteKeywordFilterTrust(ctx, trust.Rules[0].MacAddr)
+	require.NoError(t, err)
+	require.Len(t, trust.Rules, 1)
+	assert.Equal(t, "console", trust.Rules[0].Name)
+
+	writes := srv.Writes()
+	require.Len(t, writes, 5)
+	assert.Equal(t, hitrontest.Write{Path: "/Firewall/KeywordFilter/TrustRules/1", Method: "DELETE", Model: writes[4].Model}, writes[4])
+}
+
+func TestServiceFilter(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	sched, err := hitron.ParseSchedule("Mon-Fri", "09:00-17:00")
+	require.NoError(t, err)
+
+	rule := hitron.ServiceFilterRule{
+		Name:     "games",
+		Protocol: "UDP",
+		Ports:    hitron.PortRange{Start: 27000, End: 27050},
+		Schedule: sched,
+		Enable:   true,
+	}
+
+	all, err := d.AddServiceFilterRule(ctx, rule)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 2)
+
+	rule.ID = 2
+	assert.Equal(t, rule, all.Rules[1])
+
+	rule.Enable = false
+
+	all, err = d.UpdateServiceFilterRule(ctx, rule)
+	require.NoError(t, err)
+	assert.False(t, all.Rules[1].Enable)
+
+	all, err = d.DeleteServiceFilterRule(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, all.Rules, 1)
+	assert.Equal(t, "games", all.Rules[0].Name)
+
+	status, err := d.SetServiceFilterEnabled(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, status.Enable)
+
+	// the laptop is already trusted
+	_, err = d.AddServiceFilterTrust(ctx, hitron.TrustRule{Name: "laptop", MacAddr: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe}})
+	assert.Error(t, err)
+
+	trust, err := d.DeleteServiceFilterTrust(ctx, net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe})
+	require.NoError(t, err)
+	assert.Empty(t, trust.Rules)
+
+	writes := srv.Writes()
+	require.Len(t, writes, 5)
+	assert.Equal(t, "/Firewall/ServiceFilter/Status", writes[3].Path)
+}
+
+func TestUpdateTime(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	toronto, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+
+	tm, err := d.SetTimeZone(ctx, toronto)
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", tm.TZ.String())
+	assert.Equal(t, "pool.ntp.org", tm.SNTPServer)
+	assert.True(t, tm.Enable)
+}
+
+func TestUpdateDNS(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	pihole := net.ParseIP("192.168.0.53")
+	suffix := "lan"
+	off := false
+
+	dns, err := d.UpdateDNS(ctx, hitron.DNSChanges{LanDNS1: &pihole, DomainSuffix: &suffix, AutoEnable: &off})
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.53", dns.LanDNS1.String())
+	assert.Equal(t, "lan", dns.DomainSuffix)
+	assert.False(t, dns.AutoEnable)
+	assert.True(t, dns.ProxyEnable)
+}
+
+func TestUpdateDDNS(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	provider := "default@no-ip.com"
+	user, pass, host := "user", "pass", "home.example.com"
+	interval := time.Hour
+
+	ddns, err := d.UpdateDDNS(ctx, hitron.DDNSChanges{
+		Provider: &provider, Username: &user, Password: &pass, Hostname: &host, UpdateInterval: &interval,
+	})
+	require.NoError(t, err)
+	assert.False(t, ddns.Enable)
+	assert.Equal(t, provider, ddns.Provider)
+	assert.Equal(t, time.Hour, ddns.UpdateInterval)
+
+	ddns, err = d.SetDDNSEnabled(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, ddns.Enable)
+	assert.Equal(t, "home.example.com", ddns.Hostname)
+}
+
+func TestDMZ(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	_, _, err := d.SetDMZ(ctx, net.ParseIP("10.0.0.5"))
+	assert.Error(t, err)
+
+	dmz, _, err := d.SetDMZ(ctx, net.ParseIP("192.168.0.16"))
+	require.NoError(t, err)
+	assert.True(t, dmz.Enable)
+	assert.Equal(t, "192.168.0.16", dmz.Host.String())
+
+	dmz, err = d.DisableDMZ(ctx)
+	require.NoError(t, err)
+	assert.False(t, dmz.Enable)
+	assert.True(t, dmz.Host.IsUnspecified())
+
+	assert.Len(t, srv.Writes(), 2)
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, d.RouterBackup(ctx, buf))
+	assert.Equal(t, hitrontest.DefaultBackup, buf.String())
+
+	require.NoError(t, d.RouterRestore(ctx, strings.NewReader("new config")))
+	assert.Equal(t, []byte("new config"), srv.Backup())
+	assert.Equal(t, 1, srv.Reboots())
+}
+
+func TestExpireSessions(t *testing.T) {
+	ctx := context.Background()
+
+	var relogins int
+
+	srv, d := newModem(t, hitron.WithAutoRelogin(),
+		hitron.WithReloginHook(func(_ context.Context, _, err error) {
+			assert.NoError(t, err)
+
+			relogins++
+		}))
+
+	require.NoError(t, d.Login(ctx))
+
+	srv.ExpireSessions()
+
+	_, err := d.CMReboot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, relogins)
+	assert.Equal(t, 1, srv.Reboots())
+}
+
+// roundTripperFunc - an http.RoundTripper implemented by a function
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestExpireSessions_AfterCSRF(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		srv      *hitrontest.Server
+		expired  bool
+		relogins int
+	)
+
+	// expire the session as soon as the first CSRF token has been handed
+	// out, so the write which follows is rejected
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if !expired && strings.HasSuffix(r.URL.Path, "/Users/CSRF") {
+			expired = true
+
+			srv.ExpireSessions()
+		}
+
+		return resp, err
+	})
+
+	srv, d := newModem(t, hitron.WithTransport(rt), hitron.WithAutoRelogin(),
+		hitron.WithReloginHook(func(_ context.Context, _, err error) {
+			assert.NoError(t, err)
+
+			relogins++
+		}))
+
+	require.NoError(t, d.Login(ctx))
+
+	// the retried write must use a token from the new session
+	_, err := d.SetInboundEnabled(ctx, false)
+	require.NoError(t, err)
+	assert.True(t, expired)
+	assert.Equal(t, 1, relogins)
+	require.Len(t, srv.Writes(), 1)
+	assert.Equal(t, "/Firewall/Inbound/Status", srv.Writes()[0].Path)
+}
+
+func TestSetFixture(t *testing.T) {
+	ctx := context.Background()
+	srv, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+	require.NoError(t, srv.SetFixture("/Router/Location", []byte(`{"locationText":"Attic"}`)))
+
+	l, err := d.RouterLocation(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Attic", l.LocationText)
+
+	require.NoError(t, srv.SetFixture("/Router/Location", []byte(`{"errCode":"001","errMsg":"broken"}`)))
+
+	_, err = d.RouterLocation(ctx)
+
+	var apiErr *hitron.APIError
+	assert.True(t, errors.As(err, &apiErr))
+
+	assert.Error(t, srv.SetFixture("/Router/Location", []byte(`not json`)))
+}
+
+func TestCSRFMismatch(t *testing.T) {
+	srv, _ := newModem(t)
+
+	// log in with a raw client, so we can send the wrong token
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	hc := &http.Client{Jar: jar}
+	base := srv.URL + hitrontest.BasePath
+
+	resp, err := hc.PostForm(base+"/Users/Login", url.Values{
+		"model": []string{`{"username":"cusadmin","password":"secret"}`},
+	})
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = hc.PostForm(base+"/CM/Reboot", url.Values{
+		"model": []string{`{"reboot":1}`},
+		"csrf":  []string{"wrong"},
+	})
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+
+	err = &hitron.StatusError{StatusCode: resp.StatusCode, Body: body}
+	assert.ErrorIs(t, err, hitron.ErrCSRFMismatch)
+	assert.Equal(t, 0, srv.Reboots())
+	assert.Empty(t, srv.Writes())
+}
+
+func TestUpdateSSID(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	pass := "rotated passphrase"
+
+	s, err := d.UpdateSSID(ctx, 2, hitron.SSIDChanges{Passphrase: &pass})
+	require.NoError(t, err)
+	assert.Equal(t, pass, s.Passphrase)
+	assert.Equal(t, "5G", s.Band)
+}
+
+func TestUpdateGuestSSID(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	g, err := d.SetGuestEnabled(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, g.Enable)
+
+	rotated, err := d.RotateGuestPassword(ctx)
+	require.NoError(t, err)
+	assert.True(t, rotated.Enable)
+	assert.NotEqual(t, g.Password, rotated.Password)
+	assert.Equal(t, "CODA-Guest", rotated.SSID)
+}
+
+func TestUpdateRadio(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	channel := 11
+
+	r, err := d.UpdateRadio(ctx, 1, hitron.RadioChanges{Channel: &channel})
+	require.NoError(t, err)
+	assert.Equal(t, 11, r.Channel)
+	assert.Equal(t, "2.4G", r.Band)
+
+	all, err := d.WiFiRadios(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 11, all.Radios[0].Channel)
+}
+
+func TestUpdateRadioAdvanced(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	off := false
+
+	r, err := d.UpdateRadioAdvanced(ctx, 2, hitron.AdvancedRadioChanges{BandSteering: &off})
+	require.NoError(t, err)
+	assert.False(t, r.BandSteering)
+	assert.Equal(t, "5G", r.Band)
+
+	// changes to the basic settings are reflected in the advanced settings
+	channel := 6
+
+	_, err = d.UpdateRadio(ctx, 1, hitron.RadioChanges{Channel: &channel})
+	require.NoError(t, err)
+
+	all, err := d.WiFiRadiosAdvanced(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, all.Radios[0].Channel)
+	assert.True(t, all.Radios[0].BandSteering)
+	assert.False(t, all.Radios[1].BandSteering)
+}
+
+func TestAccessControl(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	mac, _ := net.ParseMAC("12:34:56:78:9a:bc")
+
+	all, err := d.AddAccessControlRule(ctx, hitron.WiFiAccessControlRule{Hostname: "phone", MACAddr: mac})
+	require.NoError(t, err)
+	require.Len(t, all.RulesList, 2)
+	assert.Equal(t, 1, all.RulesList[1].ID)
+
+	_, err = d.AddAccessControlRule(ctx, hitron.WiFiAccessControlRule{Hostname: "again", MACAddr: mac})
+	require.Error(t, err)
+
+	all, err = d.ReplaceAccessControlRules(ctx, []hitron.WiFiAccessControlRule{
+		{Hostname: "renamed", MACAddr: mac},
+	})
+	require.NoError(t, err)
+	require.Len(t, all.RulesList, 1)
+	assert.Equal(t, "renamed", all.RulesList[0].Hostname)
+
+	all, err = d.DeleteAccessControlRule(ctx, mac)
+	require.NoError(t, err)
+	assert.Empty(t, all.RulesList)
+
+	status, err := d.SetAccessControlMode(ctx, hitron.AccessControlAllow)
+	require.NoError(t, err)
+	assert.Equal(t, hitron.AccessControlAllow, status.BlockType)
+
+	all, err = d.WiFiAccessControl(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, hitron.AccessControlAllow, all.BlockType)
+}
+
+func TestWPS(t *testing.T) {
+	ctx := context.Background()
+	_, d := newModem(t)
+
+	require.NoError(t, d.Login(ctx))
+
+	wps, err := d.StartWPS(ctx, hitron.WPSMethodPushButton, "")
+	require.NoError(t, err)
+	assert.Equal(t, hitron.WPSInProgress, wps.State())
+
+	_, err = d.StartWPS(ctx, hitron.WPSMethodPushButton, "")
+	require.Error(t, err)
+
+	for range hitrontest.WPSReads - 1 {
+		wps, err = d.WiFiWPS(ctx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, hitron.WPSSuccess, wps.State())
+	assert.Equal(t, time.Duration(hitrontest.WPSReads)*time.Second, wps.TimeElapsed)
+}
@@ -0,0 +1,233 @@
+// Package qrcode renders QR codes for Wi-Fi network payloads. The symbols are
+// encoded in byte mode at error correction level M by rsc.io/qr/coding - this
+// package only picks the mask, and draws the result.
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"rsc.io/qr/coding"
+)
+
+// quietZone - the width (in modules) of the light border required around the
+// symbol
+const quietZone = 4
+
+// Code - an encoded QR code symbol
+type Code struct {
+	code    *coding.Code
+	Version int
+	Size    int
+}
+
+// Encode encodes the data in the smallest QR code version that fits, with the
+// mask that has the lowest penalty score
+func Encode(data []byte) (*Code, error) {
+	enc := coding.String(data)
+
+	version := coding.Version(0)
+
+	for v := coding.Version(coding.MinVersion); v <= coding.MaxVersion; v++ {
+		if enc.Bits(v) <= v.DataBytes(coding.M)*8 {
+			version = v
+
+			break
+		}
+	}
+
+	if version == 0 {
+		return nil, fmt.Errorf("data too long for a QR code (%d bytes)", len(data))
+	}
+
+	var best *Code
+
+	bestPenalty := -1
+
+	for mask := range coding.Mask(8) {
+		c, err := encodeMasked(version, mask, enc)
+		if err != nil {
+			return nil, err
+		}
+
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = c, p
+		}
+	}
+
+	return best, nil
+}
+
+func encodeMasked(version coding.Version, mask coding.Mask, enc coding.Encoding) (*Code, error) {
+	plan, err := coding.NewPlan(version, coding.M, mask)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan QR code: %w", err)
+	}
+
+	code, err := plan.Encode(enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	return &Code{code: code, Version: int(version), Size: code.Size}, nil
+}
+
+// Dark returns whether the module at the given coordinates is dark. Modules
+// outside the symbol (in the quiet zone) are light.
+func (c *Code) Dark(x, y int) bool {
+	return c.code.Black(x, y)
+}
+
+// Terminal renders the code with Unicode block characters, two rows of
+// modules per line, drawing the light modules so that it scans on terminals
+// with a dark background.
+func (c *Code) Terminal() string {
+	sb := strings.Builder{}
+
+	for y := -quietZone; y < c.Size+quietZone; y += 2 {
+		for x := -quietZone; x < c.Size+quietZone; x++ {
+			top, bottom := !c.Dark(x, y), !c.Dark(x, y+1)
+
+			switch {
+			case top && bottom:
+				sb.WriteRune('█')
+			case top:
+				sb.WriteRune('▀')
+			case bottom:
+				sb.WriteRune('▄')
+			default:
+				sb.WriteRune(' ')
+			}
+		}
+
+		sb.WriteRune('\n')
+	}
+
+	return sb.String()
+}
+
+// Image renders the code as an image, with each module scale pixels wide
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+
+	size := (c.Size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, size, size))
+
+	for py := range size {
+		for px := range size {
+			v := color.Gray{Y: 0xff}
+			if c.Dark(px/scale-quietZone, py/scale-quietZone) {
+				v = color.Gray{Y: 0}
+			}
+
+			img.SetGray(px, py, v)
+		}
+	}
+
+	return img
+}
+
+// PNG writes the code as a PNG image, with each module scale pixels wide
+func (c *Code) PNG(w io.Writer, scale int) error {
+	return png.Encode(w, c.Image(scale))
+}
+
+// penalty - scores the symbol for features which make it hard to scan
+func (c *Code) penalty() int {
+	p := 0
+
+	for i := range c.Size {
+		p += linePenalty(func(j int) bool { return c.Dark(j, i) }, c.Size)
+		p += linePenalty(func(j int) bool { return c.Dark(i, j) }, c.Size)
+	}
+
+	dark := 0
+
+	for y := range c.Size {
+		for x := range c.Size {
+			m := c.Dark(x, y)
+			if m {
+				dark++
+			}
+
+			if x < c.Size-1 && y < c.Size-1 {
+				if m == c.Dark(x+1, y) && m == c.Dark(x, y+1) && m == c.Dark(x+1, y+1) {
+					p += 3
+				}
+			}
+		}
+	}
+
+	// 10 points for every 5% the dark proportion deviates from 50%
+	total := c.Size * c.Size
+	p += abs(dark*20-total*10) / total * 10
+
+	return p
+}
+
+// linePenalty - scores runs of the same colour, and patterns resembling
+// finders, in a single row or column
+func linePenalty(at func(int) bool, n int) int {
+	p := 0
+	run := 0
+
+	for i := range n {
+		if i > 0 && at(i) == at(i-1) {
+			run++
+		} else {
+			run = 1
+		}
+
+		if run == 5 {
+			p += 3
+		} else if run > 5 {
+			p++
+		}
+	}
+
+	finder := []bool{true, false, true, true, true, false, true}
+
+	for i := 0; i+len(finder) <= n; i++ {
+		match := true
+
+		for j, f := range finder {
+			if at(i+j) != f {
+				match = false
+
+				break
+			}
+		}
+
+		if match && (lightRun(at, i-4, i, n) || lightRun(at, i+7, i+11, n)) {
+			p += 40
+		}
+	}
+
+	return p
+}
+
+// lightRun - whether all modules in [from, to) are light, treating modules
+// outside the symbol as light
+func lightRun(at func(int) bool, from, to, n int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < n && at(i) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
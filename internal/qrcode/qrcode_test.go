@@ -0,0 +1,75 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"rsc.io/qr/coding"
+)
+
+func TestEncode(t *testing.T) {
+	c, err := Encode([]byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Version)
+	assert.Equal(t, 21, c.Size)
+
+	// finder pattern corners, and the dark module
+	assert.True(t, c.Dark(0, 0))
+	assert.True(t, c.Dark(20, 0))
+	assert.True(t, c.Dark(0, 20))
+	assert.False(t, c.Dark(7, 7))
+	assert.True(t, c.Dark(8, 13))
+	assert.False(t, c.Dark(-1, 0))
+
+	// a typical Wi-Fi payload needs a larger version
+	c, err = Encode([]byte("WIFI:T:WPA;S:" + strings.Repeat("s", 32) + ";P:" + strings.Repeat("p", 63) + ";;"))
+	require.NoError(t, err)
+	assert.Equal(t, 7, c.Version)
+
+	_, err = Encode(make([]byte, 3000))
+	assert.Error(t, err)
+}
+
+func TestEncode_BestMask(t *testing.T) {
+	data := []byte("WIFI:T:WPA;S:CODA-Guest;P:hunter22;;")
+
+	c, err := Encode(data)
+	require.NoError(t, err)
+
+	for mask := range coding.Mask(8) {
+		other, err := encodeMasked(coding.Version(c.Version), mask, coding.String(data))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, c.penalty(), other.penalty(), "mask %d", mask)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	c, err := Encode([]byte("hello"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(c.Terminal(), "\n"), "\n")
+	assert.Len(t, lines, (21+8+1)/2)
+
+	for _, l := range lines {
+		assert.Equal(t, 21+8, len([]rune(l)))
+	}
+
+	// the quiet zone is drawn light
+	assert.Equal(t, strings.Repeat("█", 29), lines[0])
+}
+
+func TestPNG(t *testing.T) {
+	c, err := Encode([]byte("hello"))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, c.PNG(buf, 4))
+
+	img, err := png.Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, (21+8)*4, img.Bounds().Dx())
+}
@@ -1,10 +1,12 @@
 package hitron
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -52,7 +54,7 @@ func (c *CableModem) Login(ctx context.Context) error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed with status %d: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
 	}
 
 	return nil
@@ -82,8 +84,46 @@ func (c *CableModem) Logout(ctx context.Context) error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed with status %d: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
 	}
 
 	return nil
 }
+
+// relogin logs in again after the session was found to be expired, notifying
+// the re-login hook (if any) of the outcome
+func (c *CableModem) relogin(ctx context.Context, cause error) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	slog.DebugContext(ctx, "session expired, logging in again", slog.Any("cause", cause))
+
+	err := c.Login(ctx)
+
+	if c.onRelogin != nil {
+		c.onRelogin(ctx, cause, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("re-login failed: %w (after: %w)", err, cause)
+	}
+
+	return nil
+}
+
+// sessionExpired - determines from a response whether the session is no longer
+// valid. The modem signals this in a few different ways, depending on the
+// firmware: a redirect to the login page, a 401 or 403 status, or simply
+// serving the (HTML) login page in place of the JSON response.
+func sessionExpired(status int, body []byte) bool {
+	switch {
+	case status >= 300 && status < 400:
+		return true
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return true
+	case status != http.StatusOK:
+		return false
+	}
+
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogin(t *testing.T) {
@@ -143,3 +144,92 @@ func TestLogout(t *testing.T) {
 		Value: "9999999999",
 	}, cookies[0])
 }
+
+func TestSessionExpired(t *testing.T) {
+	testdata := []struct {
+		body     string
+		status   int
+		expected bool
+	}{
+		{`{"errCode":"000","errMsg":""}`, http.StatusOK, false},
+		{`<html><head><title>Login</title></head></html>`, http.StatusOK, true},
+		{"\n  <!DOCTYPE html>", http.StatusOK, true},
+		{"", http.StatusFound, true},
+		{"", http.StatusUnauthorized, true},
+		{"", http.StatusForbidden, true},
+		{"", http.StatusInternalServerError, false},
+	}
+
+	for _, d := range testdata {
+		assert.Equal(t, d.expected, sessionExpired(d.status, []byte(d.body)), "status %d, body %q", d.status, d.body)
+	}
+}
+
+//nolint:funlen
+func TestAutoRelogin(t *testing.T) {
+	logins := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Users/Login":
+			logins++
+
+			http.SetCookie(w, &http.Cookie{Name: "PHPSESSID", Value: "valid", Path: "/"})
+		case "/Router/Location":
+			cookie, err := r.Cookie("PHPSESSID")
+			if err != nil || cookie.Value != "valid" {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"errCode":"000","errMsg":"","locationText":"Basement"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	newModem := func(auto bool) *CableModem {
+		hc := srv.Client()
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+
+		hc.Jar = jar
+
+		return &CableModem{base: mustParse(srv.URL), hc: hc, autoRelogin: auto}
+	}
+
+	ctx := context.Background()
+
+	// without the option, the error is returned as-is
+	d := newModem(false)
+	_, err := d.RouterLocation(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, 0, logins)
+
+	// with the option, the client logs in and retries once
+	d = newModem(true)
+
+	var hookCause, hookErr error
+
+	hookCalls := 0
+	d.onRelogin = func(_ context.Context, cause, err error) {
+		hookCalls++
+		hookCause, hookErr = cause, err
+	}
+
+	p, err := d.RouterLocation(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Basement", p.LocationText)
+	assert.Equal(t, 1, logins)
+	assert.Equal(t, 1, hookCalls)
+	assert.ErrorIs(t, hookCause, ErrUnauthorized)
+	assert.NoError(t, hookErr)
+
+	// the session is now valid, so no further logins are needed
+	_, err = d.RouterLocation(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, logins)
+	assert.Equal(t, 1, hookCalls)
+}
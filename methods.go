@@ -69,6 +69,13 @@ func (c *CableModem) DDNS(ctx context.Context) (out DDNS, err error) {
 	return out, err
 }
 
+// DHCPReservation - /DHCP/Reservation
+func (c *CableModem) DHCPReservation(ctx context.Context) (out DHCPReservation, err error) {
+	err = c.getJSON(ctx, "/DHCP/Reservation", &out)
+
+	return out, err
+}
+
 // DNS - /DNS
 func (c *CableModem) DNS(ctx context.Context) (out DNS, err error) {
 	err = c.getJSON(ctx, "/DNS", &out)
@@ -76,6 +83,90 @@ func (c *CableModem) DNS(ctx context.Context) (out DNS, err error) {
 	return out, err
 }
 
+// FirewallDeviceFilterRules - /Firewall/DeviceFilter/Rules
+func (c *CableModem) FirewallDeviceFilterRules(ctx context.Context) (out FirewallDeviceFilterRules, err error) {
+	err = c.getJSON(ctx, "/Firewall/DeviceFilter/Rules", &out)
+
+	return out, err
+}
+
+// FirewallDeviceFilterType - /Firewall/DeviceFilter/Type
+func (c *CableModem) FirewallDeviceFilterType(ctx context.Context) (out FirewallDeviceFilterType, err error) {
+	err = c.getJSON(ctx, "/Firewall/DeviceFilter/Type", &out)
+
+	return out, err
+}
+
+// FirewallInboundRules - /Firewall/Inbound/Rules
+func (c *CableModem) FirewallInboundRules(ctx context.Context) (out FirewallInboundRules, err error) {
+	err = c.getJSON(ctx, "/Firewall/Inbound/Rules", &out)
+
+	return out, err
+}
+
+// FirewallInboundStatus - /Firewall/Inbound/Status
+func (c *CableModem) FirewallInboundStatus(ctx context.Context) (out FirewallInboundStatus, err error) {
+	err = c.getJSON(ctx, "/Firewall/Inbound/Status", &out)
+
+	return out, err
+}
+
+// FirewallKeywordFilterRules - /Firewall/KeywordFilter/Rules
+func (c *CableModem) FirewallKeywordFilterRules(ctx context.Context) (out FirewallKeywordFilterRules, err error) {
+	err = c.getJSON(ctx, "/Firewall/KeywordFilter/Rules", &out)
+
+	return out, err
+}
+
+// FirewallKeywordFilterStatus - /Firewall/KeywordFilter/Status
+func (c *CableModem) FirewallKeywordFilterStatus(ctx context.Context) (out FirewallKeywordFilterStatus, err error) {
+	err = c.getJSON(ctx, "/Firewall/KeywordFilter/Status", &out)
+
+	return out, err
+}
+
+// FirewallKeywordFilterTrustRules - /Firewall/KeywordFilter/TrustRules
+func (c *CableModem) FirewallKeywordFilterTrustRules(ctx context.Context) (out FirewallKeywordFilterTrustRules, err error) {
+	err = c.getJSON(ctx, "/Firewall/KeywordFilter/TrustRules", &out)
+
+	return out, err
+}
+
+// FirewallLevel - /Firewall/Level
+func (c *CableModem) FirewallLevel(ctx context.Context) (out FirewallLevel, err error) {
+	err = c.getJSON(ctx, "/Firewall/Level", &out)
+
+	return out, err
+}
+
+// FirewallServiceFilterRules - /Firewall/ServiceFilter/Rules
+func (c *CableModem) FirewallServiceFilterRules(ctx context.Context) (out FirewallServiceFilterRules, err error) {
+	err = c.getJSON(ctx, "/Firewall/ServiceFilter/Rules", &out)
+
+	return out, err
+}
+
+// FirewallServiceFilterStatus - /Firewall/ServiceFilter/Status
+func (c *CableModem) FirewallServiceFilterStatus(ctx context.Context) (out FirewallServiceFilterStatus, err error) {
+	err = c.getJSON(ctx, "/Firewall/ServiceFilter/Status", &out)
+
+	return out, err
+}
+
+// FirewallServiceFilterTrustRules - /Firewall/ServiceFilter/TrustRules
+func (c *CableModem) FirewallServiceFilterTrustRules(ctx context.Context) (out FirewallServiceFilterTrustRules, err error) {
+	err = c.getJSON(ctx, "/Firewall/ServiceFilter/TrustRules", &out)
+
+	return out, err
+}
+
+// FirewallVPN - /Firewall/VPN
+func (c *CableModem) FirewallVPN(ctx context.Context) (out FirewallVPN, err error) {
+	err = c.getJSON(ctx, "/Firewall/VPN", &out)
+
+	return out, err
+}
+
 // Hosts - /Hosts
 func (c *CableModem) Hosts(ctx context.Context) (out Hosts, err error) {
 	err = c.getJSON(ctx, "/Hosts", &out)
@@ -0,0 +1,98 @@
+package hitron
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// UpdateTime - changes the time zone, SNTP, and daylight saving settings, and
+// returns the refreshed settings. Time zones the modem doesn't list are
+// replaced with one it does which has the same offsets (see SupportedLocation).
+// The modem's time zone is left as-is unless TZ is changed.
+func (c *CableModem) UpdateTime(ctx context.Context, changes TimeChanges) (Time, error) {
+	t, err := c.Time(ctx)
+	if err != nil {
+		return Time{}, err
+	}
+
+	t = changes.apply(t)
+
+	if err = t.Validate(); err != nil {
+		return Time{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, "/Time", t)
+	if err != nil {
+		return Time{}, fmt.Errorf("failed to update time settings: %w", err)
+	}
+
+	return c.Time(ctx)
+}
+
+// SetTimeZone - sets the modem's time zone, which is used for log entry
+// timestamps among other things
+func (c *CableModem) SetTimeZone(ctx context.Context, loc *time.Location) (Time, error) {
+	return c.UpdateTime(ctx, TimeChanges{TZ: loc})
+}
+
+// UpdateDNS - changes the LAN DNS settings, and returns the refreshed
+// settings. The settings are read back after writing, and an error is returned
+// if any of the changes didn't take effect.
+func (c *CableModem) UpdateDNS(ctx context.Context, changes DNSChanges) (DNS, error) {
+	dns, err := c.DNS(ctx)
+	if err != nil {
+		return DNS{}, err
+	}
+
+	dns = changes.apply(dns)
+
+	if err = dns.Validate(); err != nil {
+		return DNS{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, "/DNS", dns)
+	if err != nil {
+		return DNS{}, fmt.Errorf("failed to update DNS settings: %w", err)
+	}
+
+	out, err := c.DNS(ctx)
+	if err != nil {
+		return DNS{}, err
+	}
+
+	if field := changes.unapplied(out); field != "" {
+		return out, fmt.Errorf("DNS settings did not take effect: %s was not changed", field)
+	}
+
+	return out, nil
+}
+
+// UpdateDDNS - changes the settings of the modem's dynamic DNS client, and
+// returns the refreshed settings (including the last update status, if the
+// firmware reports one).
+func (c *CableModem) UpdateDDNS(ctx context.Context, changes DDNSChanges) (DDNS, error) {
+	ddns, err := c.DDNS(ctx)
+	if err != nil {
+		return DDNS{}, err
+	}
+
+	ddns = changes.apply(ddns)
+
+	if err = ddns.Validate(); err != nil {
+		return DDNS{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, "/DDNS", ddns)
+	if err != nil {
+		return DDNS{}, fmt.Errorf("failed to update DDNS settings: %w", err)
+	}
+
+	return c.DDNS(ctx)
+}
+
+// SetDDNSEnabled - enables or disables the modem's dynamic DNS client
+func (c *CableModem) SetDDNSEnabled(ctx context.Context, enable bool) (DDNS, error) {
+	return c.UpdateDDNS(ctx, DDNSChanges{Enable: &enable})
+}
@@ -0,0 +1,350 @@
+package hitron
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dnsBody = `{"errCode":"000","errMsg":"","lanDnsOnOff":"ON","landns1":"192.168.0.1",
+	"landns2":"","dnsProxyOnOff":"ON","domainSuffix":"ht.home","proxyName1":"","proxyName2":""}`
+
+func TestDNSValidate(t *testing.T) {
+	valid := DNS{
+		LanDNS1:      net.ParseIP("192.168.0.53"),
+		LanDNS2:      net.ParseIP("2001:db8::53"),
+		DomainSuffix: "home.example.com",
+	}
+	assert.NoError(t, valid.Validate())
+
+	d := valid
+	d.LanDNS1 = nil
+	assert.Error(t, d.Validate())
+
+	d.AutoEnable = true
+	assert.NoError(t, d.Validate())
+
+	for _, ip := range []string{"0.0.0.0", "::", "224.0.0.1", "255.255.255.255"} {
+		d = valid
+		d.LanDNS2 = net.ParseIP(ip)
+		assert.Error(t, d.Validate(), ip)
+	}
+
+	d = valid
+	d.LanDNS1 = net.IP{1, 2, 3}
+	assert.Error(t, d.Validate())
+
+	for _, name := range []string{"-home", "home-.lan", "ho_me", "a..b"} {
+		d = valid
+		d.DomainSuffix = name
+		assert.Error(t, d.Validate(), name)
+	}
+}
+
+func TestDNSMarshalJSON(t *testing.T) {
+	in := DNS{
+		AutoEnable:   false,
+		ProxyEnable:  true,
+		LanDNS1:      net.ParseIP("192.168.0.53"),
+		DomainSuffix: "lan",
+	}
+
+	b, err := in.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"lanDnsOnOff":"OFF","landns1":"192.168.0.53","landns2":"",
+		"dnsProxyOnOff":"ON","domainSuffix":"lan","proxyName1":"","proxyName2":""}`, string(b))
+
+	out := DNS{}
+	require.NoError(t, out.UnmarshalJSON(b))
+	assert.True(t, in.LanDNS1.Equal(out.LanDNS1))
+	assert.Equal(t, in.DomainSuffix, out.DomainSuffix)
+	assert.Equal(t, in.ProxyEnable, out.ProxyEnable)
+}
+
+func TestUpdateDNS(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/DNS": dnsBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	bad := "not a domain!"
+	_, err := d.UpdateDNS(ctx, DNSChanges{DomainSuffix: &bad})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	// the settings are unchanged on this server, so the write can't be
+	// confirmed
+	pihole := net.ParseIP("192.168.0.53")
+	off := false
+
+	_, err = d.UpdateDNS(ctx, DNSChanges{LanDNS1: &pihole, AutoEnable: &off})
+	assert.ErrorContains(t, err, "LanDNS1 was not changed")
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/DNS", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"lanDnsOnOff":"OFF","landns1":"192.168.0.53","landns2":"",
+		"dnsProxyOnOff":"ON","domainSuffix":"ht.home","proxyName1":"","proxyName2":""}`, w[0].Model)
+
+	// changes which are already in effect are confirmed
+	suffix := "ht.home"
+	_, err = d.UpdateDNS(ctx, DNSChanges{DomainSuffix: &suffix})
+	assert.NoError(t, err)
+}
+
+const ddnsBody = `{"errCode":"000","errMsg":"","ddnsOnOff":"OFF","ddnsSrvProvider":1,
+	"ddnsUsername":"","ddnsPassword":"","ddnsHostnames":"","ddnsUpdateInterval":"604800",
+	"ddnsStatus":"Update succeeded"}`
+
+func TestDDNSValidate(t *testing.T) {
+	valid := DDNS{
+		Enable:         true,
+		Provider:       "default@no-ip.com",
+		Username:       "user",
+		Password:       "pass",
+		Hostname:       "home.example.com, www.example.com",
+		UpdateInterval: 24 * time.Hour,
+	}
+	assert.NoError(t, valid.Validate())
+
+	d := valid
+	d.Provider = "default@example.com"
+	assert.ErrorContains(t, d.Validate(), "default@no-ip.com")
+
+	d = valid
+	d.UpdateInterval = 1500 * time.Millisecond
+	assert.Error(t, d.Validate())
+
+	d = valid
+	d.Hostname = ""
+	assert.Error(t, d.Validate())
+
+	d = valid
+	d.Hostname = "home.example.com,"
+	assert.Error(t, d.Validate())
+
+	d = valid
+	d.Password = ""
+	assert.Error(t, d.Validate())
+
+	// the provider, credentials, and hostname aren't needed when disabled
+	d.Enable = false
+	d.Hostname = ""
+	d.Username = ""
+	d.Provider = ""
+	assert.NoError(t, d.Validate())
+}
+
+func TestDDNSMarshalJSON(t *testing.T) {
+	in := DDNS{
+		Enable:         true,
+		Provider:       "ipv6tb@he.net",
+		Username:       "user",
+		Password:       "pass",
+		Hostname:       "home.example.com",
+		UpdateInterval: time.Hour,
+	}
+
+	b, err := in.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ddnsOnOff":"ON","ddnsSrvProvider":10,"ddnsUsername":"user",
+		"ddnsPassword":"pass","ddnsHostnames":"home.example.com","ddnsUpdateInterval":"3600"}`, string(b))
+
+	out := DDNS{}
+	require.NoError(t, out.UnmarshalJSON(b))
+	assert.Equal(t, in, out)
+}
+
+func TestDDNSProviders(t *testing.T) {
+	p := DDNSProviders()
+	assert.Len(t, p, 11)
+	assert.Equal(t, "dyndns@dyndns.org", p[0])
+	assert.Equal(t, "default@dynsip.org", p[10])
+}
+
+func TestUpdateDDNS(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/DDNS": ddnsBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.SetDDNSEnabled(ctx, true)
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	provider := "default@no-ip.com"
+	user, pass, host := "user", "pass", "home.example.com"
+	enable := true
+
+	out, err := d.UpdateDDNS(ctx, DDNSChanges{
+		Provider: &provider, Username: &user, Password: &pass, Hostname: &host, Enable: &enable,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Update succeeded", out.Status)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/DDNS", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"ddnsOnOff":"ON","ddnsSrvProvider":4,"ddnsUsername":"user",
+		"ddnsPassword":"pass","ddnsHostnames":"home.example.com","ddnsUpdateInterval":"604800"}`, w[0].Model)
+}
+
+func TestSetDDNSEnabled_UnknownProvider(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","ddnsOnOff":"ON","ddnsSrvProvider":0,
+		"ddnsUsername":"","ddnsPassword":"","ddnsHostnames":"","ddnsUpdateInterval":"604800"}`
+
+	srv, writes := writeServer(t, map[string]string{"/DDNS": body})
+	d := testCableModem(srv)
+
+	_, err := d.SetDDNSEnabled(context.Background(), false)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.JSONEq(t, `{"ddnsOnOff":"OFF","ddnsSrvProvider":0,"ddnsUsername":"",
+		"ddnsPassword":"","ddnsHostnames":"","ddnsUpdateInterval":"604800"}`, w[0].Model)
+}
+
+func TestLocationToTZ(t *testing.T) {
+	load := func(name string) *time.Location {
+		l, err := time.LoadLocation(name)
+		require.NoError(t, err)
+
+		return l
+	}
+
+	testdata := []struct {
+		loc      *time.Location
+		expected string
+	}{
+		{time.UTC, "0"},
+		{load("Etc/UTC"), "0"},
+		{load("America/New_York"), "7_2_1"},
+		{load("Asia/Taipei"), "22_2_0"},
+		// not listed by the modem, so matched by offset and DST
+		{load("America/Toronto"), "7_2_1"},
+		{load("Europe/London"), "13_2_1"},
+		{load("Africa/Abidjan"), "0"},
+		{load("Europe/Paris"), "14_2_1"},
+		{load("Asia/Dubai"), "17_1_0"},
+		{load("Australia/Melbourne"), "24_2_1"},
+	}
+
+	for _, d := range testdata {
+		tz, err := locationToTZ(d.loc)
+		require.NoError(t, err, d.loc)
+		assert.Equal(t, d.expected, tz, d.loc)
+	}
+
+	// round-trips for every listed zone
+	for code, name := range timeZones {
+		tz, err := locationToTZ(load(name))
+		require.NoError(t, err)
+		assert.Equal(t, code, tz, name)
+	}
+
+	_, err := locationToTZ(load("Asia/Kathmandu"))
+	assert.Error(t, err)
+
+	// same offsets as Halifax, but DST is in the southern summer
+	_, err = locationToTZ(load("America/Santiago"))
+	assert.Error(t, err)
+
+	_, err = locationToTZ(nil)
+	assert.Error(t, err)
+
+	l, err := SupportedLocation(load("America/Toronto"))
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", l.String())
+
+	l, err = SupportedLocation(load("Europe/London"))
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/London", l.String())
+
+	l, err = SupportedLocation(time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "Etc/UTC", l.String())
+}
+
+func TestTimeValidate(t *testing.T) {
+	valid := Time{TZ: time.UTC, SNTPServer: "pool.ntp.org", Enable: true}
+	assert.NoError(t, valid.Validate())
+
+	s := valid
+	s.SNTPServer = "192.168.0.1"
+	assert.NoError(t, s.Validate())
+
+	s.SNTPServer = ""
+	assert.Error(t, s.Validate())
+
+	s.SNTPServer = "not a host"
+	assert.Error(t, s.Validate())
+
+	s = valid
+	s.TZ = nil
+	assert.Error(t, s.Validate())
+
+	s = valid
+	s.DaylightTime = -1
+	assert.Error(t, s.Validate())
+}
+
+func TestUpdateTime(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Time": `{"errCode":"000","errMsg":"","sntpOnOff":"ON","sntpTimeZone":"13_1_0",
+			"sntpSrvName":"pool.ntp.org","daylightOnOff":"OFF","daylightTime":"60"}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	kathmandu, err := time.LoadLocation("Asia/Kathmandu")
+	require.NoError(t, err)
+
+	_, err = d.SetTimeZone(ctx, kathmandu)
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	toronto, err := time.LoadLocation("America/Toronto")
+	require.NoError(t, err)
+
+	server := "time.example.com"
+	dst := true
+
+	_, err = d.UpdateTime(ctx, TimeChanges{TZ: toronto, SNTPServer: &server, Daylight: &dst})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Time", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"sntpOnOff":"ON","sntpTimeZone":"7_2_1","sntpSrvName":"time.example.com",
+		"daylightOnOff":"ON","daylightTime":"60"}`, w[0].Model)
+}
+
+func TestUpdateTime_KeepsZone(t *testing.T) {
+	for _, zone := range []string{"13_2_1", "99_1_0"} {
+		srv, writes := writeServer(t, map[string]string{
+			"/Time": `{"errCode":"000","errMsg":"","sntpOnOff":"ON","sntpTimeZone":"` + zone + `",
+				"sntpSrvName":"pool.ntp.org","daylightOnOff":"ON","daylightTime":"60"}`,
+		})
+		d := testCableModem(srv)
+
+		server := "time.example.com"
+
+		_, err := d.UpdateTime(context.Background(), TimeChanges{SNTPServer: &server})
+		require.NoError(t, err, zone)
+
+		w := writes()
+		require.Len(t, w, 1)
+		assert.JSONEq(t, `{"sntpOnOff":"ON","sntpTimeZone":"`+zone+`","sntpSrvName":"time.example.com",
+			"daylightOnOff":"ON","daylightTime":"60"}`, w[0].Model)
+	}
+}
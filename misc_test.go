@@ -12,7 +12,7 @@ import (
 func TestTime(t *testing.T) {
 	body := `{"errCode":"000","errMsg":"",
 		"sntpOnOff":"ON","sntpTimeZone":"13_1_0","sntpSrvName":"pool.ntp.org",
-		"daylightOnOff":"ON","daylightTime":"0"
+		"daylightOnOff":"ON","daylightTime":"60"
 	}`
 
 	srv := staticResponseServer(t, body)
@@ -27,9 +27,10 @@ func TestTime(t *testing.T) {
 		Error:        NoError,
 		Enable:       true,
 		Daylight:     true,
-		DaylightTime: 0,
+		DaylightTime: 60,
 		TZ:           tz,
 		SNTPServer:   "pool.ntp.org",
+		zone:         "13_1_0",
 	}, p)
 }
 
@@ -87,6 +88,33 @@ func TestDDNS(t *testing.T) {
 	}, p)
 }
 
+func TestDHCPReservation(t *testing.T) {
+	body := `{"errCode":"000","errMsg":"","Rules_List":[
+		{"id":"1","hostName":"console","macAddr":"13:37:be:ef:ca:fe","ip":"192.168.0.16"}
+	]}`
+
+	srv := staticResponseServer(t, body)
+	d := testCableModem(srv)
+
+	p, err := d.DHCPReservation(context.Background())
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, DHCPReservation{
+		Error: NoError,
+		Rules: []DHCPReservationRule{
+			{
+				ID:      1,
+				Name:    "console",
+				MacAddr: net.HardwareAddr{0x13, 0x37, 0xbe, 0xef, 0xca, 0xfe},
+				IP:      net.ParseIP("192.168.0.16"),
+			},
+		},
+	}, p)
+
+	assert.True(t, p.Reserved(net.ParseIP("192.168.0.16")))
+	assert.False(t, p.Reserved(net.ParseIP("192.168.0.17")))
+}
+
 func TestHosts(t *testing.T) {
 	body := `{"errCode":"000","errMsg":"","HostNumberOfEntries":2,
 		"Hosts_List":[
@@ -153,3 +181,37 @@ func TestUsersCSRF(t *testing.T) {
 		CSRF:  "abcdefgh1234.4321abcdefgh",
 	}, p)
 }
+
+func TestHostsLookup(t *testing.T) {
+	hosts := Hosts{Hosts: []Host{
+		{Name: "tablet", MacAddr: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe}},
+		{Name: "Unknown", MacAddr: net.HardwareAddr{0x13, 0x37, 0xbe, 0xef, 0xca, 0xfe}},
+		{Name: "Unknown", MacAddr: net.HardwareAddr{0x13, 0x37, 0xbe, 0xef, 0xca, 0x11}},
+	}}
+
+	h, err := hosts.Lookup("Tablet")
+	assert.NoError(t, err)
+	assert.Equal(t, hosts.Hosts[0], h)
+
+	h, err = hosts.Lookup("13:37:BE:EF:CA:FE")
+	assert.NoError(t, err)
+	assert.Equal(t, hosts.Hosts[1], h)
+
+	// offline devices can still be given by MAC address
+	h, err = hosts.Lookup("02:00:00:00:00:01")
+	assert.NoError(t, err)
+	assert.Equal(t, Host{MacAddr: net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01}}, h)
+
+	_, err = hosts.Lookup("phone")
+	assert.ErrorContains(t, err, `no host named "phone"`)
+
+	_, err = hosts.Lookup("unknown")
+	assert.ErrorContains(t, err, "13:37:be:ef:ca:fe, 13:37:be:ef:ca:11")
+
+	h, ok := hosts.Find(net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe})
+	assert.True(t, ok)
+	assert.Equal(t, "tablet", h.Name)
+
+	_, ok = hosts.Find(net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01})
+	assert.False(t, ok)
+}
@@ -1,10 +1,12 @@
 package hitron
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,11 +38,15 @@ var NoError = Error{Code: "000", Message: ""}
 // Time -
 type Time struct {
 	Error
-	TZ           *time.Location
+	TZ           *time.Location // nil if the modem reports an unknown zone
 	SNTPServer   string
 	DaylightTime int
 	Enable       bool
 	Daylight     bool
+
+	// zone is the modem's identifier for TZ, which is sent back unchanged
+	// unless TZ is changed
+	zone string
 }
 
 // UnmarshalJSON - implements json.Unmarshaler
@@ -63,12 +69,99 @@ func (s *Time) UnmarshalJSON(b []byte) error {
 	s.Enable = raw.SntpOnOff == on
 	s.Daylight = raw.DaylightOnOff == on
 	s.SNTPServer = raw.SntpSrvName
+	s.DaylightTime, _ = strconv.Atoi(raw.DaylightTime)
+
+	s.zone = raw.SntpTimeZone
+
+	if _, ok := timeZones[raw.SntpTimeZone]; ok {
+		s.TZ, _ = tzToLocation(raw.SntpTimeZone)
+	}
+
+	return nil
+}
+
+// TimeChanges - changes to make to the time settings with UpdateTime. Nil
+// fields are left unchanged.
+type TimeChanges struct {
+	TZ           *time.Location
+	SNTPServer   *string
+	DaylightTime *int
+	Enable       *bool
+	Daylight     *bool
+}
+
+// apply returns a copy of the time settings with the changes made
+func (c TimeChanges) apply(s Time) Time {
+	if c.TZ != nil {
+		s.TZ = c.TZ
+		s.zone = ""
+	}
+
+	if c.DaylightTime != nil {
+		s.DaylightTime = *c.DaylightTime
+	}
+
+	setString(&s.SNTPServer, c.SNTPServer)
+	setBool(&s.Enable, c.Enable)
+	setBool(&s.Daylight, c.Daylight)
+
+	return s
+}
+
+// Validate checks that the time settings can be sent to the modem
+func (s Time) Validate() error {
+	if s.zone == "" {
+		if _, err := locationToTZ(s.TZ); err != nil {
+			return fmt.Errorf("invalid time settings: %w", err)
+		}
+	}
 
-	s.TZ, _ = tzToLocation(raw.SntpTimeZone)
+	if s.Enable && s.SNTPServer == "" {
+		return fmt.Errorf("invalid time settings: SNTPServer must be set when SNTP is enabled")
+	}
+
+	if s.SNTPServer != "" && net.ParseIP(s.SNTPServer) == nil {
+		if err := validateDomainName(s.SNTPServer); err != nil {
+			return fmt.Errorf("invalid time settings: SNTPServer: %w", err)
+		}
+	}
+
+	if s.DaylightTime < 0 {
+		return fmt.Errorf("invalid time settings: DaylightTime must not be negative")
+	}
 
 	return nil
 }
 
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s Time) MarshalJSON() ([]byte, error) {
+	tz := s.zone
+	if tz == "" {
+		var err error
+
+		tz, err = locationToTZ(s.TZ)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	raw := struct {
+		SntpOnOff     string `json:"sntpOnOff"`
+		SntpTimeZone  string `json:"sntpTimeZone"`
+		SntpSrvName   string `json:"sntpSrvName"`
+		DaylightOnOff string `json:"daylightOnOff"`
+		DaylightTime  string `json:"daylightTime"`
+	}{
+		SntpOnOff:     onOff(s.Enable),
+		SntpTimeZone:  tz,
+		SntpSrvName:   s.SNTPServer,
+		DaylightOnOff: onOff(s.Daylight),
+		DaylightTime:  strconv.Itoa(s.DaylightTime),
+	}
+
+	return json.Marshal(raw)
+}
+
 // DNS -
 type DNS struct {
 	Error
@@ -111,6 +204,165 @@ func (s *DNS) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// DNSChanges - changes to make to the LAN DNS settings with UpdateDNS. Nil
+// fields are left unchanged. To clear LanDNS2, point it at a nil net.IP.
+type DNSChanges struct {
+	LanDNS1      *net.IP
+	LanDNS2      *net.IP
+	DomainSuffix *string
+	ProxyName1   *string
+	ProxyName2   *string
+	AutoEnable   *bool
+	ProxyEnable  *bool
+}
+
+// apply returns a copy of the DNS settings with the changes made
+func (c DNSChanges) apply(s DNS) DNS {
+	if c.LanDNS1 != nil {
+		s.LanDNS1 = *c.LanDNS1
+	}
+
+	if c.LanDNS2 != nil {
+		s.LanDNS2 = *c.LanDNS2
+	}
+
+	setString(&s.DomainSuffix, c.DomainSuffix)
+	setString(&s.ProxyName1, c.ProxyName1)
+	setString(&s.ProxyName2, c.ProxyName2)
+	setBool(&s.AutoEnable, c.AutoEnable)
+	setBool(&s.ProxyEnable, c.ProxyEnable)
+
+	return s
+}
+
+// unapplied returns the name of the first change which isn't reflected in the
+// given settings, or "" if they all are
+func (c DNSChanges) unapplied(s DNS) string {
+	want := c.apply(s)
+
+	switch {
+	case !want.LanDNS1.Equal(s.LanDNS1):
+		return "LanDNS1"
+	case !want.LanDNS2.Equal(s.LanDNS2):
+		return "LanDNS2"
+	case want.DomainSuffix != s.DomainSuffix:
+		return "DomainSuffix"
+	case want.ProxyName1 != s.ProxyName1:
+		return "ProxyName1"
+	case want.ProxyName2 != s.ProxyName2:
+		return "ProxyName2"
+	case want.AutoEnable != s.AutoEnable:
+		return "AutoEnable"
+	case want.ProxyEnable != s.ProxyEnable:
+		return "ProxyEnable"
+	}
+
+	return ""
+}
+
+// Validate checks that the DNS settings can be sent to the modem. Servers may
+// be IPv4 or IPv6 addresses, and the first is required unless servers are set
+// automatically.
+func (s DNS) Validate() error {
+	if !s.AutoEnable && len(s.LanDNS1) == 0 {
+		return fmt.Errorf("invalid DNS settings: LanDNS1 must be set when AutoEnable is off")
+	}
+
+	for i, ip := range []net.IP{s.LanDNS1, s.LanDNS2} {
+		if err := validateDNSServer(ip); err != nil {
+			return fmt.Errorf("invalid DNS settings: LanDNS%d: %w", i+1, err)
+		}
+	}
+
+	names := []struct{ field, value string }{
+		{"DomainSuffix", s.DomainSuffix},
+		{"ProxyName1", s.ProxyName1},
+		{"ProxyName2", s.ProxyName2},
+	}
+
+	for _, n := range names {
+		if n.value == "" {
+			continue
+		}
+
+		if err := validateDomainName(n.value); err != nil {
+			return fmt.Errorf("invalid DNS settings: %s: %w", n.field, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDNSServer checks that an optional DNS server address is a usable
+// unicast IPv4 or IPv6 address
+func validateDNSServer(ip net.IP) error {
+	switch {
+	case len(ip) == 0:
+		return nil
+	case len(ip) != net.IPv4len && len(ip) != net.IPv6len:
+		return fmt.Errorf("invalid address %q", []byte(ip))
+	case ip.IsUnspecified(), ip.IsMulticast(), ip.Equal(net.IPv4bcast):
+		return fmt.Errorf("invalid address %s: must be a unicast address", ip)
+	}
+
+	return nil
+}
+
+// validateDomainName checks that a name is a syntactically valid DNS name
+func validateDomainName(name string) error {
+	if len(name) > 253 {
+		return fmt.Errorf("invalid domain name %q: must be at most 253 characters", name)
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if l := len(label); l < 1 || l > 63 {
+			return fmt.Errorf("invalid domain name %q: labels must be 1-63 characters", name)
+		}
+
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid domain name %q: labels must not start or end with '-'", name)
+		}
+
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+				return fmt.Errorf("invalid domain name %q: %q is not allowed", name, r)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s DNS) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		LanDNSOnOff   string `json:"lanDnsOnOff"`
+		LanDNS1       string `json:"landns1"`
+		LanDNS2       string `json:"landns2"`
+		DNSProxyOnOff string `json:"dnsProxyOnOff"`
+		DomainSuffix  string `json:"domainSuffix"`
+		ProxyName1    string `json:"proxyName1"`
+		ProxyName2    string `json:"proxyName2"`
+	}{
+		LanDNSOnOff:   onOff(s.AutoEnable),
+		DNSProxyOnOff: onOff(s.ProxyEnable),
+		DomainSuffix:  s.DomainSuffix,
+		ProxyName1:    s.ProxyName1,
+		ProxyName2:    s.ProxyName2,
+	}
+
+	// the firmware uses an empty string for an unset server
+	if len(s.LanDNS1) > 0 {
+		raw.LanDNS1 = s.LanDNS1.String()
+	}
+
+	if len(s.LanDNS2) > 0 {
+		raw.LanDNS2 = s.LanDNS2.String()
+	}
+
+	return json.Marshal(raw)
+}
+
 // DDNS -
 type DDNS struct {
 	Error
@@ -118,10 +370,50 @@ type DDNS struct {
 	Username       string
 	Password       string
 	Hostname       string
+	Status         string // result of the last update, if the firmware reports it
 	UpdateInterval time.Duration
 	Enable         bool
 }
 
+// ddnsProviders - the DDNS services the firmware supports, by the modem's code
+//
+//nolint:gochecknoglobals
+var ddnsProviders = map[int]string{
+	1:  "dyndns@dyndns.org",
+	2:  "default@freedns.afraid.org",
+	3:  "default@zoneedit.com",
+	4:  "default@no-ip.com",
+	5:  "default@easydns.com",
+	6:  "default@tzo.com",
+	7:  "dyndns@3322.org",
+	8:  "default@sitelutions.com",
+	9:  "default@dnsomatic.com",
+	10: "ipv6tb@he.net",
+	11: "default@dynsip.org",
+}
+
+// ddnsProviderCode returns the modem's code for the provider, if it supports it
+func ddnsProviderCode(p string) (int, bool) {
+	for code, provider := range ddnsProviders {
+		if provider == p {
+			return code, true
+		}
+	}
+
+	return 0, false
+}
+
+// DDNSProviders returns the names of the DDNS providers the firmware supports,
+// in the form used by DDNS.Provider
+func DDNSProviders() []string {
+	out := make([]string, 0, len(ddnsProviders))
+	for code := 1; code <= len(ddnsProviders); code++ {
+		out = append(out, ddnsProviders[code])
+	}
+
+	return out
+}
+
 // UnmarshalJSON - implements json.Unmarshaler
 func (s *DDNS) UnmarshalJSON(b []byte) error {
 	raw := struct {
@@ -131,6 +423,7 @@ func (s *DDNS) UnmarshalJSON(b []byte) error {
 		DDNSPassword       string
 		DDNSHostnames      string
 		DDNSUpdateInterval string
+		DDNSStatus         string
 		DDNSSrvProvider    int
 	}{}
 
@@ -143,6 +436,7 @@ func (s *DDNS) UnmarshalJSON(b []byte) error {
 	s.Username = raw.DDNSUsername
 	s.Password = raw.DDNSPassword
 	s.Hostname = raw.DDNSHostnames
+	s.Status = raw.DDNSStatus
 
 	s.Enable = raw.DDNSOnOff == on
 
@@ -150,24 +444,142 @@ func (s *DDNS) UnmarshalJSON(b []byte) error {
 	d, _ := strconv.Atoi(raw.DDNSUpdateInterval)
 	s.UpdateInterval = time.Duration(d) * time.Second
 
-	providers := map[int]string{
-		1:  "dyndns@dyndns.org",
-		2:  "default@freedns.afraid.org",
-		3:  "default@zoneedit.com",
-		4:  "default@no-ip.com",
-		5:  "default@easydns.com",
-		6:  "default@tzo.com",
-		7:  "dyndns@3322.org",
-		8:  "default@sitelutions.com",
-		9:  "default@dnsomatic.com",
-		10: "ipv6tb@he.net",
-		11: "default@dynsip.org",
-	}
-	s.Provider = providers[raw.DDNSSrvProvider]
+	s.Provider = ddnsProviders[raw.DDNSSrvProvider]
+
+	return nil
+}
+
+// DDNSChanges - changes to make to the DDNS settings with UpdateDDNS. Nil
+// fields are left unchanged.
+type DDNSChanges struct {
+	Provider       *string
+	Username       *string
+	Password       *string
+	Hostname       *string
+	UpdateInterval *time.Duration
+	Enable         *bool
+}
+
+// apply returns a copy of the DDNS settings with the changes made
+func (c DDNSChanges) apply(s DDNS) DDNS {
+	setString(&s.Provider, c.Provider)
+	setString(&s.Username, c.Username)
+	setString(&s.Password, c.Password)
+	setString(&s.Hostname, c.Hostname)
+	setBool(&s.Enable, c.Enable)
+
+	if c.UpdateInterval != nil {
+		s.UpdateInterval = *c.UpdateInterval
+	}
+
+	return s
+}
+
+// Validate checks that the DDNS settings can be sent to the modem. The
+// provider, hostname, and credentials are only required when DDNS is enabled,
+// so that DDNS can be disabled on a modem which reports an unknown provider.
+func (s DDNS) Validate() error {
+	if s.UpdateInterval < time.Second || s.UpdateInterval%time.Second != 0 {
+		return fmt.Errorf("invalid DDNS update interval %s: must be a whole number of seconds", s.UpdateInterval)
+	}
+
+	if !s.Enable {
+		return nil
+	}
+
+	if _, ok := ddnsProviderCode(s.Provider); !ok {
+		return fmt.Errorf("invalid DDNS provider %q: must be one of %s",
+			s.Provider, strings.Join(DDNSProviders(), ", "))
+	}
+
+	if s.Hostname == "" {
+		return fmt.Errorf("invalid DDNS settings: Hostname must be set when enabled")
+	}
+
+	// the firmware accepts several comma-separated hostnames
+	for _, h := range strings.Split(s.Hostname, ",") {
+		if err := validateDomainName(strings.TrimSpace(h)); err != nil {
+			return fmt.Errorf("invalid DDNS settings: %w", err)
+		}
+	}
+
+	if s.Username == "" || s.Password == "" {
+		return fmt.Errorf("invalid DDNS settings: Username and Password must be set when enabled")
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s DDNS) MarshalJSON() ([]byte, error) {
+	provider, _ := ddnsProviderCode(s.Provider)
+
+	raw := struct {
+		DDNSOnOff          string `json:"ddnsOnOff"`
+		DDNSSrvProvider    int    `json:"ddnsSrvProvider"`
+		DDNSUsername       string `json:"ddnsUsername"`
+		DDNSPassword       string `json:"ddnsPassword"`
+		DDNSHostnames      string `json:"ddnsHostnames"`
+		DDNSUpdateInterval string `json:"ddnsUpdateInterval"`
+	}{
+		DDNSOnOff:          onOff(s.Enable),
+		DDNSSrvProvider:    provider,
+		DDNSUsername:       s.Username,
+		DDNSPassword:       s.Password,
+		DDNSHostnames:      s.Hostname,
+		DDNSUpdateInterval: strconv.FormatInt(int64(s.UpdateInterval/time.Second), 10),
+	}
+
+	return json.Marshal(raw)
+}
+
+// DHCPReservation -
+type DHCPReservation struct {
+	Error
+	Rules []DHCPReservationRule `json:"Rules_List"`
+}
+
+// DHCPReservationRule - a LAN address which is always leased to the same host
+type DHCPReservationRule struct {
+	Name    string
+	MacAddr net.HardwareAddr
+	IP      net.IP
+	ID      int
+}
+
+// UnmarshalJSON - implements json.Unmarshaler
+func (s *DHCPReservationRule) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		ID       string
+		HostName string
+		MacAddr  string
+		IP       net.IP
+	}{}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal DHCPReservationRule %q: %w", string(b), err)
+	}
+
+	s.ID, _ = strconv.Atoi(raw.ID)
+	s.Name = raw.HostName
+	s.IP = raw.IP
+	s.MacAddr, _ = net.ParseMAC(raw.MacAddr)
 
 	return nil
 }
 
+// Reserved returns true if the given address is reserved for a host
+func (s DHCPReservation) Reserved(ip net.IP) bool {
+	for _, r := range s.Rules {
+		if r.IP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Hosts -
 type Hosts struct {
 	Error
@@ -221,6 +633,54 @@ func (s *Host) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Find returns the host with the given MAC address, if it's listed
+func (s Hosts) Find(mac net.HardwareAddr) (Host, bool) {
+	for _, h := range s.Hosts {
+		if bytes.Equal(h.MacAddr, mac) {
+			return h, true
+		}
+	}
+
+	return Host{}, false
+}
+
+// Lookup finds a host by MAC address or (case-insensitive) name. A valid MAC
+// address which isn't in the list returns a Host with only MacAddr set, since
+// offline devices aren't listed. An error is returned if no host, or more than
+// one host, has the given name.
+func (s Hosts) Lookup(nameOrMAC string) (Host, error) {
+	if mac, err := net.ParseMAC(nameOrMAC); err == nil {
+		if h, ok := s.Find(mac); ok {
+			return h, nil
+		}
+
+		return Host{MacAddr: mac}, nil
+	}
+
+	found := []Host{}
+
+	for _, h := range s.Hosts {
+		if strings.EqualFold(h.Name, nameOrMAC) {
+			found = append(found, h)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Host{}, fmt.Errorf("no host named %q", nameOrMAC)
+	case 1:
+		return found[0], nil
+	default:
+		macs := make([]string, len(found))
+		for i, h := range found {
+			macs[i] = h.MacAddr.String()
+		}
+
+		return Host{}, fmt.Errorf("%d hosts are named %q (%s) - use a MAC address instead",
+			len(found), nameOrMAC, strings.Join(macs, ", "))
+	}
+}
+
 type UsersCSRF struct {
 	Error
 	CSRF string
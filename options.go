@@ -0,0 +1,182 @@
+package hitron
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+)
+
+// Option configures optional behaviour of a CableModem created with New
+type Option func(*options)
+
+type options struct {
+	hc          *http.Client
+	transport   http.RoundTripper
+	tlsConfig   *tls.Config
+	onRelogin   func(ctx context.Context, cause, err error)
+	scheme      string
+	basePath    string
+	userAgent   string
+	timeout     time.Duration
+	autoRelogin bool
+}
+
+func defaultOptions() options {
+	return options{
+		scheme:   "http",
+		basePath: "/1/Device/",
+	}
+}
+
+// WithAutoRelogin enables transparent session recovery. When a request fails
+// because the session has expired (the modem redirects to the login page,
+// responds with 401/403, or returns the login page instead of JSON), the
+// client logs in again with the stored credentials and retries the request
+// once.
+func WithAutoRelogin() Option {
+	return func(o *options) {
+		o.autoRelogin = true
+	}
+}
+
+// WithReloginHook registers a function to be called after every automatic
+// re-login attempt. cause is the error which triggered the re-login, and err is
+// the result of the login attempt (nil on success). Only has an effect when
+// combined with WithAutoRelogin.
+func WithReloginHook(fn func(ctx context.Context, cause, err error)) Option {
+	return func(o *options) {
+		o.onRelogin = fn
+	}
+}
+
+// WithHTTPClient uses a copy of the given *http.Client to talk to the modem. A
+// cookie jar is added if the client doesn't have one, since the session is
+// tracked with a cookie. Redirects are not followed unless the client sets its
+// own CheckRedirect policy.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.hc = hc
+	}
+}
+
+// WithTransport sets the http.RoundTripper used for all requests, overriding
+// the transport of any client given with WithHTTPClient.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithTLSConfig connects to the modem over HTTPS, using the given TLS config.
+// This is useful for trusting the modem's self-signed certificate. The
+// transport (default or custom) must be an *http.Transport.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.scheme = "https"
+		o.tlsConfig = cfg
+	}
+}
+
+// WithScheme sets the URL scheme ("http" or "https") - the default is "http",
+// or "https" when WithTLSConfig is used.
+func WithScheme(scheme string) Option {
+	return func(o *options) {
+		o.scheme = scheme
+	}
+}
+
+// WithBasePath sets the path prefix for all API calls - the default is
+// "/1/Device/".
+func WithBasePath(p string) Option {
+	return func(o *options) {
+		o.basePath = p
+	}
+}
+
+// WithTimeout sets a time limit for each request made to the modem. A zero
+// value means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(o *options) {
+		o.userAgent = ua
+	}
+}
+
+func (o *options) baseURL(host string) string {
+	p := strings.Trim(o.basePath, "/")
+	if p != "" {
+		p += "/"
+	}
+
+	return fmt.Sprintf("%s://%s/%s", o.scheme, host, p)
+}
+
+func (o *options) httpClient() (*http.Client, error) {
+	client := &http.Client{}
+	if o.hc != nil {
+		*client = *o.hc
+	}
+
+	if client.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		client.Jar = jar
+	}
+
+	if client.CheckRedirect == nil {
+		// Ignore redirects
+		client.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	if o.timeout > 0 {
+		client.Timeout = o.timeout
+	}
+
+	tr := client.Transport
+	if o.transport != nil {
+		tr = o.transport
+	}
+
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+
+	if o.tlsConfig != nil {
+		t, ok := tr.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("a custom TLS config requires an *http.Transport, not %T", tr)
+		}
+
+		t = t.Clone()
+		t.TLSClientConfig = o.tlsConfig
+		tr = t
+	}
+
+	if o.userAgent != "" {
+		tr = &userAgentTransport{rt: tr, ua: o.userAgent}
+	}
+
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		tr = &debugTransport{tr}
+	}
+
+	client.Transport = tr
+
+	return client, nil
+}
@@ -0,0 +1,104 @@
+package hitron
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	d, err := New("192.168.0.1", "cusadmin", "password")
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://192.168.0.1/1/Device/", d.base.String())
+	assert.NotNil(t, d.hc.Jar)
+	assert.NotNil(t, d.hc.CheckRedirect)
+	assert.False(t, d.autoRelogin)
+}
+
+func TestNew_BaseURL(t *testing.T) {
+	testdata := []struct {
+		expected string
+		opts     []Option
+	}{
+		{"http://example.com:8080/1/Device/", nil},
+		{"https://example.com:8080/1/Device/", []Option{WithScheme("https")}},
+		{"http://example.com:8080/2/Device/", []Option{WithBasePath("/2/Device")}},
+		{"http://example.com:8080/api/", []Option{WithBasePath("api/")}},
+		{"http://example.com:8080/", []Option{WithBasePath("")}},
+		{"https://example.com:8080/1/Device/", []Option{WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})}},
+	}
+
+	for _, td := range testdata {
+		d, err := New("example.com:8080", "", "", td.opts...)
+		require.NoError(t, err)
+		assert.Equal(t, td.expected, d.base.String())
+	}
+}
+
+func TestNew_HTTPClient(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+
+	d, err := New("example.com", "", "", WithHTTPClient(hc), WithTimeout(5*time.Second))
+	require.NoError(t, err)
+
+	// the given client must not be modified
+	assert.Nil(t, hc.Jar)
+	assert.Equal(t, time.Minute, hc.Timeout)
+
+	assert.NotNil(t, d.hc.Jar)
+	assert.Equal(t, 5*time.Second, d.hc.Timeout)
+
+	_, err = New("example.com", "", "",
+		WithTransport(&userAgentTransport{}),
+		WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	assert.Error(t, err)
+}
+
+func TestNew_TLSAndUserAgent(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/1/Device/Router/Location", r.URL.Path)
+		assert.Equal(t, "hitron-test/1.0", r.UserAgent())
+
+		_, _ = w.Write([]byte(`{"errCode":"000","errMsg":"","locationText":"Basement"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	d, err := New(host, "", "",
+		WithTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}),
+		WithUserAgent("hitron-test/1.0"))
+	require.NoError(t, err)
+
+	p, err := d.RouterLocation(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Basement", p.LocationText)
+}
+
+func TestNew_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	d, err := New(strings.TrimPrefix(srv.URL, "http://"), "", "", WithTimeout(10*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = d.RouterLocation(context.Background())
+	assert.Error(t, err)
+}
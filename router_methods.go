@@ -0,0 +1,381 @@
+package hitron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// AddPortForwardRule - adds a new port forwarding rule, and returns the
+// refreshed list of rules. The rule's ID is assigned by the modem.
+func (c *CableModem) AddPortForwardRule(ctx context.Context, rule PortForwardRule) (RouterPortForwardall, error) {
+	if err := rule.Validate(); err != nil {
+		return RouterPortForwardall{}, err
+	}
+
+	rule.ID = 0
+
+	err := c.sendModel(ctx, http.MethodPost, "/Router/PortForward", rule)
+	if err != nil {
+		return RouterPortForwardall{}, fmt.Errorf("failed to add port forwarding rule %q: %w", rule.AppName, err)
+	}
+
+	return c.RouterPortForwardall(ctx)
+}
+
+// UpdatePortForwardRule - replaces the port forwarding rule with the same ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) UpdatePortForwardRule(ctx context.Context, rule PortForwardRule) (RouterPortForwardall, error) {
+	if err := rule.Validate(); err != nil {
+		return RouterPortForwardall{}, err
+	}
+
+	err := c.sendModel(ctx, http.MethodPut, portForwardRulePath(rule.ID), rule)
+	if err != nil {
+		return RouterPortForwardall{}, fmt.Errorf("failed to update port forwarding rule %d: %w", rule.ID, err)
+	}
+
+	return c.RouterPortForwardall(ctx)
+}
+
+// DeletePortForwardRule - deletes the port forwarding rule with the given ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) DeletePortForwardRule(ctx context.Context, id int) (RouterPortForwardall, error) {
+	rule, err := c.portForwardRule(ctx, id)
+	if err != nil {
+		return RouterPortForwardall{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodDelete, portForwardRulePath(id), rule)
+	if err != nil {
+		return RouterPortForwardall{}, fmt.Errorf("failed to delete port forwarding rule %d: %w", id, err)
+	}
+
+	return c.RouterPortForwardall(ctx)
+}
+
+// SetPortForwardEnabled - turns the port forwarding rule with the given ID on
+// or off, and returns the refreshed list of rules.
+func (c *CableModem) SetPortForwardEnabled(ctx context.Context, id int, enable bool) (RouterPortForwardall, error) {
+	rule, err := c.portForwardRule(ctx, id)
+	if err != nil {
+		return RouterPortForwardall{}, err
+	}
+
+	rule.Enable = enable
+
+	return c.UpdatePortForwardRule(ctx, rule)
+}
+
+func (c *CableModem) portForwardRule(ctx context.Context, id int) (PortForwardRule, error) {
+	all, err := c.RouterPortForwardall(ctx)
+	if err != nil {
+		return PortForwardRule{}, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+
+	return PortForwardRule{}, fmt.Errorf("no port forwarding rule with ID %d", id)
+}
+
+func portForwardRulePath(id int) string {
+	return "/Router/PortForward/" + strconv.Itoa(id)
+}
+
+// SetDMZ - enables the DMZ, exposing the given LAN host to all unsolicited
+// incoming traffic, and returns the refreshed DMZ settings. The host must be in
+// the router's private LAN subnet. Advisory warnings are also returned - for
+// example when the host has no DHCP reservation, since its address could later
+// be leased to another device.
+func (c *CableModem) SetDMZ(ctx context.Context, host net.IP) (RouterDMZ, []string, error) {
+	dmz, err := c.RouterDMZ(ctx)
+	if err != nil {
+		return RouterDMZ{}, nil, err
+	}
+
+	dmz.Enable = true
+	dmz.Host = host
+
+	if err := dmz.Validate(); err != nil {
+		return RouterDMZ{}, nil, err
+	}
+
+	warnings := c.reservationWarnings(ctx, host)
+
+	err = c.sendModel(ctx, http.MethodPut, "/Router/DMZ", dmz)
+	if err != nil {
+		return RouterDMZ{}, nil, fmt.Errorf("failed to set DMZ host: %w", err)
+	}
+
+	dmz, err = c.RouterDMZ(ctx)
+	if err != nil {
+		return RouterDMZ{}, nil, err
+	}
+
+	return dmz, warnings, nil
+}
+
+// DisableDMZ - disables the DMZ, and returns the refreshed DMZ settings
+func (c *CableModem) DisableDMZ(ctx context.Context) (RouterDMZ, error) {
+	dmz, err := c.RouterDMZ(ctx)
+	if err != nil {
+		return RouterDMZ{}, err
+	}
+
+	dmz.Enable = false
+	dmz.Host = net.IPv4zero
+
+	err = c.sendModel(ctx, http.MethodPut, "/Router/DMZ", dmz)
+	if err != nil {
+		return RouterDMZ{}, fmt.Errorf("failed to disable DMZ: %w", err)
+	}
+
+	return c.RouterDMZ(ctx)
+}
+
+// reservationWarnings returns a warning if the host has no DHCP reservation.
+// Failing to read the reservations isn't fatal, as this is only advisory.
+func (c *CableModem) reservationWarnings(ctx context.Context, host net.IP) []string {
+	res, err := c.DHCPReservation(ctx)
+	if err != nil {
+		return []string{fmt.Sprintf("couldn't read DHCP reservations: %v", err)}
+	}
+
+	if !res.Reserved(host) {
+		return []string{fmt.Sprintf("DMZ host %s has no DHCP reservation, so its address may change", host)}
+	}
+
+	return nil
+}
+
+// AddPortTriggerRule - adds a new port triggering rule, and returns the
+// refreshed list of rules. The rule's ID is assigned by the modem. Rules with
+// ports overlapping existing port triggering or forwarding rules are rejected.
+func (c *CableModem) AddPortTriggerRule(ctx context.Context, rule PortTriggerRule) (RouterPortTriggerall, error) {
+	rule.ID = 0
+
+	if err := c.checkPortTriggerRule(ctx, rule); err != nil {
+		return RouterPortTriggerall{}, err
+	}
+
+	err := c.sendModel(ctx, http.MethodPost, "/Router/PortTrigger", rule)
+	if err != nil {
+		return RouterPortTriggerall{}, fmt.Errorf("failed to add port triggering rule %q: %w", rule.AppName, err)
+	}
+
+	return c.RouterPortTriggerall(ctx)
+}
+
+// UpdatePortTriggerRule - replaces the port triggering rule with the same ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) UpdatePortTriggerRule(ctx context.Context, rule PortTriggerRule) (RouterPortTriggerall, error) {
+	if err := c.checkPortTriggerRule(ctx, rule); err != nil {
+		return RouterPortTriggerall{}, err
+	}
+
+	err := c.sendModel(ctx, http.MethodPut, portTriggerRulePath(rule.ID), rule)
+	if err != nil {
+		return RouterPortTriggerall{}, fmt.Errorf("failed to update port triggering rule %d: %w", rule.ID, err)
+	}
+
+	return c.RouterPortTriggerall(ctx)
+}
+
+// DeletePortTriggerRule - deletes the port triggering rule with the given ID,
+// and returns the refreshed list of rules.
+func (c *CableModem) DeletePortTriggerRule(ctx context.Context, id int) (RouterPortTriggerall, error) {
+	rule, err := c.portTriggerRule(ctx, id)
+	if err != nil {
+		return RouterPortTriggerall{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodDelete, portTriggerRulePath(id), rule)
+	if err != nil {
+		return RouterPortTriggerall{}, fmt.Errorf("failed to delete port triggering rule %d: %w", id, err)
+	}
+
+	return c.RouterPortTriggerall(ctx)
+}
+
+// SetPortTriggerEnabled - turns the port triggering rule with the given ID on
+// or off, and returns the refreshed list of rules.
+func (c *CableModem) SetPortTriggerEnabled(ctx context.Context, id int, enable bool) (RouterPortTriggerall, error) {
+	rule, err := c.portTriggerRule(ctx, id)
+	if err != nil {
+		return RouterPortTriggerall{}, err
+	}
+
+	rule.Enable = enable
+
+	return c.UpdatePortTriggerRule(ctx, rule)
+}
+
+// SetAllPortTriggersEnabled - turns port triggering as a whole on or off (the
+// AllRulesOnOff setting), without changing the individual rules.
+func (c *CableModem) SetAllPortTriggersEnabled(ctx context.Context, enable bool) (RouterPortTriggerStatus, error) {
+	err := c.sendModel(ctx, http.MethodPut, "/Router/PortTrigger/Status", map[string]string{"allRulesOnOff": onOff(enable)})
+	if err != nil {
+		return RouterPortTriggerStatus{}, fmt.Errorf("failed to set port triggering status: %w", err)
+	}
+
+	return c.RouterPortTriggerStatus(ctx)
+}
+
+// checkPortTriggerRule validates the rule, and checks it against the existing
+// port triggering and forwarding rules for overlapping ports
+func (c *CableModem) checkPortTriggerRule(ctx context.Context, rule PortTriggerRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	triggers, err := c.RouterPortTriggerall(ctx)
+	if err != nil {
+		return err
+	}
+
+	forwards, err := c.RouterPortForwardall(ctx)
+	if err != nil {
+		return err
+	}
+
+	return rule.conflicts(triggers.Rules, forwards.Rules)
+}
+
+func (c *CableModem) portTriggerRule(ctx context.Context, id int) (PortTriggerRule, error) {
+	all, err := c.RouterPortTriggerall(ctx)
+	if err != nil {
+		return PortTriggerRule{}, err
+	}
+
+	for _, rule := range all.Rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+
+	return PortTriggerRule{}, fmt.Errorf("no port triggering rule with ID %d", id)
+}
+
+func portTriggerRulePath(id int) string {
+	return "/Router/PortTrigger/" + strconv.Itoa(id)
+}
+
+// RouterBackup - downloads the modem's configuration backup file from
+// /Router/Backup, writing it to w. The file is opaque, and is only useful for
+// restoring with RouterRestore.
+func (c *CableModem) RouterBackup(ctx context.Context, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return c.withRelogin(ctx, func() error {
+		return c.download(ctx, "/Router/Backup", w)
+	})
+}
+
+// download - writes the response body of a GET request to w. The body is read
+// in full first, so nothing is written to w if the request fails - even
+// partway through the body.
+func (c *CableModem) download(ctx context.Context, path string, w io.Writer) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// an expired session results in the (HTML) login page being served
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || mediaType == "text/html" {
+		b, _ := io.ReadAll(resp.Body)
+
+		return &StatusError{StatusCode: resp.StatusCode, Body: b, Header: resp.Header}
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+// RouterRestore - uploads a configuration backup file (as produced by
+// RouterBackup) to /Router/Restore, replacing the modem's configuration. The
+// modem reboots to apply the restored configuration.
+func (c *CableModem) RouterRestore(ctx context.Context, r io.Reader) error {
+	backup, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if len(backup) == 0 {
+		return fmt.Errorf("backup is empty")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the CSRF token is tied to the session, so must be fetched again if a
+	// re-login is needed
+	return c.withRelogin(ctx, func() error {
+		return c.uploadBackup(ctx, backup)
+	})
+}
+
+func (c *CableModem) uploadBackup(ctx context.Context, backup []byte) error {
+	csrf, err := c.UsersCSRF(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve CSRF token: %w", err)
+	}
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+
+	if err = mw.WriteField("csrf", csrf.CSRF); err != nil {
+		return err
+	}
+
+	fw, err := mw.CreateFormFile("file", "backup.cfg")
+	if err != nil {
+		return err
+	}
+
+	if _, err = fw.Write(backup); err != nil {
+		return err
+	}
+
+	if err = mw.Close(); err != nil {
+		return err
+	}
+
+	b, err := c.do(ctx, http.MethodPost, "/Router/Restore", mw.FormDataContentType(), body.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	o := Error{}
+
+	err = json.Unmarshal(b, &o)
+	if err != nil {
+		return fmt.Errorf("JSON decoding failed: %w", err)
+	}
+
+	return o.Err()
+}
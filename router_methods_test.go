@@ -0,0 +1,556 @@
+package hitron
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const portForwardAllBody = `{"errCode":"000","errMsg":"","total":2,
+	"Rules_List":[
+		{"appName":"custom",
+		"pubStart":"1024","pubEnd":"2048","priStart":"1024","priEnd":"2048",
+		"protocol":"UDP","localIpAddr":"192.168.0.2",
+		"remoteIpStar":"0.0.0.0","remoteIpEnd":"255.255.255.255",
+		"ruleOnOff":"ON","origin":"1","id":"1"},
+		{"appName":"SSH",
+		"pubStart":"22","pubEnd":"22","priStart":"2222","priEnd":"2222",
+		"protocol":"TCP","localIpAddr":"192.168.0.16",
+		"remoteIpStar":"10.0.0.1","remoteIpEnd":"11.4.3.2",
+		"ruleOnOff":"OFF","origin":"1","id":"2"}
+	]}`
+
+func TestPortRangeValidate(t *testing.T) {
+	assert.NoError(t, PortRange{1, 65535}.Validate())
+	assert.NoError(t, PortRange{22, 22}.Validate())
+	assert.Error(t, PortRange{0, 22}.Validate())
+	assert.Error(t, PortRange{22, 65536}.Validate())
+	assert.Error(t, PortRange{23, 22}.Validate())
+}
+
+func TestParsePortRange(t *testing.T) {
+	r, err := ParsePortRange("80")
+	require.NoError(t, err)
+	assert.Equal(t, PortRange{80, 80}, r)
+	assert.Equal(t, "80", r.String())
+
+	r, err = ParsePortRange(" 8080 - 8088 ")
+	require.NoError(t, err)
+	assert.Equal(t, PortRange{8080, 8088}, r)
+	assert.Equal(t, "8080-8088", r.String())
+
+	_, err = ParsePortRange("http")
+	assert.Error(t, err)
+
+	_, err = ParsePortRange("90-80")
+	assert.Error(t, err)
+}
+
+func TestParseIPRange(t *testing.T) {
+	r, err := ParseIPRange("10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, IPRange{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.1")}, r)
+
+	r, err = ParseIPRange("10.0.0.1-10.0.0.255")
+	require.NoError(t, err)
+	assert.Equal(t, IPRange{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.255")}, r)
+	assert.Equal(t, "10.0.0.1-10.0.0.255", r.String())
+
+	_, err = ParseIPRange("10.0.0.1-foo")
+	assert.Error(t, err)
+}
+
+func TestIPRangeValidate(t *testing.T) {
+	assert.NoError(t, IPRange{}.Validate())
+	assert.NoError(t, IPRange{net.IPv4zero, net.IPv4bcast}.Validate())
+	assert.NoError(t, IPRange{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.1")}.Validate())
+	assert.Error(t, IPRange{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")}.Validate())
+	assert.Error(t, IPRange{net.ParseIP("10.0.0.1"), nil}.Validate())
+	assert.Error(t, IPRange{net.ParseIP("::1"), net.ParseIP("::2")}.Validate())
+}
+
+func TestPortForwardRuleValidate(t *testing.T) {
+	valid := PortForwardRule{
+		AppName:      "SSH",
+		Protocol:     "TCP",
+		LocalIP:      net.ParseIP("192.168.0.16"),
+		PublicPorts:  PortRange{22, 22},
+		PrivatePorts: PortRange{2222, 2222},
+	}
+	assert.NoError(t, valid.Validate())
+
+	r := valid
+	r.AppName = ""
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Protocol = "ICMP"
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.LocalIP = nil
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.PrivatePorts = PortRange{2222, 2223}
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.RemoteIPs = IPRange{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")}
+	assert.Error(t, r.Validate())
+}
+
+func TestPortForwardRuleMarshalJSON(t *testing.T) {
+	in := PortForwardRule{
+		Enable: true, ID: 2, Origin: 1,
+		AppName:      "SSH",
+		Protocol:     "TCP",
+		PublicPorts:  PortRange{22, 22},
+		PrivatePorts: PortRange{2222, 2222},
+		LocalIP:      net.ParseIP("192.168.0.16"),
+	}
+
+	b, err := in.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"2","origin":"1","appName":"SSH",
+		"pubStart":"22","pubEnd":"22","priStart":"2222","priEnd":"2222",
+		"ruleOnOff":"ON","protocol":"TCP","localIpAddr":"192.168.0.16",
+		"remoteIpStar":"0.0.0.0","remoteIpEnd":"255.255.255.255"}`, string(b))
+
+	out := PortForwardRule{}
+	require.NoError(t, out.UnmarshalJSON(b))
+
+	in.RemoteIPs = IPRange{net.IPv4zero, net.IPv4bcast}
+	assert.EqualValues(t, in, out)
+}
+
+func TestAddPortForwardRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Router/PortForward/all": portForwardAllBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.AddPortForwardRule(ctx, PortForwardRule{AppName: "bad"})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	p, err := d.AddPortForwardRule(ctx, PortForwardRule{
+		Enable:       true,
+		AppName:      "web",
+		Protocol:     "TCP",
+		PublicPorts:  PortRange{80, 80},
+		PrivatePorts: PortRange{8080, 8080},
+		LocalIP:      net.ParseIP("192.168.0.10"),
+	})
+	require.NoError(t, err)
+	assert.Len(t, p.Rules, 2)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortForward", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.Equal(t, "csrf-token", w[0].CSRF)
+	assert.JSONEq(t, `{"id":"0","origin":"0","appName":"web",
+		"pubStart":"80","pubEnd":"80","priStart":"8080","priEnd":"8080",
+		"ruleOnOff":"ON","protocol":"TCP","localIpAddr":"192.168.0.10",
+		"remoteIpStar":"0.0.0.0","remoteIpEnd":"255.255.255.255"}`, w[0].Model)
+}
+
+func TestDeletePortForwardRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Router/PortForward/all": portForwardAllBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.DeletePortForwardRule(ctx, 42)
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, err = d.DeletePortForwardRule(ctx, 2)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortForward/2", w[0].Path)
+	assert.Equal(t, http.MethodDelete, w[0].Method)
+}
+
+func TestSetPortForwardEnabled(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Router/PortForward/all": portForwardAllBody})
+	d := testCableModem(srv)
+
+	_, err := d.SetPortForwardEnabled(context.Background(), 2, true)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortForward/2", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+
+	rule := PortForwardRule{}
+	require.NoError(t, rule.UnmarshalJSON([]byte(w[0].Model)))
+	assert.True(t, rule.Enable)
+	assert.Equal(t, "SSH", rule.AppName)
+	assert.Equal(t, IPRange{net.ParseIP("10.0.0.1"), net.ParseIP("11.4.3.2")}, rule.RemoteIPs)
+}
+
+const dmzBody = `{"errCode":"000","errMsg":"","enable":"OFF","host":"0.0.0.0",
+	"privateLan":"192.168.0.1","subMask":"255.255.255.0"}`
+
+func TestRouterDMZValidate(t *testing.T) {
+	dmz := RouterDMZ{
+		Enable:     true,
+		Host:       net.ParseIP("192.168.0.16"),
+		PrivateLan: net.ParseIP("192.168.0.1"),
+		Mask:       net.ParseIP("255.255.255.0"),
+	}
+	assert.NoError(t, dmz.Validate())
+
+	for _, host := range []string{"192.168.1.16", "192.168.0.0", "192.168.0.255", "192.168.0.1", "0.0.0.0", "fe80::1"} {
+		d := dmz
+		d.Host = net.ParseIP(host)
+		assert.Error(t, d.Validate(), host)
+	}
+
+	d := dmz
+	d.Mask = nil
+	assert.Error(t, d.Validate())
+
+	// nothing to check when disabled
+	d = dmz
+	d.Enable = false
+	d.Host = nil
+	assert.NoError(t, d.Validate())
+}
+
+func TestSetDMZ(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Router/DMZ":       dmzBody,
+		"/DHCP/Reservation": `{"errCode":"000","errMsg":"","Rules_List":[{"id":"1","hostName":"console","macAddr":"13:37:be:ef:ca:fe","ip":"192.168.0.16"}]}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, _, err := d.SetDMZ(ctx, net.ParseIP("10.0.0.16"))
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, warnings, err := d.SetDMZ(ctx, net.ParseIP("192.168.0.16"))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	_, warnings, err = d.SetDMZ(ctx, net.ParseIP("192.168.0.17"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DMZ host 192.168.0.17 has no DHCP reservation, so its address may change"}, warnings)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, "/Router/DMZ", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"enable":"ON","host":"192.168.0.16","privateLan":"192.168.0.1","subMask":"255.255.255.0"}`, w[0].Model)
+}
+
+func TestDisableDMZ(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Router/DMZ": dmzBody})
+	d := testCableModem(srv)
+
+	_, err := d.DisableDMZ(context.Background())
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.JSONEq(t, `{"enable":"OFF","host":"0.0.0.0","privateLan":"192.168.0.1","subMask":"255.255.255.0"}`, w[0].Model)
+}
+
+const portTriggerAllBody = `{"errCode":"000","errMsg":"","total":1,
+	"Rules_List":[
+		{"ruleOnOff":"ON","appName":"game","protocol":"BOTH",
+		"pubStart":"6000","pubEnd":"6010","priStart":"7000","priEnd":"7010",
+		"timeout":"300000","twowayOnOff":"OFF","id":"1"}
+	]}`
+
+func TestPortRangeOverlaps(t *testing.T) {
+	assert.True(t, PortRange{80, 80}.Overlaps(PortRange{80, 80}))
+	assert.True(t, PortRange{80, 90}.Overlaps(PortRange{90, 100}))
+	assert.True(t, PortRange{80, 90}.Overlaps(PortRange{1, 65535}))
+	assert.False(t, PortRange{80, 89}.Overlaps(PortRange{90, 100}))
+	assert.False(t, PortRange{90, 100}.Overlaps(PortRange{80, 89}))
+}
+
+func TestPortTriggerRuleValidate(t *testing.T) {
+	valid := PortTriggerRule{
+		AppName:      "game",
+		Protocol:     "BOTH",
+		TriggerPorts: PortRange{6000, 6010},
+		TargetPorts:  PortRange{7000, 7010},
+		Timeout:      5 * time.Minute,
+	}
+	assert.NoError(t, valid.Validate())
+
+	r := valid
+	r.AppName = ""
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Protocol = "ICMP"
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.TriggerPorts = PortRange{6010, 6000}
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.TargetPorts = PortRange{0, 7000}
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Timeout = 0
+	assert.Error(t, r.Validate())
+}
+
+func TestPortTriggerRuleConflicts(t *testing.T) {
+	triggers := []PortTriggerRule{
+		{ID: 1, AppName: "game", Protocol: "UDP", TriggerPorts: PortRange{6000, 6010}, TargetPorts: PortRange{7000, 7010}},
+	}
+	forwards := []PortForwardRule{
+		{ID: 1, AppName: "SSH", Protocol: "TCP", PublicPorts: PortRange{22, 22}},
+	}
+
+	r := PortTriggerRule{AppName: "new", Protocol: "BOTH", TriggerPorts: PortRange{5000, 5000}, TargetPorts: PortRange{5001, 5002}}
+	assert.NoError(t, r.conflicts(triggers, forwards))
+
+	r.TriggerPorts = PortRange{6010, 6020}
+	assert.ErrorContains(t, r.conflicts(triggers, forwards), "trigger ports")
+
+	r.TriggerPorts = PortRange{5000, 5000}
+	r.TargetPorts = PortRange{6990, 7000}
+	assert.ErrorContains(t, r.conflicts(triggers, forwards), "target ports")
+
+	r.TargetPorts = PortRange{20, 30}
+	assert.ErrorContains(t, r.conflicts(triggers, forwards), `port forwarding rule "SSH"`)
+
+	// different protocols don't conflict
+	r.Protocol = "UDP"
+	assert.NoError(t, r.conflicts(triggers, forwards))
+
+	// a rule doesn't conflict with itself
+	r = triggers[0]
+	assert.NoError(t, r.conflicts(triggers, forwards))
+}
+
+func TestPortTriggerRuleMarshalJSON(t *testing.T) {
+	in := PortTriggerRule{
+		Enable: true, ID: 1, TwoWay: true,
+		AppName:      "game",
+		Protocol:     "BOTH",
+		TriggerPorts: PortRange{6000, 6010},
+		TargetPorts:  PortRange{7000, 7010},
+		Timeout:      5 * time.Minute,
+	}
+
+	b, err := in.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ruleOnOff":"ON","appName":"game","protocol":"BOTH",
+		"pubStart":"6000","pubEnd":"6010","priStart":"7000","priEnd":"7010",
+		"timeout":"300000","twowayOnOff":"ON","id":"1"}`, string(b))
+
+	out := PortTriggerRule{}
+	require.NoError(t, out.UnmarshalJSON(b))
+	assert.Equal(t, in, out)
+}
+
+func TestAddPortTriggerRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Router/PortTrigger/all": portTriggerAllBody,
+		"/Router/PortForward/all": portForwardAllBody,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.AddPortTriggerRule(ctx, PortTriggerRule{AppName: "bad"})
+	require.Error(t, err)
+
+	rule := PortTriggerRule{
+		Enable:       true,
+		AppName:      "voip",
+		Protocol:     "UDP",
+		TriggerPorts: PortRange{5060, 5060},
+		TargetPorts:  PortRange{1500, 1500},
+		Timeout:      time.Minute,
+	}
+
+	// overlaps the "custom" port forwarding rule
+	_, err = d.AddPortTriggerRule(ctx, rule)
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	rule.TargetPorts = PortRange{10000, 10010}
+	p, err := d.AddPortTriggerRule(ctx, rule)
+	require.NoError(t, err)
+	assert.Len(t, p.Rules, 1)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortTrigger", w[0].Path)
+	assert.Equal(t, http.MethodPost, w[0].Method)
+	assert.Equal(t, "csrf-token", w[0].CSRF)
+	assert.JSONEq(t, `{"ruleOnOff":"ON","appName":"voip","protocol":"UDP",
+		"pubStart":"5060","pubEnd":"5060","priStart":"10000","priEnd":"10010",
+		"timeout":"60000","twowayOnOff":"OFF","id":"0"}`, w[0].Model)
+}
+
+func TestUpdatePortTriggerRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Router/PortTrigger/all": portTriggerAllBody,
+		"/Router/PortForward/all": portForwardAllBody,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.SetPortTriggerEnabled(ctx, 42, false)
+	require.Error(t, err)
+
+	_, err = d.SetPortTriggerEnabled(ctx, 1, false)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortTrigger/1", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+
+	rule := PortTriggerRule{}
+	require.NoError(t, rule.UnmarshalJSON([]byte(w[0].Model)))
+	assert.False(t, rule.Enable)
+	assert.Equal(t, "game", rule.AppName)
+	assert.Equal(t, 5*time.Minute, rule.Timeout)
+}
+
+func TestDeletePortTriggerRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/Router/PortTrigger/all": portTriggerAllBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.DeletePortTriggerRule(ctx, 42)
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, err = d.DeletePortTriggerRule(ctx, 1)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortTrigger/1", w[0].Path)
+	assert.Equal(t, http.MethodDelete, w[0].Method)
+}
+
+func TestSetAllPortTriggersEnabled(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/Router/PortTrigger/Status": `{"allRulesOnOff":"ON"}`,
+	})
+	d := testCableModem(srv)
+
+	_, err := d.SetAllPortTriggersEnabled(context.Background(), false)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/Router/PortTrigger/Status", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"allRulesOnOff":"OFF"}`, w[0].Model)
+}
+
+func TestRouterBackup(t *testing.T) {
+	backup := []byte{0x00, 0x01, 0xfe, 0xff, 'c', 'f', 'g'}
+	loggedIn := true
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Router/Backup", r.URL.Path)
+
+		if !loggedIn {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html>login</html>"))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(backup)
+	}))
+	t.Cleanup(srv.Close)
+
+	d := testCableModem(srv)
+	ctx := context.Background()
+
+	out := &bytes.Buffer{}
+	require.NoError(t, d.RouterBackup(ctx, out))
+	assert.Equal(t, backup, out.Bytes())
+
+	loggedIn = false
+	out.Reset()
+
+	err := d.RouterBackup(ctx, out)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	assert.Empty(t, out.Bytes())
+}
+
+func TestRouterBackup_Truncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// promise more than is sent, so the body is cut short
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", "1024")
+		_, _ = w.Write([]byte("partial"))
+	}))
+	t.Cleanup(srv.Close)
+
+	d := testCableModem(srv)
+
+	out := &bytes.Buffer{}
+	err := d.RouterBackup(context.Background(), out)
+	require.Error(t, err)
+	assert.Empty(t, out.Bytes())
+}
+
+func TestRouterRestore(t *testing.T) {
+	backup := []byte{0x00, 0x01, 0xfe, 0xff, 'c', 'f', 'g'}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Users/CSRF":
+			_, _ = w.Write([]byte(`{"errCode":"000","errMsg":"","CSRF":"csrf-token"}`))
+		case "/Router/Restore":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			assert.Equal(t, "csrf-token", r.FormValue("csrf"))
+
+			f, _, err := r.FormFile("file")
+			assert.NoError(t, err)
+
+			b, _ := io.ReadAll(f)
+			assert.Equal(t, backup, b)
+
+			_, _ = w.Write([]byte(`{"errCode":"000","errMsg":""}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	d := testCableModem(srv)
+	ctx := context.Background()
+
+	require.NoError(t, d.RouterRestore(ctx, bytes.NewReader(backup)))
+	assert.Error(t, d.RouterRestore(ctx, strings.NewReader("")))
+}
@@ -52,7 +52,7 @@ func TestRouterSysInfo(t *testing.T) {
 	p, err := d.RouterSysInfo(context.Background())
 	assert.NoError(t, err)
 
-	loc, err := time.LoadLocation("Etc/UTC")
+	loc, err := time.LoadLocation("Europe/London")
 	assert.NoError(t, err)
 
 	systime, err := time.ParseInLocation("2006-01-02 15:04:05", "2020-11-17 02:12:33", loc)
@@ -131,6 +131,9 @@ func TestRouterSysInfo(t *testing.T) {
 	p, err = d.RouterSysInfo(context.Background())
 	require.NoError(t, err)
 
+	loc, err = time.LoadLocation("Etc/UTC")
+	assert.NoError(t, err)
+
 	systime, err = time.ParseInLocation("2006-01-02 15:04:05", "2022-07-29 23:26:32", loc)
 	assert.NoError(t, err)
 
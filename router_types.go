@@ -1,9 +1,11 @@
 package hitron
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -299,54 +301,125 @@ func parseIPList(s []string) ([]net.IP, error) {
 	return ips, nil
 }
 
+// timeZones - the modem's time zone identifiers, mapped to the closest
+// location. The last part of the identifier is 1 when the zone observes
+// daylight saving time.
+//
+//nolint:gochecknoglobals
+var timeZones = map[string]string{
+	"0":      "Etc/UTC",
+	"0_1_0":  "Pacific/Kwajalein",
+	"1_1_0":  "Pacific/Pago_Pago",
+	"2_1_0":  "Pacific/Honolulu",
+	"3_1_1":  "America/Anchorage",
+	"4_1_1":  "America/Los_Angeles",
+	"5_1_0":  "America/Phoenix",
+	"5_2_1":  "America/Denver",
+	"6_1_1":  "America/Mexico_City",
+	"6_2_1":  "America/Chicago",
+	"7_1_0":  "America/Indiana/Indianapolis",
+	"7_2_1":  "America/New_York",
+	"8_1_0":  "America/Caracas",
+	"8_2_1":  "America/Halifax",
+	"9_1_1":  "America/St_Johns",
+	"10_1_1": "America/Sao_Paulo",
+	"11_1_1": "Atlantic/South_Georgia",
+	"12_1_1": "Atlantic/Azores",
+	"13_1_0": "Africa/Monrovia",
+	"13_2_1": "Europe/London",
+	"14_1_0": "Africa/Tunis",
+	"14_2_1": "Europe/Rome",
+	"15_1_0": "Africa/Johannesburg",
+	"16_1_0": "Europe/Athens",
+	"17_1_0": "Europe/Samara",
+	"18_1_0": "Asia/Yekaterinburg",
+	"19_1_0": "Asia/Kolkata",
+	"20_1_0": "Asia/Omsk",
+	"21_1_0": "Asia/Bangkok",
+	"22_1_0": "Asia/Shanghai",
+	"22_2_0": "Asia/Taipei",
+	"23_1_0": "Asia/Tokyo",
+	"24_1_0": "Pacific/Guam",
+	"24_2_1": "Australia/Sydney",
+	"25_1_0": "Pacific/Bougainville",
+	"26_1_1": "Pacific/Auckland",
+}
+
 func tzToLocation(tz string) (*time.Location, error) {
-	tzmap := map[string]string{
-		"0":      "Etc/UTC",
-		"0_1_0":  "Pacific/Kwajalein",
-		"1_1_0":  "Pacific/Pago_Pago",
-		"2_1_0":  "Pacific/Honolulu",
-		"3_1_1":  "America/Anchorage",
-		"4_1_1":  "America/Los_Angeles",
-		"5_1_0":  "America/Phoenix",
-		"5_2_1":  "America/Denver",
-		"6_1_1":  "America/Mexico_City",
-		"6_2_1":  "America/Chicago",
-		"7_1_0":  "America/Indiana/Indianapolis",
-		"7_2_1":  "America/New_York",
-		"8_1_0":  "America/Caracas",
-		"8_2_1":  "America/Halifax",
-		"9_1_1":  "America/St_Johns",
-		"10_1_1": "America/Sao_Paulo",
-		"11_1_1": "Atlantic/South_Georgia",
-		"12_1_1": "Atlantic/Azores",
-		"13_1_0": "Africa/Monrovia",
-		"13_2_1": "Etc/UTC",
-		"14_1_0": "Africa/Tunis",
-		"14_2_1": "Europe/Rome",
-		"15_1_0": "Africa/Johannesburg",
-		"16_1_0": "Europe/Athens",
-		"17_1_0": "Europe/Samara",
-		"18_1_0": "Asia/Yekaterinburg",
-		"19_1_0": "Asia/Kolkata",
-		"20_1_0": "Asia/Omsk",
-		"21_1_0": "Asia/Bangkok",
-		"22_1_0": "Asia/Shanghai",
-		"22_2_0": "Asia/Taipei",
-		"23_1_0": "Asia/Tokyo",
-		"24_1_0": "Pacific/Guam",
-		"24_2_1": "Australia/Sydney",
-		"25_1_0": "Pacific/Bougainville",
-		"26_1_1": "Pacific/Auckland",
-	}
-
-	l, err := time.LoadLocation(tzmap[tz])
+	l, err := time.LoadLocation(timeZones[tz])
 	if err != nil {
-		return time.UTC, fmt.Errorf("failed to load location %q: %w", tzmap[tz], err)
+		return time.UTC, fmt.Errorf("failed to load location %q: %w", timeZones[tz], err)
 	}
 
 	return l, nil
 }
 
+// locationToTZ returns the modem's identifier for the time zone - the reverse
+// of tzToLocation. Locations the modem doesn't list are matched to a zone with
+// the same offsets in winter and summer, which the modem also lists as
+// observing (or not observing) daylight saving time.
+func locationToTZ(loc *time.Location) (string, error) {
+	if loc == nil {
+		return "", fmt.Errorf("no time zone given")
+	}
+
+	if loc.String() == "UTC" {
+		return "0", nil
+	}
+
+	codes := make([]string, 0, len(timeZones))
+	for code := range timeZones {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if timeZones[code] == loc.String() {
+			return code, nil
+		}
+	}
+
+	jan, jul := offsets(loc)
+
+	for _, code := range codes {
+		l, err := time.LoadLocation(timeZones[code])
+		if err != nil {
+			continue
+		}
+
+		if j, k := offsets(l); j == jan && k == jul && strings.HasSuffix(code, "_1") == (jan != jul) {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("time zone %s (offsets %s in January, %s in July) is not supported by the modem",
+		loc, time.Duration(jan)*time.Second, time.Duration(jul)*time.Second)
+}
+
+// offsets returns the location's offsets from UTC in seconds in January and
+// July this year, which differ when it observes daylight saving time
+func offsets(loc *time.Location) (jan, jul int) {
+	year := time.Now().Year()
+
+	_, jan = time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, jul = time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+
+	return jan, jul
+}
+
+// SupportedLocation returns the location the modem will report after its time
+// zone is set to loc, which may be a different location with the same offsets.
+// An error is returned if the modem lists no zone with exactly the same offsets
+func SupportedLocation(loc *time.Location) (*time.Location, error) {
+	tz, err := locationToTZ(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return tzToLocation(tz)
+}
+
 // RouterCapability -
 type RouterCapability struct {
 	Error
@@ -471,6 +544,67 @@ func (s *RouterDMZ) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Validate checks that the DMZ host is a usable address in the router's
+// private LAN subnet. Nothing is checked when the DMZ is disabled.
+func (s RouterDMZ) Validate() error {
+	if !s.Enable {
+		return nil
+	}
+
+	host := s.Host.To4()
+	if host == nil || host.IsUnspecified() {
+		return fmt.Errorf("invalid DMZ host %q: must be an IPv4 address", s.Host)
+	}
+
+	mask := net.IPMask(s.Mask.To4())
+
+	lan := &net.IPNet{IP: s.PrivateLan.Mask(mask), Mask: mask}
+	if lan.IP == nil {
+		return fmt.Errorf("invalid private LAN %s/%s", s.PrivateLan, s.Mask)
+	}
+
+	if !lan.Contains(host) {
+		return fmt.Errorf("invalid DMZ host %s: must be in the private LAN subnet %s", host, lan)
+	}
+
+	broadcast := make(net.IP, len(lan.IP))
+	for i := range lan.IP {
+		broadcast[i] = lan.IP[i] | ^lan.Mask[i]
+	}
+
+	switch {
+	case host.Equal(lan.IP), host.Equal(broadcast):
+		return fmt.Errorf("invalid DMZ host %s: must not be the network or broadcast address of %s", host, lan)
+	case host.Equal(s.PrivateLan):
+		return fmt.Errorf("invalid DMZ host %s: must not be the router itself", host)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s RouterDMZ) MarshalJSON() ([]byte, error) {
+	host := s.Host
+	if host == nil {
+		// the firmware's representation of "no host"
+		host = net.IPv4zero
+	}
+
+	raw := struct {
+		Enable     string `json:"enable"`
+		Host       string `json:"host"`
+		PrivateLan string `json:"privateLan"`
+		SubMask    string `json:"subMask"`
+	}{
+		Enable:     onOff(s.Enable),
+		Host:       host.String(),
+		PrivateLan: s.PrivateLan.String(),
+		SubMask:    s.Mask.String(),
+	}
+
+	return json.Marshal(raw)
+}
+
 // RouterPortForwardStatus -
 type RouterPortForwardStatus struct {
 	Error
@@ -528,6 +662,57 @@ type PortRange struct {
 	Start, End int
 }
 
+// Validate checks that the range is a valid, non-empty range of TCP/UDP ports
+func (r PortRange) Validate() error {
+	if r.Start < 1 || r.Start > 65535 || r.End < 1 || r.End > 65535 {
+		return fmt.Errorf("invalid port range %d-%d: ports must be between 1 and 65535", r.Start, r.End)
+	}
+
+	if r.Start > r.End {
+		return fmt.Errorf("invalid port range %d-%d: start must not be greater than end", r.Start, r.End)
+	}
+
+	return nil
+}
+
+// Overlaps returns true if the ranges have any ports in common
+func (r PortRange) Overlaps(o PortRange) bool {
+	return r.Start <= o.End && o.Start <= r.End
+}
+
+// ParsePortRange parses a single port ("80") or an inclusive range of ports
+// ("8080-8088")
+func ParsePortRange(s string) (PortRange, error) {
+	start, end, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		end = start
+	}
+
+	r := PortRange{}
+
+	var err error
+
+	r.Start, err = strconv.Atoi(strings.TrimSpace(start))
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+
+	r.End, err = strconv.Atoi(strings.TrimSpace(end))
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+
+	return r, r.Validate()
+}
+
+func (r PortRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+
+	return strconv.Itoa(r.Start) + "-" + strconv.Itoa(r.End)
+}
+
 func parsePort(in string) int {
 	p, _ := strconv.Atoi(in)
 
@@ -539,6 +724,157 @@ type IPRange struct {
 	Start, End net.IP
 }
 
+// Validate checks that the range is either unset, or that both ends are IPv4
+// addresses with the start not greater than the end
+func (r IPRange) Validate() error {
+	if r.Start == nil && r.End == nil {
+		return nil
+	}
+
+	start, end := r.Start.To4(), r.End.To4()
+	if start == nil || end == nil {
+		return fmt.Errorf("invalid IP range %s-%s: both ends must be IPv4 addresses", r.Start, r.End)
+	}
+
+	if bytes.Compare(start, end) > 0 {
+		return fmt.Errorf("invalid IP range %s-%s: start must not be greater than end", r.Start, r.End)
+	}
+
+	return nil
+}
+
+// ParseIPRange parses a single IPv4 address ("10.0.0.1") or an inclusive range
+// of addresses ("10.0.0.1-10.0.0.255")
+func ParseIPRange(s string) (IPRange, error) {
+	start, end, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		end = start
+	}
+
+	r := IPRange{
+		Start: net.ParseIP(strings.TrimSpace(start)),
+		End:   net.ParseIP(strings.TrimSpace(end)),
+	}
+
+	if r.Start == nil || r.End == nil {
+		return IPRange{}, fmt.Errorf("invalid IP range %q", s)
+	}
+
+	return r, r.Validate()
+}
+
+func (r IPRange) String() string {
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// Equal returns true if both ranges cover the same addresses
+func (r IPRange) Equal(o IPRange) bool {
+	return r.Start.Equal(o.Start) && r.End.Equal(o.End)
+}
+
+// orAny returns the firmware's representation of "any address" if the range
+// is unset
+func (r IPRange) orAny() IPRange {
+	if r.Start == nil && r.End == nil {
+		return IPRange{net.IPv4zero, net.IPv4bcast}
+	}
+
+	return r
+}
+
+// validateProtocol checks for one of the protocol values the firmware accepts
+func validateProtocol(p string) error {
+	switch p {
+	case "TCP", "UDP", "BOTH":
+		return nil
+	default:
+		return fmt.Errorf("invalid protocol %q: must be one of TCP, UDP, or BOTH", p)
+	}
+}
+
+// protocolsOverlap returns true if rules with the given protocols could match
+// the same traffic
+func protocolsOverlap(a, b string) bool {
+	return a == b || a == "BOTH" || b == "BOTH"
+}
+
+func onOff(b bool) string {
+	if b {
+		return on
+	}
+
+	return "OFF"
+}
+
+// Validate checks that the rule can be sent to the modem
+func (s PortForwardRule) Validate() error {
+	if s.AppName == "" {
+		return fmt.Errorf("invalid port forwarding rule: AppName must be set")
+	}
+
+	if err := validateProtocol(s.Protocol); err != nil {
+		return fmt.Errorf("invalid port forwarding rule %q: %w", s.AppName, err)
+	}
+
+	if s.LocalIP.To4() == nil {
+		return fmt.Errorf("invalid port forwarding rule %q: LocalIP %q must be an IPv4 address", s.AppName, s.LocalIP)
+	}
+
+	if err := s.PublicPorts.Validate(); err != nil {
+		return fmt.Errorf("invalid port forwarding rule %q: public ports: %w", s.AppName, err)
+	}
+
+	if err := s.PrivatePorts.Validate(); err != nil {
+		return fmt.Errorf("invalid port forwarding rule %q: private ports: %w", s.AppName, err)
+	}
+
+	if s.PublicPorts.End-s.PublicPorts.Start != s.PrivatePorts.End-s.PrivatePorts.Start {
+		return fmt.Errorf("invalid port forwarding rule %q: public (%s) and private (%s) port ranges must be the same size",
+			s.AppName, s.PublicPorts, s.PrivatePorts)
+	}
+
+	if err := s.RemoteIPs.Validate(); err != nil {
+		return fmt.Errorf("invalid port forwarding rule %q: remote IPs: %w", s.AppName, err)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s PortForwardRule) MarshalJSON() ([]byte, error) {
+	remote := s.RemoteIPs.orAny()
+
+	raw := struct {
+		ID           string `json:"id"`
+		Origin       string `json:"origin"`
+		AppName      string `json:"appName"`
+		PubStart     string `json:"pubStart"`
+		PubEnd       string `json:"pubEnd"`
+		PriStart     string `json:"priStart"`
+		PriEnd       string `json:"priEnd"`
+		RuleOnOff    string `json:"ruleOnOff"`
+		Protocol     string `json:"protocol"`
+		LocalIPAddr  string `json:"localIpAddr"`
+		RemoteIPStar string `json:"remoteIpStar"`
+		RemoteIPEnd  string `json:"remoteIpEnd"`
+	}{
+		ID:           strconv.Itoa(s.ID),
+		Origin:       strconv.Itoa(s.Origin),
+		AppName:      s.AppName,
+		PubStart:     strconv.Itoa(s.PublicPorts.Start),
+		PubEnd:       strconv.Itoa(s.PublicPorts.End),
+		PriStart:     strconv.Itoa(s.PrivatePorts.Start),
+		PriEnd:       strconv.Itoa(s.PrivatePorts.End),
+		RuleOnOff:    onOff(s.Enable),
+		Protocol:     s.Protocol,
+		LocalIPAddr:  s.LocalIP.String(),
+		RemoteIPStar: remote.Start.String(),
+		RemoteIPEnd:  remote.End.String(),
+	}
+
+	return json.Marshal(raw)
+}
+
 // UnmarshalJSON - implements json.Unmarshaler
 func (s *PortForwardRule) UnmarshalJSON(b []byte) error {
 	raw := struct {
@@ -659,6 +995,91 @@ func (s *PortTriggerRule) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Validate checks that the rule can be sent to the modem
+func (s PortTriggerRule) Validate() error {
+	if s.AppName == "" {
+		return fmt.Errorf("invalid port triggering rule: AppName must be set")
+	}
+
+	if err := validateProtocol(s.Protocol); err != nil {
+		return fmt.Errorf("invalid port triggering rule %q: %w", s.AppName, err)
+	}
+
+	if err := s.TriggerPorts.Validate(); err != nil {
+		return fmt.Errorf("invalid port triggering rule %q: trigger ports: %w", s.AppName, err)
+	}
+
+	if err := s.TargetPorts.Validate(); err != nil {
+		return fmt.Errorf("invalid port triggering rule %q: target ports: %w", s.AppName, err)
+	}
+
+	if s.Timeout < time.Millisecond {
+		return fmt.Errorf("invalid port triggering rule %q: timeout %s must be at least 1ms", s.AppName, s.Timeout)
+	}
+
+	return nil
+}
+
+// conflicts returns an error if the rule's ports overlap those of any of the
+// given port triggering or port forwarding rules (other than itself). Target
+// ports must not overlap other rules' incoming ports, and trigger ports must
+// not overlap other triggers.
+func (s PortTriggerRule) conflicts(triggers []PortTriggerRule, forwards []PortForwardRule) error {
+	for _, t := range triggers {
+		if t.ID == s.ID || !protocolsOverlap(s.Protocol, t.Protocol) {
+			continue
+		}
+
+		if s.TriggerPorts.Overlaps(t.TriggerPorts) {
+			return fmt.Errorf("port triggering rule %q: trigger ports %s overlap those of rule %q (%s)",
+				s.AppName, s.TriggerPorts, t.AppName, t.TriggerPorts)
+		}
+
+		if s.TargetPorts.Overlaps(t.TargetPorts) {
+			return fmt.Errorf("port triggering rule %q: target ports %s overlap those of rule %q (%s)",
+				s.AppName, s.TargetPorts, t.AppName, t.TargetPorts)
+		}
+	}
+
+	for _, f := range forwards {
+		if protocolsOverlap(s.Protocol, f.Protocol) && s.TargetPorts.Overlaps(f.PublicPorts) {
+			return fmt.Errorf("port triggering rule %q: target ports %s overlap port forwarding rule %q (%s)",
+				s.AppName, s.TargetPorts, f.AppName, f.PublicPorts)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s PortTriggerRule) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		RuleOnOff   string `json:"ruleOnOff"`
+		AppName     string `json:"appName"`
+		Protocol    string `json:"protocol"`
+		PubStart    string `json:"pubStart"`
+		PubEnd      string `json:"pubEnd"`
+		PriStart    string `json:"priStart"`
+		PriEnd      string `json:"priEnd"`
+		Timeout     string `json:"timeout"`
+		TwoWayOnOff string `json:"twowayOnOff"`
+		ID          string `json:"id"`
+	}{
+		RuleOnOff:   onOff(s.Enable),
+		AppName:     s.AppName,
+		Protocol:    s.Protocol,
+		PubStart:    strconv.Itoa(s.TriggerPorts.Start),
+		PubEnd:      strconv.Itoa(s.TriggerPorts.End),
+		PriStart:    strconv.Itoa(s.TargetPorts.Start),
+		PriEnd:      strconv.Itoa(s.TargetPorts.End),
+		Timeout:     strconv.FormatInt(s.Timeout.Milliseconds(), 10),
+		TwoWayOnOff: onOff(s.TwoWay),
+		ID:          strconv.Itoa(s.ID),
+	}
+
+	return json.Marshal(raw)
+}
+
 // RouterTR069 -
 type RouterTR069 struct {
 	Error
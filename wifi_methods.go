@@ -4,7 +4,13 @@ package hitron
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // WiFiRadioDetails - get details from /WiFi/Radios/<n>
@@ -13,3 +19,397 @@ func (c *CableModem) WiFiRadioDetails(ctx context.Context, radio int) (out WiFiR
 
 	return out, err
 }
+
+// WiFiRadioAdvancedDetails - get the advanced settings for radio <n> from
+// /WiFi/Radios/Advanced
+func (c *CableModem) WiFiRadioAdvancedDetails(ctx context.Context, radio int) (WiFiRadioAdvanced, error) {
+	all, err := c.WiFiRadiosAdvanced(ctx)
+	if err != nil {
+		return WiFiRadioAdvanced{}, err
+	}
+
+	suffix := "/WiFi/Radios/" + strconv.Itoa(radio)
+	for _, r := range all.Radios {
+		if strings.HasSuffix(r.RadioURI, suffix) {
+			return r, nil
+		}
+	}
+
+	return WiFiRadioAdvanced{}, fmt.Errorf("no radio with ID %d", radio)
+}
+
+// UpdateSSID - changes the settings of the SSID with the given ID, and returns
+// the refreshed SSID. The changes are validated before anything is sent.
+func (c *CableModem) UpdateSSID(ctx context.Context, id int, changes SSIDChanges) (SSID, error) {
+	ssid, err := c.ssid(ctx, id)
+	if err != nil {
+		return SSID{}, err
+	}
+
+	ssid = changes.apply(ssid)
+
+	if err = ssid.Validate(); err != nil {
+		return SSID{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, c.relativePath(ssid.URI, "/WiFi/SSIDs/"+strconv.Itoa(id)), ssid)
+	if err != nil {
+		return SSID{}, fmt.Errorf("failed to update SSID %d: %w", id, err)
+	}
+
+	return c.ssid(ctx, id)
+}
+
+// UpdateRadio - changes the settings of the given radio (as numbered in
+// WiFiRadioDetails), and returns the refreshed settings. The changes are
+// validated before anything is sent.
+func (c *CableModem) UpdateRadio(ctx context.Context, radio int, changes RadioChanges) (WiFiRadio, error) {
+	r, err := c.WiFiRadioDetails(ctx, radio)
+	if err != nil {
+		return WiFiRadio{}, err
+	}
+
+	r = changes.apply(r)
+
+	if err = r.Validate(); err != nil {
+		return WiFiRadio{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, c.relativePath(r.RadioURI, "/WiFi/Radios/"+strconv.Itoa(radio)), r)
+	if err != nil {
+		return WiFiRadio{}, fmt.Errorf("failed to update radio %d: %w", radio, err)
+	}
+
+	return c.WiFiRadioDetails(ctx, radio)
+}
+
+// UpdateRadioAdvanced - changes the advanced settings of the given radio (as
+// numbered in WiFiRadioDetails), and returns the refreshed settings. The
+// changes are validated before anything is sent.
+func (c *CableModem) UpdateRadioAdvanced(ctx context.Context, radio int, changes AdvancedRadioChanges) (WiFiRadioAdvanced, error) {
+	r, err := c.WiFiRadioAdvancedDetails(ctx, radio)
+	if err != nil {
+		return WiFiRadioAdvanced{}, err
+	}
+
+	r = changes.apply(r)
+
+	if err = r.Validate(); err != nil {
+		return WiFiRadioAdvanced{}, err
+	}
+
+	// advanced settings are saved through the radio's own URI
+	err = c.sendModel(ctx, http.MethodPut, c.relativePath(r.RadioURI, "/WiFi/Radios/"+strconv.Itoa(radio)), r)
+	if err != nil {
+		return WiFiRadioAdvanced{}, fmt.Errorf("failed to update radio %d: %w", radio, err)
+	}
+
+	return c.WiFiRadioAdvancedDetails(ctx, radio)
+}
+
+// UpdateGuestSSID - changes the guest network settings, and returns the
+// refreshed settings. The changes are validated before anything is sent.
+func (c *CableModem) UpdateGuestSSID(ctx context.Context, changes GuestSSIDChanges) (WiFiGuestSSID, error) {
+	guest, err := c.WiFiGuestSSID(ctx)
+	if err != nil {
+		return WiFiGuestSSID{}, err
+	}
+
+	guest = changes.apply(guest)
+
+	if err = guest.Validate(); err != nil {
+		return WiFiGuestSSID{}, err
+	}
+
+	err = c.sendModel(ctx, http.MethodPut, "/WiFi/GuestSSID", guest)
+	if err != nil {
+		return WiFiGuestSSID{}, fmt.Errorf("failed to update guest network: %w", err)
+	}
+
+	return c.WiFiGuestSSID(ctx)
+}
+
+// SetGuestEnabled - enables or disables the guest network
+func (c *CableModem) SetGuestEnabled(ctx context.Context, enable bool) (WiFiGuestSSID, error) {
+	return c.UpdateGuestSSID(ctx, GuestSSIDChanges{Enable: &enable})
+}
+
+// RotateGuestPassword - sets the guest network password to a new randomly
+// generated value, and returns the refreshed settings
+func (c *CableModem) RotateGuestPassword(ctx context.Context) (WiFiGuestSSID, error) {
+	password, err := GeneratePassphrase(16)
+	if err != nil {
+		return WiFiGuestSSID{}, err
+	}
+
+	return c.UpdateGuestSSID(ctx, GuestSSIDChanges{Password: &password})
+}
+
+func (c *CableModem) ssid(ctx context.Context, id int) (SSID, error) {
+	all, err := c.WiFiSSIDs(ctx)
+	if err != nil {
+		return SSID{}, err
+	}
+
+	for _, s := range all.SSIDs {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+
+	return SSID{}, fmt.Errorf("no SSID with ID %d", id)
+}
+
+// AddAccessControlRule - adds a device to the WiFi access control list, and
+// returns the refreshed list. Whether listed devices are allowed or blocked
+// depends on the list's mode (see SetAccessControlMode). The rule's ID is
+// assigned by the modem.
+func (c *CableModem) AddAccessControlRule(ctx context.Context, rule WiFiAccessControlRule) (WiFiAccessControl, error) {
+	if err := rule.Validate(); err != nil {
+		return WiFiAccessControl{}, err
+	}
+
+	all, err := c.WiFiAccessControl(ctx)
+	if err != nil {
+		return WiFiAccessControl{}, err
+	}
+
+	if existing, ok := all.findRule(rule.MACAddr); ok {
+		return WiFiAccessControl{}, fmt.Errorf("%s is already in the access control list (as %q)", rule.MACAddr, existing.Hostname)
+	}
+
+	rule.ID = 0
+
+	err = c.sendModel(ctx, http.MethodPost, "/WiFi/AccessControl", rule)
+	if err != nil {
+		return WiFiAccessControl{}, fmt.Errorf("failed to add access control rule for %s: %w", rule.MACAddr, err)
+	}
+
+	return c.WiFiAccessControl(ctx)
+}
+
+// DeleteAccessControlRule - removes the device with the given MAC address from
+// the WiFi access control list, and returns the refreshed list.
+func (c *CableModem) DeleteAccessControlRule(ctx context.Context, mac net.HardwareAddr) (WiFiAccessControl, error) {
+	all, err := c.WiFiAccessControl(ctx)
+	if err != nil {
+		return WiFiAccessControl{}, err
+	}
+
+	rule, ok := all.findRule(mac)
+	if !ok {
+		return WiFiAccessControl{}, fmt.Errorf("%s is not in the access control list", mac)
+	}
+
+	err = c.sendModel(ctx, http.MethodDelete, accessControlRulePath(rule.ID), rule)
+	if err != nil {
+		return WiFiAccessControl{}, fmt.Errorf("failed to delete access control rule for %s: %w", mac, err)
+	}
+
+	return c.WiFiAccessControl(ctx)
+}
+
+// ReplaceAccessControlRules - makes the WiFi access control list match the
+// given rules (matched by MAC address), adding, renaming, and removing rules as
+// needed, and returns the refreshed list. The rules are all validated before
+// anything is changed.
+func (c *CableModem) ReplaceAccessControlRules(ctx context.Context, rules []WiFiAccessControlRule) (WiFiAccessControl, error) {
+	seen := map[string]bool{}
+
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return WiFiAccessControl{}, err
+		}
+
+		if seen[r.MACAddr.String()] {
+			return WiFiAccessControl{}, fmt.Errorf("duplicate access control rule for %s", r.MACAddr)
+		}
+
+		seen[r.MACAddr.String()] = true
+	}
+
+	all, err := c.WiFiAccessControl(ctx)
+	if err != nil {
+		return WiFiAccessControl{}, err
+	}
+
+	for _, existing := range all.RulesList {
+		if seen[existing.MACAddr.String()] {
+			continue
+		}
+
+		err = c.sendModel(ctx, http.MethodDelete, accessControlRulePath(existing.ID), existing)
+		if err != nil {
+			return WiFiAccessControl{}, fmt.Errorf("failed to delete access control rule for %s: %w", existing.MACAddr, err)
+		}
+	}
+
+	for _, r := range rules {
+		existing, ok := all.findRule(r.MACAddr)
+
+		switch {
+		case !ok:
+			r.ID = 0
+			err = c.sendModel(ctx, http.MethodPost, "/WiFi/AccessControl", r)
+		case existing.Hostname != r.Hostname:
+			r.ID = existing.ID
+			err = c.sendModel(ctx, http.MethodPut, accessControlRulePath(r.ID), r)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return WiFiAccessControl{}, fmt.Errorf("failed to save access control rule for %s: %w", r.MACAddr, err)
+		}
+	}
+
+	return c.WiFiAccessControl(ctx)
+}
+
+// SetAccessControlMode - sets the WiFi access control mode to one of
+// AccessControlAllow, AccessControlBlock, or AccessControlDisabled
+func (c *CableModem) SetAccessControlMode(ctx context.Context, mode string) (WiFiAccessControlStatus, error) {
+	switch mode {
+	case AccessControlAllow, AccessControlBlock, AccessControlDisabled:
+	default:
+		return WiFiAccessControlStatus{}, fmt.Errorf("invalid access control mode %q", mode)
+	}
+
+	err := c.sendModel(ctx, http.MethodPut, "/WiFi/AccessControl/Status", map[string]string{"blockType": mode})
+	if err != nil {
+		return WiFiAccessControlStatus{}, fmt.Errorf("failed to set access control mode: %w", err)
+	}
+
+	return c.WiFiAccessControlStatus(ctx)
+}
+
+// DisableAccessControl - turns off WiFi access control, leaving the list of
+// rules in place
+func (c *CableModem) DisableAccessControl(ctx context.Context) (WiFiAccessControlStatus, error) {
+	return c.SetAccessControlMode(ctx, AccessControlDisabled)
+}
+
+func accessControlRulePath(id int) string {
+	return "/WiFi/AccessControl/" + strconv.Itoa(id)
+}
+
+// WPSWalkTime - how long a WPS session lasts, per the WPS specification. This
+// is the default timeout for WaitWPS.
+const WPSWalkTime = 2 * time.Minute
+
+// wpsPollInterval - how often WaitWPS checks the session's status
+//
+//nolint:gochecknoglobals
+var wpsPollInterval = 2 * time.Second
+
+// StartWPS - starts a WPS session, so that a device can join the WiFi network
+// without a passphrase. The method is either WPSMethodPushButton (in which
+// case pin must be empty), or WPSMethodPIN with the device's 4- or 8-digit
+// PIN. Use WaitWPS to wait for the outcome.
+func (c *CableModem) StartWPS(ctx context.Context, method, pin string) (WiFiWPS, error) {
+	switch method {
+	case WPSMethodPushButton:
+		if pin != "" {
+			return WiFiWPS{}, fmt.Errorf("a PIN can't be used with the %s method", method)
+		}
+	case WPSMethodPIN:
+		if err := validateWPSPin(pin); err != nil {
+			return WiFiWPS{}, err
+		}
+	default:
+		return WiFiWPS{}, fmt.Errorf("invalid WPS method %q", method)
+	}
+
+	wps, err := c.WiFiWPS(ctx)
+	if err != nil {
+		return WiFiWPS{}, err
+	}
+
+	if !wps.Enable {
+		return WiFiWPS{}, fmt.Errorf("WPS is disabled")
+	}
+
+	if wps.State() == WPSInProgress {
+		return WiFiWPS{}, fmt.Errorf("a WPS session is already in progress (%s)", wps.Status)
+	}
+
+	wps.Method = method
+	wps.ClientPin = pin
+
+	err = c.sendModel(ctx, http.MethodPut, "/WiFi/WPS", wps)
+	if err != nil {
+		return WiFiWPS{}, fmt.Errorf("failed to start WPS session: %w", err)
+	}
+
+	return c.WiFiWPS(ctx)
+}
+
+// WaitWPS - polls /WiFi/WPS until the current WPS session succeeds, fails, or
+// times out. If ctx has no deadline, WPSWalkTime is used. Any progress
+// functions are called with each status read. If the session has already
+// ended (or none was started), WaitWPS returns after the first read.
+//
+// Reaching the timeout isn't an error - the result's Outcome is WPSTimedOut.
+func (c *CableModem) WaitWPS(ctx context.Context, progress ...func(WiFiWPS)) (WPSResult, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, WPSWalkTime)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(wpsPollInterval)
+	defer ticker.Stop()
+
+	result := WPSResult{}
+
+	for {
+		wps, err := c.WiFiWPS(ctx)
+
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			result.Outcome = WPSTimedOut
+
+			return result, nil
+		case err != nil:
+			return result, err
+		}
+
+		result.WiFiWPS = wps
+
+		for _, f := range progress {
+			f(wps)
+		}
+
+		if state := wps.State(); state != WPSInProgress {
+			result.Outcome = state
+
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				result.Outcome = WPSTimedOut
+
+				return result, nil
+			}
+
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// relativePath - converts a URI returned by the modem (like
+// "/1/Device/WiFi/SSIDs/1") to a path relative to the base URL, or returns
+// the fallback if the URI is empty
+func (c *CableModem) relativePath(uri, fallback string) string {
+	if uri == "" {
+		return fallback
+	}
+
+	base := strings.TrimSuffix(c.base.Path, "/")
+
+	return strings.TrimPrefix(uri, base)
+}
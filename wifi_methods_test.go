@@ -0,0 +1,386 @@
+package hitron
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ssidsBody = `{"errCode":"000","errMsg":"",
+	"SSIDNumberOfEntries":1, "SSIDs_List":[
+		{"id":"1","ssidName":"CODA","band":"2.4G","enable":"ON",
+		"wlswpsOnOff":"ON","ifName":"ath0","bssid":"CA:FE:DE:AD:BE:EF",
+		"radio":"1","visible":"ON","wmm":"ON","authMode":"4","SecuMode":"2",
+		"encryptType":"3","passPhrase":"supersecret",
+		"wlsEnable":"ON", "SSID_URI":"\/1\/Device\/WiFi\/SSIDs\/1",
+		"defaultKey":"1234567890","bandsteer":"ON","primary":"YES"}
+	],
+	"Guests_List":[]
+}`
+
+func TestUpdateSSID(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/1/Device/WiFi/SSIDs": ssidsBody})
+	d := &CableModem{base: mustParse(srv.URL + "/1/Device/"), hc: srv.Client()}
+
+	ctx := context.Background()
+
+	_, err := d.UpdateSSID(ctx, 42, SSIDChanges{})
+	require.Error(t, err)
+
+	short := "short"
+	_, err = d.UpdateSSID(ctx, 1, SSIDChanges{Passphrase: &short})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	name := "NewName"
+	pass := "a new passphrase"
+	hidden := false
+
+	s, err := d.UpdateSSID(ctx, 1, SSIDChanges{Name: &name, Passphrase: &pass, Visible: &hidden})
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.ID)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/1/Device/WiFi/SSIDs/1", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.Equal(t, "csrf-token", w[0].CSRF)
+	assert.JSONEq(t, `{"id":"1","ssidName":"NewName","band":"2.4G","enable":"ON",
+		"wlswpsOnOff":"ON","ifName":"ath0","bssid":"CA:FE:DE:AD:BE:EF",
+		"radio":"1","visible":"OFF","wmm":"ON","authMode":"4","SecuMode":"2",
+		"encryptType":"3","passPhrase":"a new passphrase",
+		"wlsEnable":"ON","SSID_URI":"/1/Device/WiFi/SSIDs/1",
+		"defaultKey":"1234567890","bandsteer":"ON","primary":"YES"}`, w[0].Model)
+}
+
+func TestUpdateGuestSSID(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/GuestSSID": `{"errCode":"000","errMsg":"",
+		"ssidName":"CODA-Guest","ssidName5G":"CODA-Guest-5G","enable":"OFF",
+		"pswd":"GuestPassword","adminGuestAccProvider":"10"}`})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	zero := 0
+	_, err := d.UpdateGuestSSID(ctx, GuestSSIDChanges{MaxUsers: &zero})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, err = d.SetGuestEnabled(ctx, true)
+	require.NoError(t, err)
+
+	_, err = d.RotateGuestPassword(ctx)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, "/WiFi/GuestSSID", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"ssidName":"CODA-Guest","ssidName5G":"CODA-Guest-5G","enable":"ON",
+		"pswd":"GuestPassword","adminGuestAccProvider":"10"}`, w[0].Model)
+
+	// the writeServer doesn't keep state, so the guest network is still
+	// disabled, but the password should be new
+	assert.Contains(t, w[1].Model, `"enable":"OFF"`)
+	assert.NotContains(t, w[1].Model, "GuestPassword")
+}
+
+func TestSetGuestEnabled_NoPassword(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/GuestSSID": `{"errCode":"000","errMsg":"",
+		"ssidName":"CODA-Guest","ssidName5G":"CODA-Guest-5G","enable":"ON",
+		"pswd":"","adminGuestAccProvider":"10"}`})
+	d := testCableModem(srv)
+
+	_, err := d.SetGuestEnabled(context.Background(), false)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.JSONEq(t, `{"ssidName":"CODA-Guest","ssidName5G":"CODA-Guest-5G","enable":"OFF",
+		"pswd":"","adminGuestAccProvider":"10"}`, w[0].Model)
+}
+
+func TestUpdateRadio(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/Radios/2": `{"errCode":"000","errMsg":"",
+		"vendor":"1","band":"5G","wlsOnOff":"ON","wlsDcsOnOff":"OFF",
+		"wlsMode":"9","n_bandwidth":"80MHZ","wlsChannel":"0",
+		"autoChannel":"ON","wlsDfsOnOff":"OFF","wlsCurrentChannel":"36",
+		"wlswpsOnOff":"ON","igmpSnoop":"ON","Radio_URI":"/WiFi/Radios/2"}`})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	auto := false
+	channel := 52
+
+	_, err := d.UpdateRadio(ctx, 2, RadioChanges{AutoChannel: &auto, Channel: &channel})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	dfs := true
+
+	_, err = d.UpdateRadio(ctx, 2, RadioChanges{AutoChannel: &auto, Channel: &channel, EnableDFS: &dfs})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/WiFi/Radios/2", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"vendor":"1","band":"5G","wlsOnOff":"ON","wlsDcsOnOff":"OFF",
+		"wlsMode":"9","n_bandwidth":"80MHZ","wlsChannel":"52",
+		"autoChannel":"OFF","wlsDfsOnOff":"ON","wlsCurrentChannel":"36",
+		"wlswpsOnOff":"ON","igmpSnoop":"ON","Radio_URI":"/WiFi/Radios/2"}`, w[0].Model)
+
+	// bandwidths are sent as the modem reports them, whatever their case
+	bw := "40mhz"
+
+	_, err = d.UpdateRadio(ctx, 2, RadioChanges{ChanBandwidth: &bw})
+	require.NoError(t, err)
+
+	w = writes()
+	require.Len(t, w, 2)
+	assert.Contains(t, w[1].Model, `"n_bandwidth":"40MHZ"`)
+}
+
+func TestUpdateRadioAdvanced(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/Radios/Advanced": `{"errCode":"000","errMsg":"",
+		"Advanced_List":[{"vendor":"1","band":"2.4G","wlsOnOff":"ON","wlsDcsOnOff":"ON",
+		"wlsMode":"4","n_bandwidth":"20/40MHZ","wlsChannel":"3","autoChannel":"OFF",
+		"wlsDfsOnOff":"OFF","wlsCurrentChannel":"3","wlswpsOnOff":"ON","igmpSnoop":"ON",
+		"Radio_URI":"/WiFi/Radios/1","bgMode":"","n_coexistence":"Enabled",
+		"n_OperatingMode":"Mixed Mode","n_GuardInterval":"Long","n_mcs":"0",
+		"n_rdg":"Disabled","n_amsdu":"Enabled","n_autoba":"Enabled","n_badecline":"Disabled",
+		"tx_stream":"","rx_stream":"","bandsteering":"ON","ssidName":"CODA","showMSO":"false"}]}`})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.UpdateRadioAdvanced(ctx, 2, AdvancedRadioChanges{})
+	require.Error(t, err)
+
+	off := false
+
+	_, err = d.UpdateRadioAdvanced(ctx, 1, AdvancedRadioChanges{BandSteering: &off, NCoexistence: &off})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/WiFi/Radios/1", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"vendor":"1","band":"2.4G","wlsOnOff":"ON","wlsDcsOnOff":"ON",
+		"wlsMode":"4","n_bandwidth":"20/40MHZ","wlsChannel":"3","autoChannel":"OFF",
+		"wlsDfsOnOff":"OFF","wlsCurrentChannel":"3","wlswpsOnOff":"ON","igmpSnoop":"ON",
+		"Radio_URI":"/WiFi/Radios/1","bgMode":"","n_coexistence":"Disabled",
+		"n_OperatingMode":"Mixed Mode","n_GuardInterval":"Long","n_mcs":"0",
+		"n_rdg":"Disabled","n_amsdu":"Enabled","n_autoba":"Enabled","n_badecline":"Disabled",
+		"tx_stream":"","rx_stream":"","bandsteering":"OFF","ssidName":"CODA","showMSO":"false"}`, w[0].Model)
+}
+
+const accessControlBody = `{"errCode":"000","errMsg":"","blockType":"Block Listed",
+	"RuleNumberOfEntries":1,"Rules_List":[{"id":0,"hostName":"foo","macAddr":"AA:BB:CC:DD:EE:FF"}]}`
+
+func TestAddAccessControlRule(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/AccessControl": accessControlBody})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	dup, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	_, err := d.AddAccessControlRule(ctx, WiFiAccessControlRule{Hostname: "dup", MACAddr: dup})
+	require.Error(t, err)
+
+	_, err = d.AddAccessControlRule(ctx, WiFiAccessControlRule{Hostname: "none"})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	mac, _ := net.ParseMAC("12:34:56:78:9a:bc")
+	_, err = d.AddAccessControlRule(ctx, WiFiAccessControlRule{Hostname: "phone", MACAddr: mac})
+	require.NoError(t, err)
+
+	_, err = d.DeleteAccessControlRule(ctx, mac)
+	require.Error(t, err)
+
+	_, err = d.DeleteAccessControlRule(ctx, dup)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, recordedWrite{
+		Path: "/WiFi/AccessControl", Method: http.MethodPost, CSRF: "csrf-token",
+		Model: `{"hostName":"phone","macAddr":"12:34:56:78:9A:BC","id":0}`,
+	}, w[0])
+	assert.Equal(t, "/WiFi/AccessControl/0", w[1].Path)
+	assert.Equal(t, http.MethodDelete, w[1].Method)
+}
+
+func TestReplaceAccessControlRules(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/AccessControl": `{"errCode":"000","errMsg":"",
+		"blockType":"Allow Listed","Rules_List":[
+			{"id":0,"hostName":"keep","macAddr":"AA:BB:CC:DD:EE:00"},
+			{"id":1,"hostName":"old name","macAddr":"AA:BB:CC:DD:EE:01"},
+			{"id":2,"hostName":"remove","macAddr":"AA:BB:CC:DD:EE:02"}]}`})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	mac := func(s string) net.HardwareAddr {
+		m, _ := net.ParseMAC(s)
+
+		return m
+	}
+
+	_, err := d.ReplaceAccessControlRules(ctx, []WiFiAccessControlRule{
+		{Hostname: "a", MACAddr: mac("aa:bb:cc:dd:ee:03")},
+		{Hostname: "b", MACAddr: mac("AA:BB:CC:DD:EE:03")},
+	})
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, err = d.ReplaceAccessControlRules(ctx, []WiFiAccessControlRule{
+		{Hostname: "keep", MACAddr: mac("aa:bb:cc:dd:ee:00")},
+		{Hostname: "new name", MACAddr: mac("aa:bb:cc:dd:ee:01")},
+		{Hostname: "new", MACAddr: mac("aa:bb:cc:dd:ee:03")},
+	})
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 3)
+	assert.Equal(t, http.MethodDelete+" /WiFi/AccessControl/2", w[0].Method+" "+w[0].Path)
+	assert.Equal(t, http.MethodPut+" /WiFi/AccessControl/1", w[1].Method+" "+w[1].Path)
+	assert.Contains(t, w[1].Model, "new name")
+	assert.Equal(t, http.MethodPost+" /WiFi/AccessControl", w[2].Method+" "+w[2].Path)
+}
+
+func TestSetAccessControlMode(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{
+		"/WiFi/AccessControl/Status": `{"errCode":"000","errMsg":"","blockType":"Disabled"}`,
+	})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.SetAccessControlMode(ctx, "Deny")
+	require.Error(t, err)
+
+	_, err = d.SetAccessControlMode(ctx, AccessControlAllow)
+	require.NoError(t, err)
+
+	_, err = d.DisableAccessControl(ctx)
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 2)
+	assert.Equal(t, "/WiFi/AccessControl/Status", w[0].Path)
+	assert.JSONEq(t, `{"blockType":"Allow Listed"}`, w[0].Model)
+	assert.JSONEq(t, `{"blockType":"Disabled"}`, w[1].Model)
+}
+
+func TestStartWPS(t *testing.T) {
+	srv, writes := writeServer(t, map[string]string{"/WiFi/WPS": `{"errCode":"000","errMsg":"",
+		"wlswpsOnOff":"ON","wlsWpsMethod":"PushButton","wlsWpsClientPin":"",
+		"wlsWpsStatus":"Idle","wlsWpsTimeElapsed":"0"}`})
+	d := testCableModem(srv)
+
+	ctx := context.Background()
+
+	_, err := d.StartWPS(ctx, "NFC", "")
+	require.Error(t, err)
+
+	_, err = d.StartWPS(ctx, WPSMethodPushButton, "1234")
+	require.Error(t, err)
+
+	_, err = d.StartWPS(ctx, WPSMethodPIN, "12345678")
+	require.Error(t, err)
+	assert.Empty(t, writes())
+
+	_, err = d.StartWPS(ctx, WPSMethodPIN, "12345670")
+	require.NoError(t, err)
+
+	w := writes()
+	require.Len(t, w, 1)
+	assert.Equal(t, "/WiFi/WPS", w[0].Path)
+	assert.Equal(t, http.MethodPut, w[0].Method)
+	assert.JSONEq(t, `{"wlswpsOnOff":"ON","wlsWpsMethod":"PIN","wlsWpsClientPin":"12345670",
+		"wlsWpsStatus":"Idle","wlsWpsTimeElapsed":"0"}`, w[0].Model)
+}
+
+// wpsServer serves each of the given statuses in turn, repeating the last one
+func wpsServer(t *testing.T, statuses ...string) *httptest.Server {
+	t.Helper()
+
+	var n atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		i := min(int(n.Add(1))-1, len(statuses)-1)
+		fmt.Fprintf(w, `{"errCode":"000","errMsg":"","wlswpsOnOff":"ON","wlsWpsMethod":"PushButton",
+			"wlsWpsStatus":%q,"wlsWpsTimeElapsed":"%d"}`, statuses[i], i)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWaitWPS(t *testing.T) {
+	wpsPollInterval = time.Millisecond
+
+	defer func() { wpsPollInterval = 2 * time.Second }()
+
+	ctx := context.Background()
+
+	d := testCableModem(wpsServer(t, "In Progress", "In Progress", "In Progress", "Success"))
+
+	var seen []string
+
+	r, err := d.WaitWPS(ctx, func(w WiFiWPS) { seen = append(seen, w.Status) })
+	require.NoError(t, err)
+	assert.Equal(t, WPSSuccess, r.Outcome)
+	assert.Equal(t, 3*time.Second, r.TimeElapsed)
+	assert.Equal(t, []string{"In Progress", "In Progress", "In Progress", "Success"}, seen)
+
+	// a session which has already ended is returned without polling again
+	for _, status := range []string{"Success", "Idle"} {
+		seen = nil
+		d = testCableModem(wpsServer(t, status, "In Progress"))
+
+		r, err = d.WaitWPS(ctx, func(w WiFiWPS) { seen = append(seen, w.Status) })
+		require.NoError(t, err)
+		assert.Equal(t, []string{status}, seen)
+		assert.Equal(t, WiFiWPS{Status: status}.State(), r.Outcome)
+	}
+
+	d = testCableModem(wpsServer(t, "In Progress", "Overlap"))
+	r, err = d.WaitWPS(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, WPSFailed, r.Outcome)
+
+	d = testCableModem(wpsServer(t, "In Progress", "Idle"))
+	r, err = d.WaitWPS(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, WPSIdle, r.Outcome)
+
+	d = testCableModem(wpsServer(t, "In Progress"))
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+
+	defer cancel()
+
+	r, err = d.WaitWPS(tctx)
+	require.NoError(t, err)
+	assert.Equal(t, WPSTimedOut, r.Outcome)
+
+	// the last status read is still available, and differs from the outcome
+	assert.Equal(t, WPSInProgress, r.State())
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = d.WaitWPS(cctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
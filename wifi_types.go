@@ -1,9 +1,13 @@
 package hitron
 
 import (
+	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -70,6 +74,62 @@ type WiFiAccessControlStatus struct {
 	BlockType string
 }
 
+// Access control modes (BlockType values)
+const (
+	AccessControlDisabled = "Disabled"
+	AccessControlAllow    = "Allow Listed" // only listed devices may connect
+	AccessControlBlock    = "Block Listed" // listed devices may not connect
+)
+
+// Validate checks that the rule can be sent to the modem
+func (s WiFiAccessControlRule) Validate() error {
+	if err := validateMAC(s.MACAddr); err != nil {
+		return fmt.Errorf("invalid access control rule %q: %w", s.Hostname, err)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s WiFiAccessControlRule) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		Hostname string `json:"hostName"`
+		MACAddr  string `json:"macAddr"`
+		ID       int    `json:"id"`
+	}{
+		ID:       s.ID,
+		Hostname: s.Hostname,
+		MACAddr:  strings.ToUpper(s.MACAddr.String()),
+	}
+
+	return json.Marshal(raw)
+}
+
+// validateMAC checks that the address could belong to a WiFi client: it must
+// be a 48-bit unicast address
+func validateMAC(mac net.HardwareAddr) error {
+	if len(mac) != 6 {
+		return fmt.Errorf("MAC address %q must be 6 bytes long", mac)
+	}
+
+	if mac[0]&1 != 0 {
+		return fmt.Errorf("MAC address %s is not a unicast address", mac)
+	}
+
+	return nil
+}
+
+// findRule returns the rule with the given MAC address, if there is one
+func (s WiFiAccessControl) findRule(mac net.HardwareAddr) (WiFiAccessControlRule, bool) {
+	for _, r := range s.RulesList {
+		if bytes.Equal(r.MACAddr, mac) {
+			return r, true
+		}
+	}
+
+	return WiFiAccessControlRule{}, false
+}
+
 // WiFiGuestSSID -
 type WiFiGuestSSID struct {
 	Error
@@ -106,6 +166,109 @@ func (s *WiFiGuestSSID) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// GuestSSIDChanges - changes to make to the guest network with
+// UpdateGuestSSID. Nil fields are left unchanged.
+type GuestSSIDChanges struct {
+	SSID     *string
+	SSID5G   *string
+	Password *string
+	MaxUsers *int
+	Enable   *bool
+}
+
+// apply returns a copy of the guest network settings with the changes made
+func (c GuestSSIDChanges) apply(s WiFiGuestSSID) WiFiGuestSSID {
+	setString(&s.SSID, c.SSID)
+	setString(&s.SSID5G, c.SSID5G)
+	setString(&s.Password, c.Password)
+	setBool(&s.Enable, c.Enable)
+
+	if c.MaxUsers != nil {
+		s.MaxUsers = *c.MaxUsers
+	}
+
+	return s
+}
+
+// Validate checks that the guest network settings can be sent to the modem.
+// The password is only required when the guest network is enabled, so that it
+// can be disabled on a modem which has no password stored.
+func (s WiFiGuestSSID) Validate() error {
+	for _, name := range []string{s.SSID, s.SSID5G} {
+		if l := len(name); l < 1 || l > 32 {
+			return fmt.Errorf("invalid guest SSID %q: name must be 1-32 bytes long, not %d", name, l)
+		}
+	}
+
+	if s.MaxUsers < 1 {
+		return fmt.Errorf("invalid guest network: MaxUsers must be at least 1, not %d", s.MaxUsers)
+	}
+
+	if !s.Enable {
+		return nil
+	}
+
+	return validatePassphrase(s.Password)
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s WiFiGuestSSID) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		SSIDName              string `json:"ssidName"`
+		SSIDName5G            string `json:"ssidName5G"`
+		Enable                string `json:"enable"`
+		Pswd                  string `json:"pswd"`
+		AdminGuestAccProvider string `json:"adminGuestAccProvider"`
+	}{
+		SSIDName:              s.SSID,
+		SSIDName5G:            s.SSID5G,
+		Enable:                onOff(s.Enable),
+		Pswd:                  s.Password,
+		AdminGuestAccProvider: strconv.Itoa(s.MaxUsers),
+	}
+
+	return json.Marshal(raw)
+}
+
+// passphraseAlphabet - characters for generated passphrases, leaving out
+// those which are easily confused when read from a screen (0/O, 1/l/I)
+const passphraseAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassphrase returns a random passphrase of the given length (between
+// 8 and 63), using a cryptographically secure random number generator
+func GeneratePassphrase(length int) (string, error) {
+	if length < 8 || length > 63 {
+		return "", fmt.Errorf("invalid passphrase length %d: must be 8-63", length)
+	}
+
+	out := make([]byte, length)
+	limit := big.NewInt(int64(len(passphraseAlphabet)))
+
+	for i := range out {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate passphrase: %w", err)
+		}
+
+		out[i] = passphraseAlphabet[n.Int64()]
+	}
+
+	return string(out), nil
+}
+
+// WiFiQRPayload returns the payload for a QR code which joins the given
+// WPA network (or open network, if the passphrase is empty), in the format
+// understood by most phone cameras
+func WiFiQRPayload(ssid, passphrase string) string {
+	escape := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, ":", `\:`, `"`, `\"`)
+
+	if passphrase == "" {
+		return "WIFI:T:nopass;S:" + escape.Replace(ssid) + ";;"
+	}
+
+	return "WIFI:T:WPA;S:" + escape.Replace(ssid) + ";P:" + escape.Replace(passphrase) + ";;"
+}
+
 // WiFiRadios -
 type WiFiRadios struct {
 	Error
@@ -176,27 +339,223 @@ func (s *WiFiRadio) UnmarshalJSON(b []byte) error {
 		s.Channel, _ = strconv.Atoi(c)
 	}
 
-	// map according to the CODA's UI
-	wirelessMode := map[string]WiFiMode{
-		"0": WiFiModeB,
-		"1": WiFiModeG,
-		"2": WiFiModeN,
-		"3": WiFiModeB | WiFiModeG | WiFiModeN,
-		"4": WiFiModeG | WiFiModeN,
-		"5": WiFiModeB | WiFiModeG,
-		"6": WiFiModeAC,
-		"7": WiFiModeA,
-		"8": WiFiModeA | WiFiModeN,
-		"9": WiFiModeA | WiFiModeN | WiFiModeAC,
+	s.Mode = wirelessModes[raw.WlsMode]
+
+	return nil
+}
+
+// ParseWiFiMode parses a mode in the form returned by WiFiMode.String, with or
+// without the "802.11" prefix (e.g. "802.11g/n" or "a/n/ac")
+func ParseWiFiMode(s string) (WiFiMode, error) {
+	modes := map[string]WiFiMode{
+		"a": WiFiModeA, "b": WiFiModeB, "g": WiFiModeG, "n": WiFiModeN, "ac": WiFiModeAC,
+	}
+
+	var m WiFiMode
+
+	for _, p := range strings.Split(strings.TrimPrefix(strings.ToLower(s), "802.11"), "/") {
+		mode, ok := modes[p]
+		if !ok {
+			return 0, fmt.Errorf("invalid WiFi mode %q", s)
+		}
+
+		m |= mode
+	}
+
+	return m, nil
+}
+
+// Channel bandwidths, in the form the modem uses
+const (
+	Bandwidth20MHz    = "20MHZ"
+	Bandwidth20_40MHz = "20/40MHZ" //nolint:revive,stylecheck
+	Bandwidth40MHz    = "40MHZ"
+	Bandwidth80MHz    = "80MHZ"
+)
+
+// RadioChanges - changes to make to a radio with UpdateRadio. Nil fields are
+// left unchanged.
+type RadioChanges struct {
+	Channel       *int
+	ChanBandwidth *string
+	Mode          *WiFiMode
+	AutoChannel   *bool
+	EnableDFS     *bool
+	EnableDCS     *bool
+	Enable        *bool
+}
+
+// apply returns a copy of the radio with the changes made
+func (c RadioChanges) apply(r WiFiRadio) WiFiRadio {
+	setString(&r.ChanBandwidth, c.ChanBandwidth)
+	setBool(&r.AutoChannel, c.AutoChannel)
+	setBool(&r.EnableDFS, c.EnableDFS)
+	setBool(&r.EnableDCS, c.EnableDCS)
+	setBool(&r.Enable, c.Enable)
+
+	if c.Channel != nil {
+		r.Channel = *c.Channel
+	}
+
+	if c.Mode != nil {
+		r.Mode = *c.Mode
+	}
+
+	return r
+}
+
+// Validate checks that the radio settings can be sent to the modem: the mode
+// and bandwidth must suit the band, and (unless the channel is chosen
+// automatically) the channel must be usable at that bandwidth. Channels
+// reserved for radar (52-144) can only be used when DFS is enabled.
+func (r WiFiRadio) Validate() error {
+	if _, ok := wirelessModeCode(r.Mode); !ok {
+		return fmt.Errorf("invalid radio: unsupported mode %s", r.Mode)
+	}
+
+	var width int
+
+	switch strings.ToUpper(r.ChanBandwidth) {
+	case Bandwidth20MHz:
+		width = 20
+	case Bandwidth20_40MHz, Bandwidth40MHz:
+		width = 40
+	case Bandwidth80MHz:
+		width = 80
+	default:
+		return fmt.Errorf("invalid radio: unsupported bandwidth %q", r.ChanBandwidth)
+	}
+
+	if width > 20 && r.Mode&(WiFiModeN|WiFiModeAC) == 0 {
+		return fmt.Errorf("invalid radio: %s does not support %q bandwidth", r.Mode, r.ChanBandwidth)
+	}
+
+	if width > 40 && r.Mode&WiFiModeAC == 0 {
+		return fmt.Errorf("invalid radio: %s does not support %q bandwidth", r.Mode, r.ChanBandwidth)
+	}
+
+	switch r.Band {
+	case "2.4G":
+		if r.Mode&(WiFiModeA|WiFiModeAC) != 0 {
+			return fmt.Errorf("invalid radio: %s is not a 2.4GHz mode", r.Mode)
+		}
+
+		if width > 40 {
+			return fmt.Errorf("invalid radio: %q bandwidth is not available at 2.4GHz", r.ChanBandwidth)
+		}
+
+		if !r.AutoChannel && (r.Channel < 1 || r.Channel > 11) {
+			return fmt.Errorf("invalid radio: channel %d is not a 2.4GHz channel (1-11)", r.Channel)
+		}
+	case "5G":
+		if r.Mode&(WiFiModeA|WiFiModeAC) == 0 {
+			return fmt.Errorf("invalid radio: %s is not a 5GHz mode", r.Mode)
+		}
+
+		if !r.AutoChannel {
+			return validate5GChannel(r.Channel, width, r.EnableDFS)
+		}
+	default:
+		return fmt.Errorf("invalid radio: unknown band %q", r.Band)
 	}
-	s.Mode = wirelessMode[raw.WlsMode]
 
 	return nil
 }
 
+func validate5GChannel(channel, width int, dfs bool) error {
+	valid := false
+
+	switch {
+	case channel >= 36 && channel <= 144:
+		valid = channel%4 == 0 && (channel <= 64 || channel >= 100)
+	case channel >= 149 && channel <= 165:
+		valid = (channel-149)%4 == 0
+	}
+
+	if !valid {
+		return fmt.Errorf("invalid radio: channel %d is not a 5GHz channel", channel)
+	}
+
+	// channel 165 has no neighbour to bond with
+	if channel == 165 && width > 20 {
+		return fmt.Errorf("invalid radio: channel %d can only be used at 20MHz", channel)
+	}
+
+	if channel >= 52 && channel <= 144 && !dfs {
+		return fmt.Errorf("invalid radio: channel %d requires DFS to be enabled", channel)
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects.
+// The bandwidth is upper-cased, since Validate accepts it in any case.
+func (r WiFiRadio) MarshalJSON() ([]byte, error) {
+	code, _ := wirelessModeCode(r.Mode)
+
+	raw := struct {
+		Vendor            string `json:"vendor"`
+		Band              string `json:"band"`
+		WlsOnOff          string `json:"wlsOnOff"`
+		WlsDcsOnOff       string `json:"wlsDcsOnOff"`
+		WlsMode           string `json:"wlsMode"`
+		NBandwidth        string `json:"n_bandwidth"`
+		WlsChannel        string `json:"wlsChannel"`
+		AutoChannel       string `json:"autoChannel"`
+		WlsDfsOnOff       string `json:"wlsDfsOnOff"`
+		WlsCurrentChannel string `json:"wlsCurrentChannel"`
+		WlswpsOnOff       string `json:"wlswpsOnOff"`
+		IgmpSnoop         string `json:"igmpSnoop"`
+		RadioURI          string `json:"Radio_URI"`
+	}{
+		Vendor:            r.Vendor,
+		Band:              r.Band,
+		WlsOnOff:          onOff(r.Enable),
+		WlsDcsOnOff:       onOff(r.EnableDCS),
+		WlsMode:           code,
+		NBandwidth:        strings.ToUpper(r.ChanBandwidth),
+		WlsChannel:        strconv.Itoa(r.Channel),
+		AutoChannel:       onOff(r.AutoChannel),
+		WlsDfsOnOff:       onOff(r.EnableDFS),
+		WlsCurrentChannel: strconv.Itoa(r.CurrentChannel),
+		WlswpsOnOff:       onOff(r.EnableWPS),
+		IgmpSnoop:         onOff(r.IGMPSnoop),
+		RadioURI:          r.RadioURI,
+	}
+
+	return json.Marshal(raw)
+}
+
 // WiFiMode enumerates the available WiFi modes (802.11a/b/g/n/ac)
 type WiFiMode uint8
 
+// wirelessModes - the modes the modem accepts, mapped according to the CODA's UI
+//
+//nolint:gochecknoglobals
+var wirelessModes = map[string]WiFiMode{
+	"0": WiFiModeB,
+	"1": WiFiModeG,
+	"2": WiFiModeN,
+	"3": WiFiModeB | WiFiModeG | WiFiModeN,
+	"4": WiFiModeG | WiFiModeN,
+	"5": WiFiModeB | WiFiModeG,
+	"6": WiFiModeAC,
+	"7": WiFiModeA,
+	"8": WiFiModeA | WiFiModeN,
+	"9": WiFiModeA | WiFiModeN | WiFiModeAC,
+}
+
+// wirelessModeCode returns the modem's code for the mode, if it supports it
+func wirelessModeCode(m WiFiMode) (string, bool) {
+	for code, mode := range wirelessModes {
+		if mode == m {
+			return code, true
+		}
+	}
+
+	return "", false
+}
+
 // Useful WiFi constants for indicating protocol support. For multi-protocol
 // support, join with binary ORs (|).
 const (
@@ -342,23 +701,133 @@ func (s *WiFiRadioAdvanced) UnmarshalJSON(b []byte) error {
 		s.Channel, _ = strconv.Atoi(c)
 	}
 
-	// map according to the CODA's UI
-	wirelessMode := map[string]WiFiMode{
-		"0": WiFiModeB,
-		"1": WiFiModeG,
-		"2": WiFiModeN,
-		"3": WiFiModeB | WiFiModeG | WiFiModeN,
-		"4": WiFiModeG | WiFiModeN,
-		"5": WiFiModeB | WiFiModeG,
-		"6": WiFiModeAC,
-		"7": WiFiModeA,
-		"8": WiFiModeA | WiFiModeN,
-		"9": WiFiModeA | WiFiModeN | WiFiModeAC,
+	s.Mode = wirelessModes[raw.WlsMode]
+
+	return nil
+}
+
+// AdvancedRadioChanges - changes to make to a radio's advanced settings with
+// UpdateRadioAdvanced. Nil fields are left unchanged.
+type AdvancedRadioChanges struct {
+	NGuardInterval *string
+	TxStream       *string
+	RxStream       *string
+	NMCS           *int
+	NCoexistence   *bool
+	NRDG           *bool
+	Namsdu         *bool
+	Nautoba        *bool
+	Nbadecline     *bool
+	BandSteering   *bool
+}
+
+// apply returns a copy of the radio with the changes made
+func (c AdvancedRadioChanges) apply(r WiFiRadioAdvanced) WiFiRadioAdvanced {
+	setString(&r.NGuardInterval, c.NGuardInterval)
+	setString(&r.TxStream, c.TxStream)
+	setString(&r.RxStream, c.RxStream)
+	setBool(&r.NCoexistence, c.NCoexistence)
+	setBool(&r.NRDG, c.NRDG)
+	setBool(&r.Namsdu, c.Namsdu)
+	setBool(&r.Nautoba, c.Nautoba)
+	setBool(&r.Nbadecline, c.Nbadecline)
+	setBool(&r.BandSteering, c.BandSteering)
+
+	if c.NMCS != nil {
+		r.NMCS = *c.NMCS
+	}
+
+	return r
+}
+
+// Validate checks that the advanced radio settings can be sent to the modem
+func (s WiFiRadioAdvanced) Validate() error {
+	switch s.NGuardInterval {
+	case "Long", "Short", "Auto":
+	default:
+		return fmt.Errorf("invalid radio: unsupported guard interval %q (must be Long, Short, or Auto)", s.NGuardInterval)
 	}
 
-	s.Mode = wirelessMode[raw.WlsMode]
+	if s.NMCS < 0 || s.NMCS > 31 {
+		return fmt.Errorf("invalid radio: MCS index %d out of range (0-31)", s.NMCS)
+	}
 
-	return nil
+	return s.WiFiRadio.Validate()
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s WiFiRadioAdvanced) MarshalJSON() ([]byte, error) {
+	code, _ := wirelessModeCode(s.Mode)
+
+	raw := struct {
+		Vendor            string `json:"vendor"`
+		Band              string `json:"band"`
+		WlsOnOff          string `json:"wlsOnOff"`
+		WlsDcsOnOff       string `json:"wlsDcsOnOff"`
+		WlsMode           string `json:"wlsMode"`
+		NBandwidth        string `json:"n_bandwidth"`
+		WlsChannel        string `json:"wlsChannel"`
+		AutoChannel       string `json:"autoChannel"`
+		WlsDfsOnOff       string `json:"wlsDfsOnOff"`
+		WlsCurrentChannel string `json:"wlsCurrentChannel"`
+		WlswpsOnOff       string `json:"wlswpsOnOff"`
+		IgmpSnoop         string `json:"igmpSnoop"`
+		RadioURI          string `json:"Radio_URI"`
+
+		BGMode         string `json:"bgMode"`
+		SSIDName       string `json:"ssidName"`
+		NCoexistence   string `json:"n_coexistence"`
+		BandSteering   string `json:"bandsteering"`
+		NOperatingMode string `json:"n_OperatingMode"`
+		NGuardInterval string `json:"n_GuardInterval"`
+		NMCS           string `json:"n_mcs"`
+		NRDG           string `json:"n_rdg"`
+		Namsdu         string `json:"n_amsdu"`
+		Nautoba        string `json:"n_autoba"`
+		Nbadecline     string `json:"n_badecline"`
+		TxStream       string `json:"tx_stream"`
+		RxStream       string `json:"rx_stream"`
+		ShowMSO        string `json:"showMSO"`
+	}{
+		Vendor:            s.Vendor,
+		Band:              s.Band,
+		WlsOnOff:          onOff(s.Enable),
+		WlsDcsOnOff:       onOff(s.EnableDCS),
+		WlsMode:           code,
+		NBandwidth:        s.ChanBandwidth,
+		WlsChannel:        strconv.Itoa(s.Channel),
+		AutoChannel:       onOff(s.AutoChannel),
+		WlsDfsOnOff:       onOff(s.EnableDFS),
+		WlsCurrentChannel: strconv.Itoa(s.CurrentChannel),
+		WlswpsOnOff:       onOff(s.EnableWPS),
+		IgmpSnoop:         onOff(s.IGMPSnoop),
+		RadioURI:          s.RadioURI,
+
+		BGMode:         s.BGMode,
+		SSIDName:       s.SSID,
+		NCoexistence:   enabledDisabled(s.NCoexistence),
+		BandSteering:   onOff(s.BandSteering),
+		NOperatingMode: s.NOperatingMode,
+		NGuardInterval: s.NGuardInterval,
+		NMCS:           strconv.Itoa(s.NMCS),
+		NRDG:           enabledDisabled(s.NRDG),
+		Namsdu:         enabledDisabled(s.Namsdu),
+		Nautoba:        enabledDisabled(s.Nautoba),
+		Nbadecline:     enabledDisabled(s.Nbadecline),
+		TxStream:       s.TxStream,
+		RxStream:       s.RxStream,
+		ShowMSO:        strconv.FormatBool(s.ShowMSO),
+	}
+
+	return json.Marshal(raw)
+}
+
+func enabledDisabled(b bool) string {
+	if b {
+		return enabled
+	}
+
+	return "Disabled"
 }
 
 // WiFiRadiosSurvey -
@@ -501,6 +970,162 @@ func (s *SSID) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// SSID security modes (SecurityMode), as used by the modem's web UI
+const (
+	SecurityNone    = "0"
+	SecurityWPA     = "1"
+	SecurityWPA2    = "2"
+	SecurityWPAWPA2 = "3"
+)
+
+// SSID encryption types (EncryptType), as used by the modem's web UI
+const (
+	EncryptNone    = "0"
+	EncryptTKIP    = "1"
+	EncryptAES     = "2"
+	EncryptTKIPAES = "3"
+)
+
+// validEncryptTypes - the encryption types the firmware accepts with each
+// security mode. WPA2-only networks can't use TKIP alone, and mixed-mode
+// networks need both ciphers.
+//
+//nolint:gochecknoglobals
+var validEncryptTypes = map[string][]string{
+	SecurityNone:    {EncryptNone},
+	SecurityWPA:     {EncryptTKIP, EncryptTKIPAES},
+	SecurityWPA2:    {EncryptAES, EncryptTKIPAES},
+	SecurityWPAWPA2: {EncryptTKIPAES},
+}
+
+// SSIDChanges - changes to make to an SSID with UpdateSSID. Nil fields are left
+// unchanged.
+type SSIDChanges struct {
+	Name         *string
+	Passphrase   *string
+	SecurityMode *string
+	AuthMode     *string
+	EncryptType  *string
+	Enable       *bool
+	Visible      *bool
+	BandSteering *bool
+}
+
+// apply returns a copy of the SSID with the changes made
+func (c SSIDChanges) apply(s SSID) SSID {
+	setString(&s.Name, c.Name)
+	setString(&s.Passphrase, c.Passphrase)
+	setString(&s.SecurityMode, c.SecurityMode)
+	setString(&s.AuthMode, c.AuthMode)
+	setString(&s.EncryptType, c.EncryptType)
+	setBool(&s.Enable, c.Enable)
+	setBool(&s.Visible, c.Visible)
+	setBool(&s.BandSteering, c.BandSteering)
+
+	return s
+}
+
+func setString(dst, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setBool(dst, src *bool) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// Validate checks that the SSID's settings can be sent to the modem
+func (s SSID) Validate() error {
+	if l := len(s.Name); l < 1 || l > 32 {
+		return fmt.Errorf("invalid SSID %q: name must be 1-32 bytes long, not %d", s.Name, l)
+	}
+
+	encTypes, ok := validEncryptTypes[s.SecurityMode]
+	if !ok {
+		return fmt.Errorf("invalid SSID %q: unknown security mode %q", s.Name, s.SecurityMode)
+	}
+
+	if !slices.Contains(encTypes, s.EncryptType) {
+		return fmt.Errorf("invalid SSID %q: encryption type %q can't be used with security mode %q",
+			s.Name, s.EncryptType, s.SecurityMode)
+	}
+
+	if s.SecurityMode == SecurityNone {
+		return nil
+	}
+
+	return validatePassphrase(s.Passphrase)
+}
+
+// validatePassphrase - WPA passphrases must be 8-63 printable ASCII characters
+func validatePassphrase(p string) error {
+	if l := len(p); l < 8 || l > 63 {
+		return fmt.Errorf("invalid passphrase: must be 8-63 characters long, not %d", l)
+	}
+
+	for _, r := range p {
+		if r < ' ' || r > '~' {
+			return fmt.Errorf("invalid passphrase: must contain only printable ASCII characters")
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s SSID) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		ID          string `json:"id"`
+		SSIDName    string `json:"ssidName"`
+		Band        string `json:"band"`
+		Enable      string `json:"enable"`
+		WlswpsOnOff string `json:"wlswpsOnOff"`
+		IfName      string `json:"ifName"`
+		BSSID       string `json:"bssid"`
+		Radio       string `json:"radio"`
+		Visible     string `json:"visible"`
+		WMM         string `json:"wmm"`
+		AuthMode    string `json:"authMode"`
+		SecuMode    string `json:"SecuMode"`
+		EncryptType string `json:"encryptType"`
+		Passphrase  string `json:"passPhrase"`
+		WlsEnable   string `json:"wlsEnable"`
+		URI         string `json:"SSID_URI"`
+		DefaultKey  string `json:"defaultKey"`
+		Bandsteer   string `json:"bandsteer"`
+		Primary     string `json:"primary"`
+	}{
+		ID:          strconv.Itoa(s.ID),
+		SSIDName:    s.Name,
+		Band:        s.Band,
+		Enable:      onOff(s.Enable),
+		WlswpsOnOff: onOff(s.EnableWPS),
+		IfName:      s.IfName,
+		BSSID:       strings.ToUpper(s.BSSID.String()),
+		Radio:       strconv.Itoa(s.Radio),
+		Visible:     onOff(s.Visible),
+		WMM:         onOff(s.EnableWMM),
+		AuthMode:    s.AuthMode,
+		SecuMode:    s.SecurityMode,
+		EncryptType: s.EncryptType,
+		Passphrase:  s.Passphrase,
+		WlsEnable:   onOff(s.EnableWLS),
+		URI:         s.URI,
+		DefaultKey:  s.DefaultKey,
+		Bandsteer:   onOff(s.BandSteering),
+		Primary:     "NO",
+	}
+
+	if s.Primary {
+		raw.Primary = yes
+	}
+
+	return json.Marshal(raw)
+}
+
 // GuestSSID -
 type GuestSSID struct {
 	IfName string
@@ -567,6 +1192,121 @@ func (s *WiFiWPS) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// WPS methods
+const (
+	WPSMethodPushButton = "PushButton"
+	WPSMethodPIN        = "PIN"
+)
+
+// WPSState - the state of a WPS session
+type WPSState int
+
+// WPS session states, classified from the modem's status message
+const (
+	WPSIdle WPSState = iota
+	WPSInProgress
+	WPSSuccess
+	WPSFailed
+	WPSTimedOut
+)
+
+func (s WPSState) String() string {
+	switch s {
+	case WPSIdle:
+		return "idle"
+	case WPSInProgress:
+		return "in progress"
+	case WPSSuccess:
+		return "success"
+	case WPSFailed:
+		return "failed"
+	case WPSTimedOut:
+		return "timed out"
+	default:
+		return "unknown"
+	}
+}
+
+// State classifies the modem's status message
+func (s WiFiWPS) State() WPSState {
+	status := strings.ToLower(s.Status)
+
+	switch {
+	case status == "" || status == "idle":
+		return WPSIdle
+	case strings.Contains(status, "success"), strings.Contains(status, "configured"):
+		return WPSSuccess
+	case strings.Contains(status, "time"):
+		return WPSTimedOut
+	case strings.Contains(status, "fail"), strings.Contains(status, "error"), strings.Contains(status, "overlap"):
+		return WPSFailed
+	default:
+		return WPSInProgress
+	}
+}
+
+// MarshalJSON - implements json.Marshaler, in the format the modem expects
+func (s WiFiWPS) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		WlswpsOnOff       string `json:"wlswpsOnOff"`
+		WlsWpsMethod      string `json:"wlsWpsMethod"`
+		WlsWpsClientPin   string `json:"wlsWpsClientPin"`
+		WlsWpsStatus      string `json:"wlsWpsStatus"`
+		WlsWpsTimeElapsed string `json:"wlsWpsTimeElapsed"`
+	}{
+		WlswpsOnOff:       onOff(s.Enable),
+		WlsWpsMethod:      s.Method,
+		WlsWpsClientPin:   s.ClientPin,
+		WlsWpsStatus:      s.Status,
+		WlsWpsTimeElapsed: strconv.Itoa(int(s.TimeElapsed.Seconds())),
+	}
+
+	return json.Marshal(raw)
+}
+
+// validateWPSPin checks that the PIN is either a 4-digit PIN, or an 8-digit
+// PIN with a valid checksum (the last digit)
+func validateWPSPin(pin string) error {
+	if len(pin) != 4 && len(pin) != 8 {
+		return fmt.Errorf("invalid WPS PIN %q: must be 4 or 8 digits", pin)
+	}
+
+	sum := 0
+
+	for i, c := range pin {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid WPS PIN %q: must be 4 or 8 digits", pin)
+		}
+
+		// digits are weighted 3,1,3,1,...
+		weight := 1
+		if i%2 == 0 {
+			weight = 3
+		}
+
+		sum += weight * int(c-'0')
+	}
+
+	if len(pin) == 8 && sum%10 != 0 {
+		return fmt.Errorf("invalid WPS PIN %q: bad checksum", pin)
+	}
+
+	return nil
+}
+
+// WPSResult - the outcome of a WPS session, from WaitWPS, along with the last
+// status read from the modem
+type WPSResult struct {
+	WiFiWPS
+	// Outcome - WPSSuccess, WPSFailed, or WPSTimedOut, or WPSIdle if the
+	// session ended without the modem reporting an outcome
+	Outcome WPSState
+}
+
+func (r WPSResult) String() string {
+	return fmt.Sprintf("WPS %s after %s (%s)\n", r.Outcome, r.TimeElapsed, r.Status)
+}
+
 // WiFiClient -
 type WiFiClient struct {
 	Error
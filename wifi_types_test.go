@@ -3,9 +3,12 @@ package hitron
 import (
 	"encoding/json"
 	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 //nolint:govet
@@ -97,3 +100,282 @@ func TestWiFiClientEntry_UnmarshalJSON(t *testing.T) {
 		assert.Equal(t, d.expected, entry)
 	}
 }
+
+func TestSSIDValidate(t *testing.T) {
+	valid := SSID{Name: "CODA", SecurityMode: SecurityWPA2, EncryptType: EncryptAES, Passphrase: "supersecret"}
+	assert.NoError(t, valid.Validate())
+
+	open := SSID{Name: "CODA", SecurityMode: SecurityNone, EncryptType: EncryptNone}
+	assert.NoError(t, open.Validate())
+
+	testdata := []struct {
+		name string
+		mod  func(s *SSID)
+	}{
+		{"empty name", func(s *SSID) { s.Name = "" }},
+		{"long name", func(s *SSID) { s.Name = "0123456789012345678901234567890123" }},
+		{"short passphrase", func(s *SSID) { s.Passphrase = "1234567" }},
+		{"long passphrase", func(s *SSID) { s.Passphrase = string(make([]byte, 64)) }},
+		{"non-ASCII passphrase", func(s *SSID) { s.Passphrase = "pässwörd123" }},
+		{"unknown security", func(s *SSID) { s.SecurityMode = "9" }},
+		{"WPA2 with TKIP", func(s *SSID) { s.EncryptType = EncryptTKIP }},
+		{"mixed with AES", func(s *SSID) { s.SecurityMode = SecurityWPAWPA2 }},
+		{"open with AES", func(s *SSID) { s.SecurityMode = SecurityNone }},
+	}
+
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			s := valid
+			d.mod(&s)
+			assert.Error(t, s.Validate())
+		})
+	}
+}
+
+func TestSSIDMarshalJSON(t *testing.T) {
+	in := SSID{
+		ID: 2, Name: "CODA", Band: "5G", IfName: "ath1", Radio: 2,
+		BSSID:  net.HardwareAddr{0xca, 0xfe, 0xde, 0xad, 0xfa, 0xce},
+		Enable: true, Visible: true, SecurityMode: SecurityWPA2, EncryptType: EncryptAES,
+		AuthMode: "4", Passphrase: "supersecret", URI: "/1/Device/WiFi/SSIDs/2",
+	}
+
+	b, err := json.Marshal(in)
+	assert.NoError(t, err)
+
+	out := SSID{}
+	assert.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+}
+
+func TestWiFiGuestSSIDValidate(t *testing.T) {
+	valid := WiFiGuestSSID{SSID: "guest", SSID5G: "guest-5G", Password: "password", MaxUsers: 10, Enable: true}
+	require.NoError(t, valid.Validate())
+
+	for _, s := range []WiFiGuestSSID{
+		{SSID: "", SSID5G: "guest-5G", Password: "password", MaxUsers: 10},
+		{SSID: "guest", SSID5G: strings.Repeat("x", 33), Password: "password", MaxUsers: 10},
+		{SSID: "guest", SSID5G: "guest-5G", Password: "short", MaxUsers: 10, Enable: true},
+		{SSID: "guest", SSID5G: "guest-5G", Password: "", MaxUsers: 10, Enable: true},
+		{SSID: "guest", SSID5G: "guest-5G", Password: "password", MaxUsers: 0},
+	} {
+		assert.Error(t, s.Validate(), "%+v", s)
+	}
+
+	// the password isn't needed when disabled
+	s := valid
+	s.Enable = false
+	s.Password = ""
+	assert.NoError(t, s.Validate())
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	p, err := GeneratePassphrase(16)
+	require.NoError(t, err)
+	assert.Len(t, p, 16)
+	require.NoError(t, validatePassphrase(p))
+	assert.NotContains(t, p, "0")
+	assert.NotContains(t, p, "l")
+
+	other, err := GeneratePassphrase(16)
+	require.NoError(t, err)
+	assert.NotEqual(t, p, other)
+
+	_, err = GeneratePassphrase(7)
+	require.Error(t, err)
+
+	_, err = GeneratePassphrase(64)
+	require.Error(t, err)
+}
+
+func TestWiFiQRPayload(t *testing.T) {
+	assert.Equal(t, "WIFI:T:WPA;S:CODA-Guest;P:hunter22;;", WiFiQRPayload("CODA-Guest", "hunter22"))
+	assert.Equal(t, `WIFI:T:WPA;S:a\;b\,c;P:d\:e\\f\"g;;`, WiFiQRPayload("a;b,c", `d:e\f"g`))
+	assert.Equal(t, "WIFI:T:nopass;S:open;;", WiFiQRPayload("open", ""))
+}
+
+func TestWiFiRadioValidate(t *testing.T) {
+	radio24 := WiFiRadio{Band: "2.4G", Mode: WiFiModeG | WiFiModeN, ChanBandwidth: Bandwidth20_40MHz, Channel: 6}
+	radio5 := WiFiRadio{Band: "5G", Mode: WiFiModeA | WiFiModeN | WiFiModeAC, ChanBandwidth: Bandwidth80MHz, Channel: 36}
+
+	valid := []WiFiRadio{
+		radio24,
+		radio5,
+		{Band: "2.4G", Mode: WiFiModeB, ChanBandwidth: Bandwidth20MHz, Channel: 11},
+		{Band: "5G", Mode: WiFiModeA, ChanBandwidth: "20MHz", Channel: 165},
+		{Band: "5G", Mode: WiFiModeAC, ChanBandwidth: Bandwidth80MHz, Channel: 100, EnableDFS: true},
+		{Band: "5G", Mode: WiFiModeAC, ChanBandwidth: Bandwidth80MHz, AutoChannel: true},
+	}
+
+	for _, r := range valid {
+		assert.NoError(t, r.Validate(), "%+v", r)
+	}
+
+	testdata := []struct {
+		mod  func(*WiFiRadio)
+		name string
+	}{
+		{func(r *WiFiRadio) { r.Mode = WiFiModeA | WiFiModeB }, "unsupported mode"},
+		{func(r *WiFiRadio) { r.ChanBandwidth = "160MHZ" }, "unsupported bandwidth"},
+		{func(r *WiFiRadio) { r.Band = "6G" }, "unknown band"},
+		{func(r *WiFiRadio) { r.Mode = WiFiModeAC }, "5GHz mode on 2.4GHz"},
+		{func(r *WiFiRadio) { r.ChanBandwidth = Bandwidth80MHz }, "80MHz on 2.4GHz"},
+		{func(r *WiFiRadio) { r.Mode = WiFiModeB | WiFiModeG }, "40MHz without n"},
+		{func(r *WiFiRadio) { r.Channel = 12 }, "2.4GHz channel out of range"},
+		{func(r *WiFiRadio) { r.Channel = 0 }, "no channel"},
+		{func(r *WiFiRadio) { *r = radio5; r.Mode = WiFiModeG | WiFiModeN }, "2.4GHz mode on 5GHz"},
+		{func(r *WiFiRadio) { *r = radio5; r.Mode = WiFiModeA | WiFiModeN }, "80MHz without ac"},
+		{func(r *WiFiRadio) { *r = radio5; r.Channel = 38 }, "not a 5GHz channel"},
+		{func(r *WiFiRadio) { *r = radio5; r.Channel = 68 }, "gap in the 5GHz band"},
+		{func(r *WiFiRadio) { *r = radio5; r.Channel = 150 }, "not a UNII-3 channel"},
+		{func(r *WiFiRadio) { *r = radio5; r.Channel = 165 }, "channel 165 at 80MHz"},
+		{func(r *WiFiRadio) { *r = radio5; r.Channel = 52 }, "DFS channel without DFS"},
+	}
+
+	for _, d := range testdata {
+		t.Run(d.name, func(t *testing.T) {
+			r := radio24
+			d.mod(&r)
+			assert.Error(t, r.Validate())
+		})
+	}
+}
+
+func TestWiFiRadioMarshalJSON(t *testing.T) {
+	in := WiFiRadio{
+		Vendor: "1", Band: "5G", ChanBandwidth: Bandwidth80MHz, RadioURI: "/1/Device/WiFi/Radios/2",
+		Channel: 36, CurrentChannel: 36, Mode: WiFiModeA | WiFiModeN | WiFiModeAC,
+		Enable: true, EnableDFS: true, EnableWPS: true, IGMPSnoop: true,
+	}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"wlsMode":"9"`)
+
+	out := WiFiRadio{}
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+}
+
+func TestWiFiRadioAdvancedValidate(t *testing.T) {
+	valid := WiFiRadioAdvanced{
+		NGuardInterval: "Long",
+		WiFiRadio:      WiFiRadio{Band: "2.4G", Mode: WiFiModeG | WiFiModeN, ChanBandwidth: Bandwidth20_40MHz, Channel: 6},
+	}
+	require.NoError(t, valid.Validate())
+
+	r := valid
+	r.NGuardInterval = "Medium"
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.NMCS = 32
+	assert.Error(t, r.Validate())
+
+	r = valid
+	r.Channel = 36
+	assert.Error(t, r.Validate())
+}
+
+func TestWiFiRadioAdvancedMarshalJSON(t *testing.T) {
+	in := WiFiRadioAdvanced{
+		SSID: "CODA", NOperatingMode: "Mixed Mode", NGuardInterval: "Short",
+		WiFiRadio: WiFiRadio{
+			Vendor: "1", Band: "2.4G", ChanBandwidth: Bandwidth20_40MHz, RadioURI: "/1/Device/WiFi/Radios/1",
+			Channel: 6, CurrentChannel: 6, Mode: WiFiModeG | WiFiModeN, Enable: true,
+		},
+		NMCS: 7, NCoexistence: true, Namsdu: true, BandSteering: true,
+	}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"n_coexistence":"Enabled"`)
+	assert.Contains(t, string(b), `"n_rdg":"Disabled"`)
+	assert.Contains(t, string(b), `"bandsteering":"ON"`)
+
+	out := WiFiRadioAdvanced{}
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+}
+
+func TestParseWiFiMode(t *testing.T) {
+	for _, m := range []WiFiMode{WiFiModeB, WiFiModeG | WiFiModeN, WiFiModeA | WiFiModeN | WiFiModeAC} {
+		p, err := ParseWiFiMode(m.String())
+		require.NoError(t, err)
+		assert.Equal(t, m, p)
+	}
+
+	p, err := ParseWiFiMode("B/G/N")
+	require.NoError(t, err)
+	assert.Equal(t, WiFiModeB|WiFiModeG|WiFiModeN, p)
+
+	_, err = ParseWiFiMode("802.11x")
+	assert.Error(t, err)
+
+	_, err = ParseWiFiMode("")
+	assert.Error(t, err)
+}
+
+func TestWiFiAccessControlRuleValidate(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	require.NoError(t, WiFiAccessControlRule{MACAddr: mac}.Validate())
+
+	multicast, _ := net.ParseMAC("01:00:5e:00:00:01")
+	long, _ := net.ParseMAC("00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01")
+
+	for _, m := range []net.HardwareAddr{nil, multicast, long} {
+		assert.Error(t, WiFiAccessControlRule{MACAddr: m}.Validate(), "%s", m)
+	}
+}
+
+func TestWiFiAccessControlRuleMarshalJSON(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	in := WiFiAccessControlRule{ID: 3, Hostname: "laptop", MACAddr: mac}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":3,"hostName":"laptop","macAddr":"AA:BB:CC:DD:EE:FF"}`, string(b))
+
+	out := WiFiAccessControlRule{}
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+}
+
+func TestWiFiWPSState(t *testing.T) {
+	for status, expected := range map[string]WPSState{
+		"":            WPSIdle,
+		"Idle":        WPSIdle,
+		"In Progress": WPSInProgress,
+		"Processing":  WPSInProgress,
+		"Success":     WPSSuccess,
+		"Configured":  WPSSuccess,
+		"Time Out":    WPSTimedOut,
+		"Failed":      WPSFailed,
+		"Overlap":     WPSFailed,
+	} {
+		assert.Equal(t, expected, WiFiWPS{Status: status}.State(), status)
+	}
+
+	assert.Equal(t, "in progress", WPSInProgress.String())
+}
+
+func TestValidateWPSPin(t *testing.T) {
+	for _, pin := range []string{"1234", "12345670", "00000000"} {
+		assert.NoError(t, validateWPSPin(pin), pin)
+	}
+
+	for _, pin := range []string{"", "123", "12345", "12345678", "abcd", "1234567O"} {
+		assert.Error(t, validateWPSPin(pin), pin)
+	}
+}
+
+func TestWiFiWPSMarshalJSON(t *testing.T) {
+	in := WiFiWPS{Method: WPSMethodPIN, ClientPin: "12345670", Status: "Idle", Enable: true, TimeElapsed: 5 * time.Second}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+
+	out := WiFiWPS{}
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+}